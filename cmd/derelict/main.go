@@ -1,18 +1,27 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 
 	"github.com/vikash-paf/derelict-facility/internal/components"
-	"github.com/vikash-paf/derelict-facility/internal/display"
 	"github.com/vikash-paf/derelict-facility/internal/core"
+	"github.com/vikash-paf/derelict-facility/internal/display"
 	"github.com/vikash-paf/derelict-facility/internal/ecs"
 	"github.com/vikash-paf/derelict-facility/internal/engine"
+	"github.com/vikash-paf/derelict-facility/internal/input"
 	"github.com/vikash-paf/derelict-facility/internal/world"
 )
 
 func main() {
-	mapWidth, mapHeight := 120, 40
+	mapPath := flag.String("map", "", "play a hand-authored map file (e.g. assets/maps/tutorial.txt) instead of a generated facility")
+	diagonal := flag.Bool("diagonal", false, "let the player and the autopilot move diagonally")
+	bindingsPath := flag.String("bindings", "", "read key bindings from a file (e.g. assets/bindings.txt) on top of the defaults")
+	savePath := flag.String("save", "derelict.sav", "where terminal checkpoints save the message log, read back on start (empty to not save)")
+	flag.Parse()
+
+	mapWidth, mapHeight := world.DefaultFloorWidth, world.DefaultFloorHeight
+	floorCount := world.DefaultFloorCount
 	windowWidth, windowHeight := 120, 45
 
 	cellWidth := int32(10)
@@ -20,6 +29,27 @@ func main() {
 	fontSize := int32(20)
 	fontPath := "assets/fonts/FiraCodeNFBoldMono.ttf"
 
+	// 1. Load the hand-authored map before opening a window, so a broken file fails fast
+	var mapFile *world.MapFile
+	if *mapPath != "" {
+		var err error
+		mapFile, err = world.LoadMapFile(*mapPath)
+		if err != nil {
+			panic(err)
+		}
+		windowWidth = max(windowWidth, mapFile.Map.Width)
+		windowHeight = max(windowHeight, mapFile.Map.Height+5) // Leave room for the HUD
+	}
+
+	bindings := input.Default()
+	if *bindingsPath != "" {
+		var err error
+		bindings, err = input.Load(*bindingsPath)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	disp := display.NewRaylibDisplay(cellWidth, cellHeight, fontSize, fontPath)
 
 	err := disp.Init(windowWidth, windowHeight, "Derelict Facility")
@@ -28,41 +58,57 @@ func main() {
 	}
 	defer disp.Close()
 
+	newGame := generatedGame(mapWidth, mapHeight, floorCount, *diagonal)
+	if mapFile != nil {
+		newGame = handAuthoredGame(mapFile, *diagonal)
+	}
+
+	setup := engine.GameSetup{
+		Seed:   12345,
+		Seeded: mapFile == nil, // A hand-authored map plays the same whatever the seed
+		Theme:  world.NamedTileVariant{Name: "gritty", Variant: world.TileVariantGritty},
+	}
+	gameEngine := engine.NewEngine(disp, newGame, setup)
+	gameEngine.Bindings = bindings
+	gameEngine.SavePath = *savePath
+	if err := gameEngine.LoadSave(); err != nil {
+		fmt.Println(err) // A broken save costs the old messages, not the game
+	}
+	gameEngine.ShowTitle()
+
+	err = gameEngine.Run()
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+// generatedGame builds facilities of floorCount floors from the seed picked for each new game.
+func generatedGame(mapWidth, mapHeight, floorCount int, diagonal bool) engine.NewGameFunc {
+	return func(gameEngine *engine.Engine, setup engine.GameSetup) {
+		newGeneratedGame(gameEngine, setup.Seed, mapWidth, mapHeight, floorCount, diagonal)
+	}
+}
+
+func newGeneratedGame(gameEngine *engine.Engine, seed uint64, mapWidth, mapHeight, floorCount int, diagonal bool) {
 	// 2. Build the world map FIRST
-	// seed := time.Now().UnixNano()
-	seed := 12345
-	generator := world.NewFacilityGenerator(uint64(seed))
-	generatedMap, playerX, playerY := generator.Generate(mapWidth, mapHeight)
-	if generatedMap == nil {
+	facility := world.NewFacility(seed, floorCount, mapWidth, mapHeight)
+	if facility == nil {
 		panic("Failed to generate map")
 	}
+	generatedMap := facility.Floors[0]
+	playerX, playerY := facility.Spawns[0].X, facility.Spawns[0].Y
 
 	// 3. Setup the ECS and spawn the Player
 	ecsWorld := ecs.NewWorld()
-
-	playerEnt := ecsWorld.CreateEntity()
-	ecsWorld.AddPosition(playerEnt, components.Position{X: playerX, Y: playerY})
-	ecsWorld.AddGlyph(playerEnt, components.Glyph{Char: "@", Color: core.BrightWhite}) // Astronaut
-	ecsWorld.AddPlayerControl(playerEnt, components.PlayerControl{
-		Autopilot: false,
-		Status:    components.PlayerStatusHealthy,
-	})
+	spawnPlayer(ecsWorld, playerX, playerY, diagonal)
 
 	// 5. Spawn a test Power Generator
-	genEnt := ecsWorld.CreateEntity()
-	ecsWorld.AddPosition(genEnt, components.Position{X: playerX + 2, Y: playerY})
-	ecsWorld.AddGlyph(genEnt, components.Glyph{Char: "X", Color: core.Red})
-	ecsWorld.AddSolid(genEnt)
-	ecsWorld.AddInteractable(genEnt, components.Interactable{Prompt: "Press [E] to Toggle Generator"})
-	ecsWorld.AddPowerGenerator(genEnt, components.PowerGenerator{IsActive: false})
+	spawnGenerator(ecsWorld, generatedMap, playerX+2, playerY)
 
 	// Spawn a Save Terminal
-	termEnt := ecsWorld.CreateEntity()
-	ecsWorld.AddPosition(termEnt, components.Position{X: playerX, Y: playerY + 2})
-	ecsWorld.AddGlyph(termEnt, components.Glyph{Char: "🖥️", Color: core.Cyan})
-	ecsWorld.AddSolid(termEnt)
-	ecsWorld.AddInteractable(termEnt, components.Interactable{Prompt: "Press [E] to Save Checkpoint"})
-	ecsWorld.AddTerminal(termEnt, components.Terminal{HasSaved: false})
+	spawnTerminal(ecsWorld, playerX, playerY+2)
+	spawnAtmosphereFixtures(ecsWorld, generatedMap, playerX, playerY)
+	spawnFireFixtures(ecsWorld, generatedMap, playerX, playerY)
 
 	// 6. Spawn Doors
 	for _, doorPos := range generatedMap.Doors {
@@ -74,19 +120,273 @@ func main() {
 			continue
 		}
 
-		doorEnt := ecsWorld.CreateEntity()
-		ecsWorld.AddPosition(doorEnt, components.Position{X: doorPos.X, Y: doorPos.Y})
-		ecsWorld.AddGlyph(doorEnt, components.Glyph{Char: "+", Color: core.White})
-		ecsWorld.AddSolid(doorEnt) // Closed doors block movement!
-		ecsWorld.AddInteractable(doorEnt, components.Interactable{Prompt: "Press [E] to Open Door"})
-		ecsWorld.AddDoor(doorEnt, components.Door{IsOpen: false})
+		spawnDoor(ecsWorld, doorPos.X, doorPos.Y)
 	}
+	spawnRoomFixtures(ecsWorld, generatedMap, playerX, playerY)
 
 	// 7. Hand everything to the Engine
-	gameEngine := engine.NewEngine(disp, generatedMap, ecsWorld, world.TileVariantGritty)
+	gameEngine.AddFloor(generatedMap, ecsWorld)
 
-	err = gameEngine.Run()
-	if err != nil {
-		fmt.Println(err)
+	// 8. Populate the floors below, each with its own ECS world and generator
+	for i := 1; i < len(facility.Floors); i++ {
+		floorWorld := ecs.NewWorld()
+		spawn := facility.Spawns[i]
+		spawnGenerator(floorWorld, facility.Floors[i], spawn.X+2, spawn.Y)
+		spawnAtmosphereFixtures(floorWorld, facility.Floors[i], spawn.X, spawn.Y)
+		spawnFireFixtures(floorWorld, facility.Floors[i], spawn.X, spawn.Y)
+		spawnFloodFixtures(floorWorld, facility.Floors[i])
+		for _, doorPos := range facility.Floors[i].Doors {
+			spawnDoor(floorWorld, doorPos.X, doorPos.Y)
+		}
+		spawnRoomFixtures(floorWorld, facility.Floors[i], spawn.X, spawn.Y)
+		gameEngine.AddFloor(facility.Floors[i], floorWorld)
+	}
+
+	// 9. Line up the stairs and elevators between floors
+	for _, link := range facility.Links {
+		spawnFloorLink(gameEngine.Floors[link.Upper].EcsWorld, link, link.Lower)
+		spawnFloorLink(gameEngine.Floors[link.Lower].EcsWorld, link, link.Upper)
+	}
+}
+
+// handAuthoredGame plays the loaded map, on a fresh copy for each new game since play changes it.
+func handAuthoredGame(mapFile *world.MapFile, diagonal bool) engine.NewGameFunc {
+	return func(gameEngine *engine.Engine, setup engine.GameSetup) {
+		newHandAuthoredGame(gameEngine, mapFile.Clone(), diagonal)
+	}
+}
+
+func newHandAuthoredGame(gameEngine *engine.Engine, mapFile *world.MapFile, diagonal bool) {
+	ecsWorld := ecs.NewWorld()
+	spawnPlayer(ecsWorld, mapFile.SpawnX, mapFile.SpawnY, diagonal)
+
+	for _, doorPos := range mapFile.Map.Doors {
+		spawnDoor(ecsWorld, doorPos.X, doorPos.Y)
+	}
+
+	for _, ent := range mapFile.Entities {
+		switch ent.Kind {
+		case "generator":
+			spawnGenerator(ecsWorld, mapFile.Map, ent.X, ent.Y)
+		case "terminal":
+			spawnTerminal(ecsWorld, ent.X, ent.Y)
+		case "lamp":
+			spawnLamp(ecsWorld, ent.X, ent.Y)
+		case "light":
+			spawnCeilingLight(ecsWorld, mapFile.Map, ent.X, ent.Y)
+		case "breaker":
+			spawnBreaker(ecsWorld, ent.X, ent.Y)
+		case "lifesupport":
+			spawnLifeSupport(ecsWorld, ent.X, ent.Y)
+		case "leak":
+			spawnToxicLeak(ecsWorld, ent.X, ent.Y)
+		case "suppressor":
+			spawnSuppressor(ecsWorld, ent.X, ent.Y)
+		case "fuel":
+			spawnFuelDrum(ecsWorld, ent.X, ent.Y)
+		case "main":
+			spawnBurstMain(ecsWorld, ent.X, ent.Y)
+		case "pump":
+			spawnPump(ecsWorld, ent.X, ent.Y)
+		default:
+			panic(fmt.Sprintf("unknown map entity %q at (%d,%d)", ent.Kind, ent.X, ent.Y))
+		}
+	}
+
+	gameEngine.AddFloor(mapFile.Map, ecsWorld)
+}
+
+func spawnPlayer(w *ecs.World, x, y int, diagonal bool) {
+	playerEnt := w.CreateEntity()
+	w.AddPosition(playerEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(playerEnt, components.Glyph{Char: "@", Color: core.BrightWhite}) // Astronaut
+	w.AddPlayerControl(playerEnt, components.PlayerControl{
+		Autopilot: false,
+		Status:    components.PlayerStatusHealthy,
+		Diagonal:  diagonal,
+	})
+	w.AddLight(playerEnt, components.Light{Radius: 8, Color: core.Color{R: 255, G: 230, B: 180, A: 255}, Intensity: 1}) // Torch
+}
+
+func spawnGenerator(w *ecs.World, m *world.Map, x, y int) {
+	genEnt := w.CreateEntity()
+	w.AddPosition(genEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(genEnt, components.Glyph{Char: "X", Color: core.Red})
+	w.AddSolid(genEnt)
+	w.AddInteractable(genEnt, components.Interactable{Prompt: "Toggle Generator"})
+	w.AddPowerGenerator(genEnt, components.PowerGenerator{IsActive: false, Output: 30})
+	w.AddLight(genEnt, components.Light{
+		Radius:    m.SectorRadius(x, y, 10), // Floodlights for the room it powers
+		Color:     core.Color{R: 255, G: 245, B: 220, A: 255},
+		Intensity: 1,
+	})
+}
+
+// spawnLamp places a battery-powered emergency lamp: dim, red and unreliable.
+func spawnLamp(w *ecs.World, x, y int) {
+	lampEnt := w.CreateEntity()
+	w.AddPosition(lampEnt, components.Position{X: x, Y: y})
+	w.AddLight(lampEnt, components.Light{Radius: 5, Color: core.Red, Intensity: 0.6, Flicker: 0.5})
+}
+
+// spawnRoomFixtures gives every room a ceiling light. Every room except the one the player starts in
+// also gets an emergency lamp and a breaker on its centre conduit.
+func spawnRoomFixtures(w *ecs.World, m *world.Map, spawnX, spawnY int) {
+	for _, room := range m.Rooms {
+		x, y := room.Center()
+		spawnCeilingLight(w, m, x, y)
+		if room.Contains(spawnX, spawnY) {
+			continue
+		}
+		spawnLamp(w, x, y)
+		spawnBreaker(w, x, y)
+	}
+}
+
+func spawnTerminal(w *ecs.World, x, y int) {
+	termEnt := w.CreateEntity()
+	w.AddPosition(termEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(termEnt, components.Glyph{Char: "🖥️", Color: core.Cyan})
+	w.AddSolid(termEnt)
+	w.AddInteractable(termEnt, components.Interactable{Prompt: "Use Terminal"})
+	w.AddTerminal(termEnt, components.Terminal{HasSaved: false})
+	w.AddPowerConsumer(termEnt, components.PowerConsumer{Demand: 3})
+}
+
+func spawnDoor(w *ecs.World, x, y int) {
+	doorEnt := w.CreateEntity()
+	w.AddPosition(doorEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(doorEnt, components.Glyph{Char: "+", Color: core.White})
+	w.AddSolid(doorEnt) // Closed doors block movement!
+	w.AddInteractable(doorEnt, components.Interactable{Prompt: "Open Door"})
+	w.AddDoor(doorEnt, components.Door{IsOpen: false})
+	w.AddPowerConsumer(doorEnt, components.PowerConsumer{Demand: 2})
+}
+
+// spawnCeilingLight places a mains light that fills its room, as long as the grid can feed it.
+func spawnCeilingLight(w *ecs.World, m *world.Map, x, y int) {
+	lightEnt := w.CreateEntity()
+	w.AddPosition(lightEnt, components.Position{X: x, Y: y})
+	w.AddLight(lightEnt, components.Light{Radius: m.SectorRadius(x, y, 6), Color: core.White, Intensity: 0.8})
+	w.AddPowerConsumer(lightEnt, components.PowerConsumer{Demand: 1})
+}
+
+func spawnBreaker(w *ecs.World, x, y int) {
+	breakerEnt := w.CreateEntity()
+	w.AddPosition(breakerEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(breakerEnt, components.Glyph{Char: "&", Color: core.Yellow})
+	w.AddInteractable(breakerEnt, components.Interactable{Prompt: "Open Breaker"})
+	w.AddBreaker(breakerEnt, components.Breaker{Closed: true})
+}
+
+func spawnFloorLink(w *ecs.World, link world.FloorLink, target int) {
+	char := "<" // Leads up
+	if target == link.Lower {
+		char = ">" // Leads down
+	}
+	if link.Kind == world.LinkKindElevator {
+		char = "="
+	}
+
+	linkEnt := w.CreateEntity()
+	w.AddPosition(linkEnt, components.Position{X: link.X, Y: link.Y})
+	w.AddGlyph(linkEnt, components.Glyph{Char: char, Color: core.Yellow})
+	w.AddFloorLink(linkEnt, components.FloorLink{TargetFloor: target})
+}
+
+// spawnLifeSupport places a vent that tops up the oxygen and scrubs toxins, as long as the grid can feed it.
+func spawnLifeSupport(w *ecs.World, x, y int) {
+	ventEnt := w.CreateEntity()
+	w.AddPosition(ventEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(ventEnt, components.Glyph{Char: "≈", Color: core.Cyan})
+	w.AddGasEmitter(ventEnt, components.GasEmitter{O2: 0.5, Toxins: -0.5})
+	w.AddPowerConsumer(ventEnt, components.PowerConsumer{Demand: 4})
+}
+
+// spawnToxicLeak places a ruptured pipe that keeps pumping toxins into the room.
+func spawnToxicLeak(w *ecs.World, x, y int) {
+	leakEnt := w.CreateEntity()
+	w.AddPosition(leakEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(leakEnt, components.Glyph{Char: "!", Color: core.Green})
+	w.AddGasEmitter(leakEnt, components.GasEmitter{Toxins: 0.01})
+}
+
+// spawnAtmosphereFixtures puts life support in the spawn room and a leaking pipe in every storage room.
+func spawnAtmosphereFixtures(w *ecs.World, m *world.Map, spawnX, spawnY int) {
+	spawnLifeSupport(w, spawnX-2, spawnY)
+
+	if m.Graph == nil {
+		return
+	}
+	for _, room := range m.Graph.RoomsOfType(world.RoomTypeStorage) {
+		x, y := m.Rooms[room].Center()
+		spawnToxicLeak(w, x+1, y)
+	}
+}
+
+// spawnSuppressor places a wall-mounted extinguisher system that floods its room with suppressant.
+func spawnSuppressor(w *ecs.World, x, y int) {
+	suppressorEnt := w.CreateEntity()
+	w.AddPosition(suppressorEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(suppressorEnt, components.Glyph{Char: "S", Color: core.Cyan})
+	w.AddSolid(suppressorEnt)
+	w.AddInteractable(suppressorEnt, components.Interactable{Prompt: "Discharge Suppressant"})
+	w.AddSuppressor(suppressorEnt, components.Suppressor{Charges: 2})
+	w.AddPowerConsumer(suppressorEnt, components.PowerConsumer{Demand: 1})
+}
+
+// spawnFuelDrum places a drum of reactor coolant that burns for a long time once it catches.
+func spawnFuelDrum(w *ecs.World, x, y int) {
+	drumEnt := w.CreateEntity()
+	w.AddPosition(drumEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(drumEnt, components.Glyph{Char: "o", Color: core.Yellow})
+	w.AddFlammable(drumEnt, components.Flammable{Fuel: 200})
+}
+
+// spawnFireFixtures puts a suppressor in the spawn room and the reactor, and fuel drums in the storage rooms.
+func spawnFireFixtures(w *ecs.World, m *world.Map, spawnX, spawnY int) {
+	spawnSuppressor(w, spawnX, spawnY-2)
+
+	if m.Graph == nil {
+		return
+	}
+	for _, room := range m.Graph.RoomsOfType(world.RoomTypeReactor) {
+		x, y := m.Rooms[room].Center()
+		spawnSuppressor(w, x-1, y)
+	}
+	for _, room := range m.Graph.RoomsOfType(world.RoomTypeStorage) {
+		x, y := m.Rooms[room].Center()
+		spawnFuelDrum(w, x-1, y)
+	}
+}
+
+// spawnBurstMain places a ruptured water main that keeps flooding the deck.
+func spawnBurstMain(w *ecs.World, x, y int) {
+	mainEnt := w.CreateEntity()
+	w.AddPosition(mainEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(mainEnt, components.Glyph{Char: "%", Color: core.Blue})
+	w.AddWaterSource(mainEnt, components.WaterSource{Rate: 0.02})
+}
+
+// spawnPump places a bilge pump terminal that drains its room while running.
+func spawnPump(w *ecs.World, x, y int) {
+	pumpEnt := w.CreateEntity()
+	w.AddPosition(pumpEnt, components.Position{X: x, Y: y})
+	w.AddGlyph(pumpEnt, components.Glyph{Char: "P", Color: core.Blue})
+	w.AddSolid(pumpEnt)
+	w.AddInteractable(pumpEnt, components.Interactable{Prompt: "Start Pump"})
+	w.AddPump(pumpEnt, components.Pump{Rate: 0.05})
+	w.AddPowerConsumer(pumpEnt, components.PowerConsumer{Demand: 3})
+}
+
+// spawnFloodFixtures bursts the coolant main in the reactor of a lower deck, next to the pump that can drain it.
+func spawnFloodFixtures(w *ecs.World, m *world.Map) {
+	if m.Graph == nil {
+		return
+	}
+	for _, room := range m.Graph.RoomsOfType(world.RoomTypeReactor) {
+		x, y := m.Rooms[room].Center()
+		spawnBurstMain(w, x+1, y)
+		spawnPump(w, x, y+1)
 	}
 }
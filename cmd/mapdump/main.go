@@ -0,0 +1,80 @@
+// Command mapdump writes a generated facility floor to text, JSON or PNG,
+// so generator changes can be reviewed without running the game.
+//
+//	go run ./cmd/mapdump -seed 12345 -format png -o floor.png
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+func main() {
+	seed := flag.Uint64("seed", 12345, "generator seed")
+	width := flag.Int("width", world.DefaultFloorWidth, "map width in tiles")
+	height := flag.Int("height", world.DefaultFloorHeight, "map height in tiles")
+	floors := flag.Int("floors", world.DefaultFloorCount, "floors in the facility, the links between them depend on it")
+	floor := flag.Int("floor", 0, "which floor of the facility to dump (0 is the top floor)")
+	format := flag.String("format", "text", "output format: text, json or png")
+	themeName := flag.String("theme", "gritty", "tile theme used for png colours")
+	scale := flag.Int("scale", 8, "pixels per tile for png output")
+	output := flag.String("o", "", "output file (defaults to stdout)")
+	flag.Parse()
+
+	if err := run(*seed, *width, *height, *floors, *floor, *format, *themeName, *scale, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "mapdump:", err)
+		os.Exit(1)
+	}
+}
+
+func run(seed uint64, width, height, floors, floor int, format, themeName string, scale int, output string) error {
+	if floors < 1 {
+		return fmt.Errorf("a facility needs at least one floor")
+	}
+	if floor < 0 || floor >= floors {
+		return fmt.Errorf("floor must be between 0 and %d", floors-1)
+	}
+
+	// The whole facility, not just the floors down to this one, so the links are where the game puts them
+	facility := world.NewFacility(seed, floors, width, height)
+	if facility == nil {
+		return fmt.Errorf("can't generate a %dx%d map", width, height)
+	}
+
+	export := world.MapExport{
+		Map:      facility.Floors[floor],
+		SpawnX:   facility.Spawns[floor].X,
+		SpawnY:   facility.Spawns[floor].Y,
+		Entities: facility.LinkEntities(floor),
+	}
+
+	var out io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch strings.ToLower(format) {
+	case "text":
+		return export.WriteText(out)
+	case "json":
+		return export.WriteJSON(out)
+	case "png":
+		theme, ok := world.TileVariantByName(themeName)
+		if !ok {
+			return fmt.Errorf("unknown theme %q", themeName)
+		}
+		return export.WritePNG(out, theme, scale)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
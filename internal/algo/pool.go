@@ -0,0 +1,131 @@
+package algo
+
+// PoolNode is a Node that lives in a NodePool. It points at its parent by index rather than by
+// pointer, so a whole search sits in one array the garbage collector never has to look through.
+type PoolNode struct {
+	GCost     int   // Distance from the start
+	HCost     int   // Heuristic (estimated distance to the target)
+	FCost     int   // G + H
+	Parent    int32 // Index of the node we came from, -1 for the start
+	heapIndex int32 // Where the node sits in the open heap, -1 once it's been popped
+	gen       uint32
+}
+
+// NodePool holds one PoolNode per index (a tile, for the Pathfinder) and a binary heap of the open
+// ones, ordered like PriorityQueue. Reset starts a new search without clearing anything, nodes
+// left over from earlier searches just read as unseen. Nothing allocates after NewNodePool.
+type NodePool struct {
+	Nodes []PoolNode
+	heap  []int32
+	gen   uint32
+}
+
+// NewNodePool makes a pool for indices 0 to size-1.
+func NewNodePool(size int) *NodePool {
+	return &NodePool{
+		Nodes: make([]PoolNode, size),
+		heap:  make([]int32, 0, size), // Every node is pushed at most once, so this never grows
+	}
+}
+
+// Reset forgets the last search.
+func (p *NodePool) Reset() {
+	p.gen++
+	if p.gen == 0 {
+		// Wrapped around, old stamps could pass for new ones
+		for i := range p.Nodes {
+			p.Nodes[i].gen = 0
+		}
+		p.gen = 1
+	}
+	p.heap = p.heap[:0]
+}
+
+// Len is how many nodes are open.
+func (p *NodePool) Len() int { return len(p.heap) }
+
+// Seen reports whether node i has been pushed since the last Reset.
+func (p *NodePool) Seen(i int) bool { return p.Nodes[i].gen == p.gen }
+
+// Closed reports whether node i has been pushed and popped since the last Reset.
+func (p *NodePool) Closed(i int) bool { return p.Seen(i) && p.Nodes[i].heapIndex < 0 }
+
+// Push opens node i. It must not have been seen yet this search.
+func (p *NodePool) Push(i, gCost, hCost int, parent int32) {
+	p.Nodes[i] = PoolNode{
+		GCost:     gCost,
+		HCost:     hCost,
+		FCost:     gCost + hCost,
+		Parent:    parent,
+		heapIndex: int32(len(p.heap)),
+		gen:       p.gen,
+	}
+	p.heap = append(p.heap, int32(i))
+	p.up(len(p.heap) - 1)
+}
+
+// Improve gives an open node a cheaper route from the start, like heap.Fix on a PriorityQueue.
+func (p *NodePool) Improve(i, gCost int, parent int32) {
+	n := &p.Nodes[i]
+	n.GCost = gCost
+	n.FCost = gCost + n.HCost
+	n.Parent = parent
+	p.up(int(n.heapIndex))
+}
+
+// Pop closes the open node with the lowest F-Cost and returns its index.
+func (p *NodePool) Pop() int {
+	top := p.heap[0]
+	last := len(p.heap) - 1
+	p.swap(0, last)
+	p.heap = p.heap[:last]
+	p.Nodes[top].heapIndex = -1
+	if last > 0 {
+		p.down(0)
+	}
+	return int(top)
+}
+
+// less is PriorityQueue.Less: lowest F-Cost first, ties to the node closest to the target.
+func (p *NodePool) less(a, b int) bool {
+	na, nb := &p.Nodes[p.heap[a]], &p.Nodes[p.heap[b]]
+	if na.FCost == nb.FCost {
+		return na.HCost < nb.HCost
+	}
+	return na.FCost < nb.FCost
+}
+
+func (p *NodePool) swap(a, b int) {
+	p.heap[a], p.heap[b] = p.heap[b], p.heap[a]
+	p.Nodes[p.heap[a]].heapIndex = int32(a)
+	p.Nodes[p.heap[b]].heapIndex = int32(b)
+}
+
+func (p *NodePool) up(k int) {
+	for k > 0 {
+		parent := (k - 1) / 2
+		if !p.less(k, parent) {
+			return
+		}
+		p.swap(k, parent)
+		k = parent
+	}
+}
+
+func (p *NodePool) down(k int) {
+	n := len(p.heap)
+	for {
+		smallest := k
+		if l := 2*k + 1; l < n && p.less(l, smallest) {
+			smallest = l
+		}
+		if r := 2*k + 2; r < n && p.less(r, smallest) {
+			smallest = r
+		}
+		if smallest == k {
+			return
+		}
+		p.swap(k, smallest)
+		k = smallest
+	}
+}
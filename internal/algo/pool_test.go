@@ -0,0 +1,58 @@
+package algo
+
+import (
+	"testing"
+)
+
+func TestNodePoolOrdering(t *testing.T) {
+	p := NewNodePool(8)
+	p.Reset()
+
+	// Same nodes as TestPriorityQueueOrdering, by index
+	p.Push(1, 5, 5, -1)  // F 10
+	p.Push(2, 3, 2, -1)  // F 5
+	p.Push(3, 5, 10, -1) // F 15
+	p.Push(4, 4, 1, -1)  // F 5, lower H-Cost tie-breaker
+
+	for _, want := range []int{4, 2, 1, 3} {
+		if got := p.Pop(); got != want {
+			t.Errorf("Expected node %d next, got %d", want, got)
+		}
+	}
+	if p.Len() != 0 {
+		t.Errorf("Expected the heap to be empty, %d left", p.Len())
+	}
+}
+
+func TestNodePoolImprove(t *testing.T) {
+	p := NewNodePool(4)
+	p.Reset()
+	p.Push(0, 10, 0, -1)
+	p.Push(1, 20, 0, -1)
+	p.Push(2, 30, 0, -1)
+
+	p.Improve(2, 5, 1)
+	if got := p.Pop(); got != 2 {
+		t.Fatalf("Expected the improved node first, got %d", got)
+	}
+	if n := p.Nodes[2]; n.GCost != 5 || n.FCost != 5 || n.Parent != 1 {
+		t.Errorf("Expected G 5, F 5 and parent 1, got %+v", n)
+	}
+}
+
+func TestNodePoolReset(t *testing.T) {
+	p := NewNodePool(4)
+	p.Reset()
+	p.Push(0, 0, 0, -1)
+	p.Push(1, 1, 0, 0)
+	p.Pop()
+
+	if !p.Closed(0) || p.Closed(1) || !p.Seen(1) {
+		t.Fatal("Expected node 0 closed and node 1 open")
+	}
+
+	p.Reset()
+	if p.Seen(0) || p.Seen(1) || p.Len() != 0 {
+		t.Error("Expected a reset pool to have seen nothing")
+	}
+}
@@ -15,6 +15,7 @@ type Node struct {
 }
 
 // PriorityQueue implements heap.Interface and holds Nodes.
+// The searches run on NodePool now; this stays as the baseline pq_bench_test.go measures it against.
 type PriorityQueue []*Node
 
 func (pq PriorityQueue) Len() int { return len(pq) }
@@ -52,3 +52,40 @@ func BenchmarkPriorityQueue_Fix(b *testing.B) {
 		heap.Fix(pq, target.HeapIndex)
 	}
 }
+
+// BenchmarkNodePool_PushPop is BenchmarkPriorityQueue_PushPop on the index-based heap.
+// Nodes are reused by index, so nothing is boxed into interfaces or allocated.
+func BenchmarkNodePool_PushPop(b *testing.B) {
+	p := NewNodePool(1001)
+	p.Reset()
+
+	for i := 0; i < 1000; i++ {
+		p.Push(i, rand.Intn(100), rand.Intn(50), -1)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// The popped node's slot is free for the next push, like a tile discovered in a later search
+		free := p.Pop()
+		p.Nodes[free] = PoolNode{}
+		p.Push(free, rand.Intn(100), rand.Intn(50), -1)
+	}
+}
+
+// BenchmarkNodePool_Improve is BenchmarkPriorityQueue_Fix on the index-based heap.
+func BenchmarkNodePool_Improve(b *testing.B) {
+	p := NewNodePool(1000)
+	p.Reset()
+
+	for i := 0; i < 1000; i++ {
+		p.Push(i, 100+i, 0, -1)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		target := rand.Intn(1000)
+		p.Improve(target, p.Nodes[target].GCost-10, -1)
+	}
+}
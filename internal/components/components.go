@@ -20,6 +20,15 @@ const (
 	MaskPowerGenerator
 	MaskDoor
 	MaskTerminal
+	MaskFloorLink
+	MaskLight
+	MaskPowerConsumer
+	MaskBreaker
+	MaskGasEmitter
+	MaskFlammable
+	MaskSuppressor
+	MaskWaterSource
+	MaskPump
 )
 
 // PlayerStatus represents the health/condition of a player entity.
@@ -29,6 +38,7 @@ const (
 	PlayerStatusHealthy PlayerStatus = iota
 	PlayerStatusSick
 	PlayerStatusHurt
+	PlayerStatusDead
 )
 
 func (s PlayerStatus) Title() string {
@@ -39,6 +49,8 @@ func (s PlayerStatus) Title() string {
 		return "SICK / TOXIC"
 	case PlayerStatusHurt:
 		return "Hurt"
+	case PlayerStatusDead:
+		return "DECEASED"
 	default:
 		return "Unknown"
 	}
@@ -62,9 +74,16 @@ type Sprite struct {
 // PlayerControl indicates that this entity is currently controllable by the user.
 // It also holds properties specific to their condition.
 type PlayerControl struct {
-	Autopilot   bool
-	CurrentPath []entity.Point
-	Status      PlayerStatus
+	Autopilot    bool
+	CurrentPath  []entity.Point
+	Waypoints    []entity.Point // Portals still ahead on a long route, CurrentPath is refined to the next one
+	PathPending  bool           // A path has been asked of the path service and hasn't come back yet
+	Status       PlayerStatus
+	MoveCooldown int  // Ticks until the entity can take another step, set by wading through water
+	Diagonal     bool // Moves (and the autopilot paths) may go diagonally
+	Explore      bool // The autopilot maps out the facility instead of wandering between rooms
+	Travelling   bool // Walking CurrentPath to a clicked tile, stopping at the end of it
+	Exposure     int  // Ticks of damage soaked up from bad air or fire, the player collapses when it runs too high
 }
 
 // Glyph defines the graphical representation of an entity using a text character or emoji.
@@ -76,14 +95,29 @@ type Glyph struct {
 // Solid indicates this entity cannot be walked through.
 type Solid struct{} // empty struct because the bitmask itself holds the logic!
 
-// Interactable allows the player to trigger an action when standing nearby and pressing [E].
+// Interactable allows the player to trigger an action when standing nearby and pressing the interact key.
 type Interactable struct {
-	Prompt string
+	Prompt string // What interacting does, like "Open Door". The HUD puts the key in front of it
+	Status string // Shown in place of the prompt once there's nothing left to do, like "CHECKPOINT SAVED"
 }
 
 // PowerGenerator is a specific interactive device state.
+// While active it feeds Output into the grid it sits on.
 type PowerGenerator struct {
 	IsActive bool
+	Output   float32
+}
+
+// PowerConsumer draws Demand from the grid it sits on. The power system fills in the rest every tick.
+type PowerConsumer struct {
+	Demand  float32
+	Supply  float32 // Share of Demand the grid delivered, 0..1
+	Powered bool    // Enough supply to work, lights still glow dimly below this
+}
+
+// Breaker cuts the conduit it sits on while open, isolating everything downstream.
+type Breaker struct {
+	Closed bool
 }
 
 // Door represents a mechanism that can block movement and vision.
@@ -94,5 +128,55 @@ type Door struct {
 // Terminal allows saving the game.
 type Terminal struct {
 	HasSaved bool
+	InUse    bool // The player is logged in, the engine shows the terminal's screen until they log off
+}
+
+// FloorLink is a stair or elevator. Stepping onto it moves the entity to the same tile on TargetFloor.
+type FloorLink struct {
+	TargetFloor int
+}
+
+// Light makes an entity cast light onto the map around it.
+// Lights on a PowerGenerator only shine while the generator is running.
+type Light struct {
+	Radius    int
+	Color     core.Color
+	Intensity float32 // 1.0 is full brightness at the source
+	Flicker   float32 // 0 is steady, 1 lets the light dip all the way to black
+}
+
+// GasEmitter pumps gas into the tile it sits on every tick. Oxygen only tops the air up to normal,
+// toxins keep building up, and negative toxins scrub them. Emitters that draw power scale their output by their supply.
+type GasEmitter struct {
+	O2     float32
+	Toxins float32
 }
 
+// Flammable feeds the fire on its tile once it catches, a little each fire step until Fuel runs out.
+type Flammable struct {
+	Fuel int
+}
+
+// Suppressor floods the room it sits in with suppressant when used, putting out every fire there.
+type Suppressor struct {
+	Charges int
+}
+
+// WaterSource pours water onto the tile it sits on every tick, like a burst main.
+type WaterSource struct {
+	Rate float32
+}
+
+// Pump drains the room it sits in while running, taking up to Rate off every tile each tick.
+// Pumps that draw power scale their rate by their supply.
+type Pump struct {
+	Rate    float32
+	Running bool
+}
+
+// Sound is a one-off noise made this tick, like a footstep or a door slamming.
+// It isn't a component, entities queue them on the World for the noise system.
+type Sound struct {
+	X, Y     int
+	Loudness int
+}
@@ -1,7 +1,28 @@
 package core
 
+// MouseAction says what the mouse did in an InputEvent.
+type MouseAction uint8
+
+const (
+	MouseNone  MouseAction = iota // A key event
+	MouseHover                    // The cursor is over CellX, CellY
+	MouseClick                    // The left button was pressed on CellX, CellY
+	MouseWheel                    // The wheel turned Wheel notches, positive away from the user
+)
+
 type InputEvent struct {
-	Key  rune
-	Code int // for non-runes
-	Quit bool
+	Key    rune
+	Code   int // for non-runes
+	Quit   bool
+	Repeat bool // The key is being held down, not freshly pressed
+
+	// Mouse events, already turned into grid cells by the Display
+	Mouse MouseAction
+	CellX int
+	CellY int
+	Wheel int
 }
+
+// GamepadKeyBase is added to a gamepad button number to report it as an InputEvent Key,
+// clear of every keyboard key code.
+const GamepadKeyBase rune = 1 << 16
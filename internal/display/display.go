@@ -7,6 +7,7 @@ import (
 type Display interface {
 	Init(gridWidth, gridHeight int, title string) error
 	Close()
+	Size() (gridWidth, gridHeight int)
 	ShouldClose() bool
 	BeginFrame()
 	EndFrame()
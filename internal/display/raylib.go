@@ -15,6 +15,12 @@ type RaylibDisplay struct {
 	Tileset      rl.Texture2D
 }
 
+// The smallest window, in cells, that still fits the HUD and a sliver of the map
+const (
+	minGridWidth  = 40
+	minGridHeight = 12
+)
+
 func NewRaylibDisplay(cellWidth, cellHeight, fontSize int32, fontPath string) *RaylibDisplay {
 	return &RaylibDisplay{
 		CellWidth:  cellWidth,
@@ -26,7 +32,9 @@ func NewRaylibDisplay(cellWidth, cellHeight, fontSize int32, fontPath string) *R
 
 func (r *RaylibDisplay) Init(gridWidth, gridHeight int, title string) error {
 	rl.ClearWindowState(rl.FlagWindowTransparent) // Fix transparency issue on some Linux window managers
+	rl.SetConfigFlags(rl.FlagWindowResizable)     // The HUD and menus lay themselves out from Size every frame
 	rl.InitWindow(int32(gridWidth)*r.CellWidth, int32(gridHeight)*r.CellHeight, title)
+	rl.SetWindowMinSize(minGridWidth*int(r.CellWidth), minGridHeight*int(r.CellHeight))
 	rl.SetTargetFPS(60)
 	rl.SetExitKey(0)
 
@@ -66,6 +74,11 @@ func (r *RaylibDisplay) Close() {
 	rl.CloseWindow()
 }
 
+// Size is the window size in whole grid cells, as it is now; the player can resize the window.
+func (r *RaylibDisplay) Size() (width, height int) {
+	return rl.GetScreenWidth() / int(r.CellWidth), rl.GetScreenHeight() / int(r.CellHeight)
+}
+
 func (r *RaylibDisplay) ShouldClose() bool {
 	return rl.WindowShouldClose()
 }
@@ -128,29 +141,37 @@ func (r *RaylibDisplay) DrawSprite(gridX, gridY int, sheetX, sheetY int, color c
 func (r *RaylibDisplay) PollInput() []core.InputEvent {
 	var events []core.InputEvent
 
-	if rl.IsKeyPressed(rl.KeyW) || rl.IsKeyPressedRepeat(rl.KeyW) {
-		events = append(events, core.InputEvent{Key: rl.KeyW})
-	}
-	if rl.IsKeyPressed(rl.KeyS) || rl.IsKeyPressedRepeat(rl.KeyS) {
-		events = append(events, core.InputEvent{Key: rl.KeyS})
-	}
-	if rl.IsKeyPressed(rl.KeyA) || rl.IsKeyPressedRepeat(rl.KeyA) {
-		events = append(events, core.InputEvent{Key: rl.KeyA})
-	}
-	if rl.IsKeyPressed(rl.KeyD) || rl.IsKeyPressedRepeat(rl.KeyD) {
-		events = append(events, core.InputEvent{Key: rl.KeyD})
+	// Every key, the bindings decide which ones mean anything
+	for key := int32(rl.KeySpace); key <= int32(rl.KeyKbMenu); key++ {
+		if rl.IsKeyPressed(key) {
+			events = append(events, core.InputEvent{Key: rune(key)})
+		} else if rl.IsKeyPressedRepeat(key) {
+			events = append(events, core.InputEvent{Key: rune(key), Repeat: true})
+		}
 	}
-	if rl.IsKeyPressed(rl.KeyP) {
-		events = append(events, core.InputEvent{Key: rl.KeyP})
+	if rl.IsGamepadAvailable(0) {
+		for button := int32(rl.GamepadButtonLeftFaceUp); button <= int32(rl.GamepadButtonRightThumb); button++ {
+			if rl.IsGamepadButtonPressed(0, button) {
+				events = append(events, core.InputEvent{Key: core.GamepadKeyBase + rune(button)})
+			}
+		}
 	}
-	if rl.IsKeyPressed(rl.KeyQ) {
-		events = append(events, core.InputEvent{Key: rl.KeyQ})
+
+	// The mouse, in the grid cells everything else is drawn in
+	mouse := rl.GetMousePosition()
+	cellX, cellY := int(mouse.X)/int(r.CellWidth), int(mouse.Y)/int(r.CellHeight)
+	if rl.IsCursorOnScreen() {
+		events = append(events, core.InputEvent{Mouse: core.MouseHover, CellX: cellX, CellY: cellY})
 	}
-	if rl.IsKeyPressed(rl.KeyE) {
-		events = append(events, core.InputEvent{Key: rl.KeyE})
+	if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
+		events = append(events, core.InputEvent{Mouse: core.MouseClick, CellX: cellX, CellY: cellY})
 	}
-	if rl.IsKeyPressed(rl.KeyEscape) {
-		events = append(events, core.InputEvent{Key: rl.KeyEscape})
+	if wheel := rl.GetMouseWheelMove(); wheel != 0 {
+		notches := 1
+		if wheel < 0 {
+			notches = -1
+		}
+		events = append(events, core.InputEvent{Mouse: core.MouseWheel, CellX: cellX, CellY: cellY, Wheel: notches})
 	}
 	return events
 }
@@ -1,6 +1,9 @@
 package ecs
 
-import "github.com/vikash-paf/derelict-facility/internal/components"
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/msglog"
+)
 
 // Entity is just an index into the World arrays.
 type Entity uint32
@@ -25,6 +28,21 @@ type World struct {
 	PowerGenerators [MaxEntities]components.PowerGenerator
 	Doors           [MaxEntities]components.Door
 	Terminals       [MaxEntities]components.Terminal
+	FloorLinks      [MaxEntities]components.FloorLink
+	Lights          [MaxEntities]components.Light
+	PowerConsumers  [MaxEntities]components.PowerConsumer
+	Breakers        [MaxEntities]components.Breaker
+	GasEmitters     [MaxEntities]components.GasEmitter
+	Flammables      [MaxEntities]components.Flammable
+	Suppressors     [MaxEntities]components.Suppressor
+	WaterSources    [MaxEntities]components.WaterSource
+	Pumps           [MaxEntities]components.Pump
+
+	// Sounds made since the noise system last ran
+	Sounds []components.Sound
+
+	// Messages for the player posted since the engine last filed them in its log
+	Messages []msglog.Message
 }
 
 func NewWorld() *World {
@@ -63,6 +81,34 @@ func (w *World) AddPosition(e Entity, pos components.Position) {
 	w.Masks[e] |= components.MaskPosition // Turn ON the bit
 }
 
+// MoveEntity copies an entity and all of its components into another World, then destroys it here.
+// Returns the entity's new ID in the destination World.
+func (w *World) MoveEntity(e Entity, dst *World) Entity {
+	id := dst.CreateEntity()
+
+	dst.Masks[id] = w.Masks[e]
+	dst.Positions[id] = w.Positions[e]
+	dst.Sprites[id] = w.Sprites[e]
+	dst.PlayerControls[id] = w.PlayerControls[e]
+	dst.Glyphs[id] = w.Glyphs[e]
+	dst.Interactables[id] = w.Interactables[e]
+	dst.PowerGenerators[id] = w.PowerGenerators[e]
+	dst.Doors[id] = w.Doors[e]
+	dst.Terminals[id] = w.Terminals[e]
+	dst.FloorLinks[id] = w.FloorLinks[e]
+	dst.Lights[id] = w.Lights[e]
+	dst.PowerConsumers[id] = w.PowerConsumers[e]
+	dst.Breakers[id] = w.Breakers[e]
+	dst.GasEmitters[id] = w.GasEmitters[e]
+	dst.Flammables[id] = w.Flammables[e]
+	dst.Suppressors[id] = w.Suppressors[e]
+	dst.WaterSources[id] = w.WaterSources[e]
+	dst.Pumps[id] = w.Pumps[e]
+
+	w.DestroyEntity(e)
+	return id
+}
+
 func (w *World) AddSprite(e Entity, spr components.Sprite) {
 	w.Sprites[e] = spr
 	w.Masks[e] |= components.MaskSprite
@@ -111,3 +157,67 @@ func (w *World) AddTerminal(entity Entity, terminal components.Terminal) {
 func (w *World) RemoveTerminal(entity Entity) {
 	w.Masks[entity] &^= components.MaskTerminal
 }
+
+// AddFloorLink adds a FloorLink component to an entity.
+func (w *World) AddFloorLink(e Entity, link components.FloorLink) {
+	w.FloorLinks[e] = link
+	w.Masks[e] |= components.MaskFloorLink
+}
+
+// AddLight adds a Light component to an entity.
+func (w *World) AddLight(e Entity, light components.Light) {
+	w.Lights[e] = light
+	w.Masks[e] |= components.MaskLight
+}
+
+// AddPowerConsumer adds a PowerConsumer component to an entity.
+func (w *World) AddPowerConsumer(e Entity, consumer components.PowerConsumer) {
+	w.PowerConsumers[e] = consumer
+	w.Masks[e] |= components.MaskPowerConsumer
+}
+
+// AddBreaker adds a Breaker component to an entity.
+func (w *World) AddBreaker(e Entity, breaker components.Breaker) {
+	w.Breakers[e] = breaker
+	w.Masks[e] |= components.MaskBreaker
+}
+
+// AddGasEmitter adds a GasEmitter component to an entity.
+func (w *World) AddGasEmitter(e Entity, emitter components.GasEmitter) {
+	w.GasEmitters[e] = emitter
+	w.Masks[e] |= components.MaskGasEmitter
+}
+
+// AddFlammable adds a Flammable component to an entity.
+func (w *World) AddFlammable(e Entity, flammable components.Flammable) {
+	w.Flammables[e] = flammable
+	w.Masks[e] |= components.MaskFlammable
+}
+
+// AddSuppressor adds a Suppressor component to an entity.
+func (w *World) AddSuppressor(e Entity, suppressor components.Suppressor) {
+	w.Suppressors[e] = suppressor
+	w.Masks[e] |= components.MaskSuppressor
+}
+
+// AddWaterSource adds a WaterSource component to an entity.
+func (w *World) AddWaterSource(e Entity, source components.WaterSource) {
+	w.WaterSources[e] = source
+	w.Masks[e] |= components.MaskWaterSource
+}
+
+// AddPump adds a Pump component to an entity.
+func (w *World) AddPump(e Entity, pump components.Pump) {
+	w.Pumps[e] = pump
+	w.Masks[e] |= components.MaskPump
+}
+
+// EmitSound queues a noise at (x, y) for the noise system to spread this tick.
+func (w *World) EmitSound(x, y, loudness int) {
+	w.Sounds = append(w.Sounds, components.Sound{X: x, Y: y, Loudness: loudness})
+}
+
+// Post queues a message for the player, the engine stamps it with the tick and files it in the log.
+func (w *World) Post(severity msglog.Severity, text string) {
+	w.Messages = append(w.Messages, msglog.Message{Severity: severity, Text: text})
+}
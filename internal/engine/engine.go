@@ -1,123 +1,387 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
-	"strings"
+	"os"
 	"time"
 
-	rl "github.com/gen2brain/raylib-go/raylib"
 	"github.com/vikash-paf/derelict-facility/internal/components"
 	"github.com/vikash-paf/derelict-facility/internal/core"
 	"github.com/vikash-paf/derelict-facility/internal/display"
 	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+	"github.com/vikash-paf/derelict-facility/internal/input"
+	"github.com/vikash-paf/derelict-facility/internal/msglog"
 	"github.com/vikash-paf/derelict-facility/internal/systems"
 	"github.com/vikash-paf/derelict-facility/internal/world"
 )
 
 const (
-	fovRadius = 8 // cool stuff can be done here, like a dimming torch light
+	sightRadius       = 40 // How far the player can see into lit areas, the torch decides what is actually lit
+	fireStepInterval  = 6  // Ticks between fire steps, fast enough to be scary but slow enough to outrun
+	waterBands        = 9  // Steps of water depth the overlay tells apart, waist deep or more is the last
+	noiseOverlayScale = 16 // Noise level drawn at full strength by the debug overlay
+	pathWorkers       = 2  // Goroutines per floor working out autopilot paths, 0 plans them inline
 )
 
-type GameState uint8
-
-const (
-	GameStatePaused GameState = iota
-	GameStateRunning
-)
+var tooltipHighlight = core.Color{R: 255, G: 255, B: 0, A: 60} // Laid over the tile under the mouse
+
+// Floor holds everything the engine keeps per level of the facility.
+// Switching floors swaps these in, so every floor keeps its own Explored tiles and entities.
+type Floor struct {
+	Map         *world.Map
+	EcsWorld    *ecs.World
+	PathLookup  []bool            // Pre-allocated array to avoid map allocations per frame
+	SolidLookup []bool            // Which tiles hold a Solid entity, rebuilt every tick for the light and FOV casts
+	Pathfinder  *world.Pathfinder // Sized for this floor's map
+	PowerGrid   *world.PowerGrid  // The floor's power network, relabelled every tick
+	Noise       *world.NoiseMap   // What can be heard on this floor, rebuilt every tick
+	Nav         *world.Hierarchy  // Clusters and portals for long autopilot routes, following the doors
+	LastSolid   []bool            // SolidLookup as it was last tick, to tell Nav what opened or closed
+	Paths       *world.PathCache  // Autopilot routes, dropped whenever the map's Revision moves on
+	PathService *world.PathService
+	Snapshots   *world.SnapshotCache // What PathService plans over, shared until the map changes
+	Frontier    *world.DijkstraMap   // Distance to the nearest unexplored edge, for the exploring autopilot
+	Blocked     []bool               // Scratch for the exploring autopilot
+}
 
-// Flip the game state without branching, so it's a bit faster than using a switch statement.
-func (s GameState) Flip() GameState {
-	return s ^ 1
+func NewFloor(gameMap *world.Map, ecsWorld *ecs.World) *Floor {
+	gameMap.FOV = world.FOVShadowcast // Symmetric, so anything the player can see can also see them
+	gameMap.EnableLighting()
+	gameMap.EnableAtmosphere()
+	gameMap.EnableFire(gameMap.Seed)
+	gameMap.EnableWater()
+
+	floor := &Floor{
+		Map:         gameMap,
+		EcsWorld:    ecsWorld,
+		PathLookup:  make([]bool, gameMap.Width*gameMap.Height),
+		SolidLookup: make([]bool, gameMap.Width*gameMap.Height),
+		Pathfinder:  world.NewPathfinder(gameMap.Width, gameMap.Height),
+		PowerGrid:   world.NewPowerGrid(gameMap.Width, gameMap.Height),
+		Noise:       world.NewNoiseMap(gameMap.Width, gameMap.Height),
+		LastSolid:   make([]bool, gameMap.Width*gameMap.Height),
+		Paths:       world.NewPathCache(world.DefaultPathCacheSize),
+		PathService: world.NewPathService(gameMap.Width, gameMap.Height, pathWorkers),
+		Snapshots:   &world.SnapshotCache{},
+		Frontier:    world.NewDijkstraMap(gameMap.Width, gameMap.Height),
+		Blocked:     make([]bool, gameMap.Width*gameMap.Height),
+	}
+	floor.Nav = world.NewHierarchy(gameMap, world.DefaultClusterSize, func(x, y int) bool {
+		return gameMap.IsWalkable(x, y) && !floor.SolidLookup[gameMap.GetIndex(x, y)]
+	})
+	return floor
 }
 
 type Engine struct {
-	Display    display.Display
-	Map        *world.Map
-	EcsWorld   *ecs.World // Replaces Player
-	BaseTheme  world.TileVariant
-	TickerRate time.Duration
-	tickCount  int
-	State      GameState
-	Running    bool
-	PathLookup []bool // Pre-allocated array to avoid map allocations per frame
-	Pathfinder *world.Pathfinder
+	Display     display.Display
+	Floors      []*Floor
+	ActiveFloor int
+	BaseTheme   world.TileVariant
+	TickerRate  time.Duration
+	tickCount   int
+	Running     bool
+	ShowNoise   bool         // Debug overlay of the noise map
+	Hover       entity.Point // Grid cell under the mouse, when Hovering
+	Hovering    bool
+	Bindings    *input.Bindings // What the keys and gamepad buttons do
+	Log         *msglog.Log     // Everything the facility has told the player, across every floor
+	SavePath    string          // Where checkpoints save to and LoadSave reads back from, empty to save nothing
+	NewGame     NewGameFunc     // Builds the floors of a fresh game
+	Setup       GameSetup       // What the game in progress was built from
+	scenes      []Scene         // Bottom to top, the top one takes the input
+
+	// The active floor's state, swapped in by SwitchFloor
+	Map         *world.Map
+	EcsWorld    *ecs.World // Replaces Player
+	PathLookup  []bool
+	SolidLookup []bool
+	Pathfinder  *world.Pathfinder
+	PowerGrid   *world.PowerGrid
+	Noise       *world.NoiseMap
+	Nav         *world.Hierarchy
+	LastSolid   []bool
+	Paths       *world.PathCache
+	PathService *world.PathService
+}
+
+// GameSetup is everything a new game is built from, picked on the new game screen.
+type GameSetup struct {
+	Seed   uint64
+	Seeded bool // The game is generated from Seed, hand-authored maps don't use it and don't offer it
+	Theme  world.NamedTileVariant
 }
 
-func NewEngine(
-	disp display.Display,
-	gameMap *world.Map,
-	ecsWorld *ecs.World,
-	startingTheme world.TileVariant,
-) *Engine {
+// NewGameFunc fills a freshly reset Engine with the floors of a new game, adding them with AddFloor.
+type NewGameFunc func(e *Engine, setup GameSetup)
+
+// NewEngine builds the first game from setup and starts in play. Push the title screen on
+// top to start from there instead.
+func NewEngine(disp display.Display, newGame NewGameFunc, setup GameSetup) *Engine {
 	e := &Engine{
 		Display:    disp,
-		Map:        gameMap,
-		EcsWorld:   ecsWorld,
-		State:      GameStateRunning,
 		Running:    true,
+		Bindings:   input.Default(),
+		NewGame:    newGame,
 		TickerRate: time.Millisecond * 33, // ~30 fps
-		BaseTheme:  startingTheme,
-		PathLookup: make([]bool, gameMap.Width*gameMap.Height),
-		Pathfinder: world.NewPathfinder(gameMap.Width, gameMap.Height),
 	}
+	e.StartGame(setup)
 
 	return e
 }
 
+// StartGame throws the game in progress away and builds a fresh one from setup, going straight into play.
+func (e *Engine) StartGame(setup GameSetup) {
+	for _, floor := range e.Floors {
+		floor.PathService.Close()
+	}
+	e.Floors = nil
+	e.tickCount = 0
+	e.ShowNoise = false
+	e.Log = msglog.New(msglog.DefaultCapacity)
+	e.Setup = setup
+	e.BaseTheme = setup.Theme.Variant
+
+	e.NewGame(e, setup)
+	e.activateFloor(0)
+	e.scenes = []Scene{&gameScene{}}
+}
+
+// AddFloor registers another floor of the facility and returns its index.
+func (e *Engine) AddFloor(gameMap *world.Map, ecsWorld *ecs.World) int {
+	e.Floors = append(e.Floors, NewFloor(gameMap, ecsWorld))
+	return len(e.Floors) - 1
+}
+
+// SwitchFloor makes another floor active and carries the traveller (usually the player) along,
+// keeping its position. Returns the traveller's entity ID on the new floor.
+func (e *Engine) SwitchFloor(index int, traveller ecs.Entity) ecs.Entity {
+	if index < 0 || index >= len(e.Floors) || index == e.ActiveFloor {
+		return traveller
+	}
+
+	dst := e.Floors[index]
+	// Anything still being worked out was for this floor, hand it over before leaving so nobody
+	// staying behind waits on it forever
+	systems.DeliverPaths(e.EcsWorld, e.Map, e.Paths, e.PathService.Collect())
+	moved := e.EcsWorld.MoveEntity(traveller, dst.EcsWorld)
+	if (dst.EcsWorld.Masks[moved] & components.MaskPlayerControl) != 0 {
+		dst.EcsWorld.PlayerControls[moved].CurrentPath = nil // The path belonged to the old floor
+		dst.EcsWorld.PlayerControls[moved].Waypoints = nil
+		dst.EcsWorld.PlayerControls[moved].PathPending = false
+	}
+
+	e.activateFloor(index)
+	if (dst.EcsWorld.Masks[moved] & components.MaskPlayerControl) != 0 {
+		e.Log.Post(e.tickCount, msglog.SeverityInfo, fmt.Sprintf("Arrived on deck %d", index+1))
+	}
+	return moved
+}
+
+func (e *Engine) activateFloor(index int) {
+	floor := e.Floors[index]
+	e.ActiveFloor = index
+	e.Map = floor.Map
+	e.EcsWorld = floor.EcsWorld
+	e.PathLookup = floor.PathLookup
+	e.SolidLookup = floor.SolidLookup
+	e.Pathfinder = floor.Pathfinder
+	e.PowerGrid = floor.PowerGrid
+	e.Noise = floor.Noise
+	e.Nav = floor.Nav
+	e.LastSolid = floor.LastSolid
+	e.Paths = floor.Paths
+	e.PathService = floor.PathService
+}
+
 // Run starts the deterministic game loop
 func (e *Engine) Run() error {
 	for !e.Display.ShouldClose() && e.Running {
 		events := e.Display.PollInput()
-		e.handleInputForGlobals(events)
-
-		if e.State == GameStateRunning {
-			e.Update(events) // Calculate all game rules!
+		actions := e.Bindings.Actions(events)
+		e.handleInputForGlobals(events, actions)
+		if !e.Running {
+			break
 		}
 
+		e.Top().Update(e, events, actions) // Only the top scene hears the input and moves on
+
 		e.render() // Paint the results!
 	}
 
+	for _, floor := range e.Floors {
+		floor.PathService.Close()
+	}
 	return nil
 }
 
-func (e *Engine) handleInputForGlobals(events []core.InputEvent) {
+// handleInputForGlobals deals with the input every scene shares: the mouse position and quitting.
+func (e *Engine) handleInputForGlobals(events []core.InputEvent, actions []input.Action) {
+	e.Hovering = false // The display reports the cursor every frame it's over the window
 	for _, event := range events {
-		if event.Quit || event.Key == rl.KeyQ {
+		if event.Mouse == core.MouseHover {
+			e.Hover = entity.Point{X: event.CellX, Y: event.CellY}
+			e.Hovering = true
+		}
+		if event.Quit {
 			e.Running = false
 			return
 		}
-		if event.Key == rl.KeyEscape {
-			e.State = e.State.Flip()
+	}
+	for _, action := range actions {
+		if action == input.ActionQuit {
+			e.Running = false
+			return
 		}
 	}
 }
 
-func (e *Engine) Update(events []core.InputEvent) {
-	e.tickCount++
+// fileMessages stamps every message the floors posted this tick and files them in the log.
+func (e *Engine) fileMessages() {
+	for _, floor := range e.Floors {
+		for _, m := range floor.EcsWorld.Messages {
+			e.Log.Post(e.tickCount, m.Severity, m.Text)
+		}
+		floor.EcsWorld.Messages = floor.EcsWorld.Messages[:0]
+	}
+}
+
+// saveCheckpoint saves the game at a terminal, filing the messages first so the save has them.
+// The message log is all that's written to SavePath so far.
+func (e *Engine) saveCheckpoint(terminal ecs.Entity) {
+	saved := systems.SaveCheckpoint(e.EcsWorld, terminal)
+	e.fileMessages()
+	if !saved || e.SavePath == "" {
+		return
+	}
+
+	if err := e.writeSave(); err != nil {
+		e.Log.Post(e.tickCount, msglog.SeverityDanger, fmt.Sprintf("Checkpoint not written: %v", err))
+	}
+}
 
-	switch e.State {
-	case GameStatePaused:
-		// do nothing, the world is frozen
-		// later: implement it to save the game
-	case GameStateRunning:
-		e.processSimulation(events)
+func (e *Engine) writeSave() error {
+	file, err := os.Create(e.SavePath)
+	if err != nil {
+		return err
 	}
+	if err := e.Log.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadSave reads back the message log the last checkpoint saved to SavePath. Having no save yet isn't an error.
+func (e *Engine) LoadSave() error {
+	if e.SavePath == "" {
+		return nil
+	}
+
+	l, err := msglog.Load(e.SavePath, msglog.DefaultCapacity)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	e.Log = l
+	return nil
+}
+
+// Update runs one tick of the game. The game scene calls it while nothing is on top of it.
+func (e *Engine) Update(events []core.InputEvent, actions []input.Action) {
+	e.tickCount++
+	e.processSimulation(events, actions)
 }
 
 func (e *Engine) processAutopilot() {
 	// Replaced by systems.ProcessAutopilot
 }
 
-func (e *Engine) processSimulation(events []core.InputEvent) {
+func (e *Engine) processSimulation(events []core.InputEvent, actions []input.Action) {
+	player, hasPlayer := systems.FindPlayer(e.EcsWorld)
+	var before components.Position
+	if hasPlayer {
+		before = e.EcsWorld.Positions[player]
+	}
+
+	// Paths asked for last tick were worked out while it finished and the frame drew
+	systems.DeliverPaths(e.EcsWorld, e.Map, e.Paths, e.PathService.Collect())
+
 	// Let the systems tick using the events we polled at the start of the frame!
-	systems.ProcessPlayerInput(e.EcsWorld, events, e.Map)
+	systems.ProcessPlayerInput(e.EcsWorld, actions, e.Map)
+	systems.ProcessMouse(e.EcsWorld, events, e.Map, e.Pathfinder)
 
 	// Run AI movement every 2nd frame (approx 15 times a second)
 	if e.tickCount%6 == 0 {
-		systems.ProcessAutopilot(e.EcsWorld, e.Map, e.Pathfinder)
+		floor := e.Floors[e.ActiveFloor]
+		systems.ProcessAutopilot(e.EcsWorld, e.Map, systems.Navigation{
+			Pathfinder: floor.Pathfinder,
+			Hierarchy:  floor.Nav,
+			Cache:      floor.Paths,
+			Service:    floor.PathService,
+			Snapshots:  floor.Snapshots,
+			Frontier:   floor.Frontier,
+			Blocked:    floor.Blocked,
+		})
+	}
+
+	// Only a fresh step onto a stair or elevator travels, otherwise we'd bounce
+	// straight back from the link we arrived on.
+	if hasPlayer {
+		pos := e.EcsWorld.Positions[player]
+		if pos != before {
+			if target, ok := systems.FloorLinkAt(e.EcsWorld, pos.X, pos.Y); ok {
+				e.SwitchFloor(target, player)
+			}
+		}
+	}
+
+	// Power before light, mains lights need to know their supply
+	systems.ProcessPower(e.EcsWorld, e.Map, e.PowerGrid)
+
+	// Casting light and sight asks about solids thousands of times, so look them up once
+	copy(e.LastSolid, e.SolidLookup)
+	clear(e.SolidLookup)
+	solidMask := components.MaskPosition | components.MaskSolid
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (e.EcsWorld.Masks[i] & solidMask) == solidMask {
+			pos := e.EcsWorld.Positions[i]
+			if e.Map.GetTile(pos.X, pos.Y) != nil {
+				e.SolidLookup[e.Map.GetIndex(pos.X, pos.Y)] = true
+			}
+		}
+	}
+
+	// Doors that opened or closed change the routes through their clusters. The doors already
+	// dropped the cached routes as they moved, but anything planned since went over the clusters
+	// as they were, so those routes go too.
+	changed := false
+	for i, solid := range e.SolidLookup {
+		if solid != e.LastSolid[i] {
+			e.Nav.Invalidate(i%e.Map.Width, i/e.Map.Width)
+			changed = true
+		}
+	}
+	if changed {
+		e.Map.BumpRevision()
+	}
+
+	// Air and water move around the closed doors, fire burns through the air, then the player breathes what's left
+	systems.ProcessAtmosphere(e.EcsWorld, e.Map, e.SolidLookup)
+	systems.ProcessWater(e.EcsWorld, e.Map, e.SolidLookup)
+	if e.tickCount%fireStepInterval == 0 {
+		systems.ProcessFire(e.EcsWorld, e.Map, e.SolidLookup)
 	}
+	systems.ProcessSurvival(e.EcsWorld, e.Map)
 
-	powerOn := systems.IsPowerActive(e.EcsWorld)
+	// Everything that made a sound this tick, heard through the doors as they are now
+	systems.ProcessNoise(e.EcsWorld, e.Map, e.Noise, e.SolidLookup)
+
+	// Light first, FOV only reveals what is lit
+	systems.ProcessLighting(e.EcsWorld, e.Map, e.tickCount, e.blocksLight)
 
 	// Calculate FOV
 	targetMask := components.MaskPlayerControl | components.MaskPosition
@@ -125,66 +389,85 @@ func (e *Engine) processSimulation(events []core.InputEvent) {
 		if (e.EcsWorld.Masks[i] & targetMask) == targetMask {
 			pos := e.EcsWorld.Positions[i]
 
-			e.Map.ComputeFOV(pos.X, pos.Y, fovRadius, func(x, y int) bool {
-				// 1. Is the map tile a wall?
-				if !e.Map.IsWalkable(x, y) {
-					return true
-				}
-				// 2. Is there a Solid entity (like a closed door)?
-				return systems.IsSolidAt(e.EcsWorld, x, y)
-			}, powerOn)
+			e.Map.ComputeFOV(pos.X, pos.Y, sightRadius, e.blocksLight)
 			break // Compute FOV for the first player found
 		}
 	}
+
+	e.fileMessages()
+}
+
+// blocksLight stops both sight and light at walls and Solid entities (like a closed door).
+func (e *Engine) blocksLight(x, y int) bool {
+	if !e.Map.IsWalkable(x, y) {
+		return true
+	}
+	return e.SolidLookup[e.Map.GetIndex(x, y)]
 }
 
+// Pause puts the pause menu over the game, if the game is what's being played.
 func (e *Engine) Pause() {
-	e.State = GameStatePaused
+	if _, playing := e.Top().(*gameScene); playing {
+		e.Push(newPauseScene(e))
+	}
 }
 
+// Resume takes the pause menu off the game again.
 func (e *Engine) Resume() {
-	e.State = GameStateRunning
+	if _, paused := e.Top().(*pauseScene); paused {
+		e.Pop()
+	}
 }
 
-// render updates the game screen by drawing the map, GameState overlays,
-// and other visual elements to the Display buffer.
-//
-// Renders top to bottom as separate layers.
+// render updates the game screen by drawing the scenes, bottom to top. Scenes under an
+// overlay are drawn frozen beneath it, anything under an opaque scene isn't drawn at all.
 func (e *Engine) render() {
 	e.Display.BeginFrame()
 	e.Display.Clear(core.Black) // Black background
 
-	// Determine active theme based on global states
-	activeTheme := e.BaseTheme
-
-	if e.State == GameStatePaused {
-		activeTheme = world.TileVariantPaused
+	first := len(e.scenes) - 1
+	for first > 0 && e.scenes[first].Overlay() {
+		first--
+	}
+	for _, scene := range e.scenes[first:] {
+		scene.Render(e)
 	}
 
-	e.renderMapLayer(activeTheme)
-	systems.RenderEntities(e.EcsWorld, e.Display, e.Map)
-	e.renderHUD()
+	e.Display.EndFrame()
+}
 
-	switch e.State {
-	case GameStatePaused:
-		e.renderPauseMenu()
-	default:
+// renderTooltip highlights the tile under the mouse and says what's there, just above it
+// (or below, on the top row).
+func (e *Engine) renderTooltip() {
+	if !e.Hovering || e.Map.GetTile(e.Hover.X, e.Hover.Y) == nil {
+		return
 	}
+	e.Display.DrawRect(e.Hover.X, e.Hover.Y, tooltipHighlight)
 
-	e.Display.EndFrame()
+	y := e.Hover.Y - 1
+	if y < 0 {
+		y = e.Hover.Y + 1
+	}
+	text := systems.DescribeAt(e.EcsWorld, e.Map, e.Hover.X, e.Hover.Y)
+	x := max(0, min(e.Hover.X, e.Map.Width-len(text)))
+	e.drawText(x, y, text, core.Yellow)
 }
 
-func (e *Engine) renderPauseMenu() {
-	e.drawTextCentered(14, "=== SYSTEM PAUSED ===", core.Red)
-	e.drawTextCentered(16, "Press [ESC] to Resume", core.White)
-	e.drawTextCentered(17, "Press [Q] to Quit", core.Gray)
+// renderNoiseOverlay draws how loud every tile is, fog of war or not. It's a debugging aid for AI hearing.
+func (e *Engine) renderNoiseOverlay() {
+	for y := 0; y < e.Map.Height; y++ {
+		for x := 0; x < e.Map.Width; x++ {
+			if level := e.Noise.At(x, y); level > 0 {
+				look := world.TileOverlayNoise.At(level, noiseOverlayScale)
+				e.Display.DrawText(x, y, look.Char, look.Color)
+			}
+		}
+	}
 }
 
 func (e *Engine) renderMapLayer(theme world.TileVariant) {
 	clear(e.PathLookup)
 
-	powerOn := systems.IsPowerActive(e.EcsWorld)
-
 	// Collect paths from all PlayerControl entities to draw the red autopilot line
 	targetMask := components.MaskPlayerControl
 	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
@@ -270,14 +553,21 @@ func (e *Engine) renderMapLayer(theme world.TileVariant) {
 					}
 				}
 
-				// Apply depth shading to the foreground color based on distance
-				if !powerOn {
-					if tile.Distance > 3 {
-						color = display.DarkenColor(color, 2)
-					}
-					if tile.Distance > 5 {
-						color = display.DarkenColor(color, 2)
-					}
+				// Standing water shows how deep it is, whatever the theme
+				if depth := e.Map.WaterAt(x, y); depth >= world.DryDepth {
+					puddle := world.TileOverlayWater.At(int(min(depth, 1)*waterBands)+1, waterBands)
+					char, color = puddle.Char, puddle.Color
+				}
+
+				// Tint by the light on the tile
+				if e.Map.Light != nil {
+					color = world.TintColor(color, e.Map.LightAt(x, y))
+				}
+
+				// Flames light themselves, draw them over the theme untinted
+				if fire := e.Map.FireAt(x, y); fire > 0 {
+					flame := world.TileOverlayFire.At(fire, world.MaxFireIntensity)
+					char, color = flame.Char, flame.Color
 				}
 
 				e.Display.DrawText(x, y, char, color)
@@ -346,82 +636,7 @@ func (e *Engine) renderMapLayer(theme world.TileVariant) {
 	}
 }
 
-func (e *Engine) renderHUD() {
-	// The Y-coordinate where the map ends and the HUD begins
-	hudY := e.Map.Height
-
-	// strings.Repeat is a highly optimized Go standard library function
-	divider := strings.Repeat("═", e.Map.Width)
-	e.drawText(0, hudY, divider, core.Gray)
-
-	statusText := "HEALTHY"
-	autopilotEngaged := false
-	var interactPrompt string // Store the prompt text if near an interactable
-
-	// Find player state for HUD
-	targetMask := components.MaskPlayerControl | components.MaskPosition
-	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
-		if (e.EcsWorld.Masks[i] & targetMask) == targetMask {
-			control := e.EcsWorld.PlayerControls[i]
-			position := e.EcsWorld.Positions[i]
-
-			autopilotEngaged = control.Autopilot
-			statusText = control.Status.Title()
-
-			// Check for adjacent interactables
-			interactMask := components.MaskPosition | components.MaskInteractable
-			for j := ecs.Entity(0); j < ecs.MaxEntities; j++ {
-				if (e.EcsWorld.Masks[j] & interactMask) == interactMask {
-					targetPos := e.EcsWorld.Positions[j]
-					dx := targetPos.X - position.X
-					dy := targetPos.Y - position.Y
-					if (dx*dx + dy*dy) <= 2 { // 1 tile away
-						interact := e.EcsWorld.Interactables[j]
-						interactPrompt = interact.Prompt
-						break
-					}
-				}
-			}
-
-			break
-		}
-	}
-
-	e.drawText(2, hudY+1, fmt.Sprintf(" STATUS: %s ", statusText), core.Cyan)
-
-	if autopilotEngaged {
-		e.drawText(25, hudY+1, "[ NAV-COM: AUTOPILOT ENGAGED ]", core.Red)
-	} else {
-		e.drawText(25, hudY+1, "[ NAV-COM: MANUAL OVERRIDE ]  ", core.Gray)
-	}
-
-	// %06d formats the integer to always be 6 digits (e.g., 000142)
-	cycleText := fmt.Sprintf(" CYCLE: %06d ", e.tickCount)
-	e.drawText(e.Map.Width-len(cycleText)-2, hudY+1, cycleText, core.White)
-
-	if interactPrompt != "" {
-		// Draw the prompt blinking above the HUD
-		if e.tickCount%30 < 15 {
-			e.drawTextCentered(hudY-1, fmt.Sprintf("[ %s ]", interactPrompt), core.Green)
-		}
-	}
-
-	controls := " [W/A/S/D] Move    [P] Toggle Autopilot    [ESC] Pause System    [Q] Abort"
-	e.drawText(2, hudY+2, controls, core.Gray)
-}
-
-func (e *Engine) drawTextCentered(y int, text string, color core.Color) {
-	centerX := e.Map.Width / 2
-	halfText := len(text) / 2
-	x := centerX - halfText
-
-	
-
-	e.Display.DrawText(x, y, text, color)
-}
-
 func (e *Engine) drawText(x, y int, text string, color core.Color) {
-	
 
 	e.Display.DrawText(x, y, text, color)
 }
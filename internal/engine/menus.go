@@ -0,0 +1,348 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+	"github.com/vikash-paf/derelict-facility/internal/input"
+	"github.com/vikash-paf/derelict-facility/internal/ui"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+const maxSeed = 999999 // Seeds the new game screen offers, six digits are easy to share
+
+// menu is the list of choices at the heart of every menu scene, with the focus that routes the
+// player's actions to it, or to a dialog opened over it.
+type menu struct {
+	list  ui.List
+	focus ui.Focus
+}
+
+func newMenu(onActivate func(item int)) *menu {
+	m := &menu{list: ui.List{Color: core.Gray, SelectedColor: core.White, OnActivate: onActivate}}
+	m.focus.Add(&m.list)
+	return m
+}
+
+// draw puts the menu in a panel in the middle of area, between the widgets above and below it,
+// and any open dialog on top.
+func (m *menu) draw(e *Engine, area ui.Rect, title string, color core.Color, above, below []ui.Widget) {
+	children := append(append(append([]ui.Widget{}, above...), &m.list), below...)
+	panel := &ui.Panel{
+		Title:      title,
+		Color:      color,
+		Background: core.Black,
+		Padding:    2,
+		Child:      &ui.Column{Gap: 1, Children: children},
+	}
+	panel.Draw(e.Display, area.Center(panel.Size()))
+
+	if dialog := m.focus.Modal(); dialog != nil {
+		dialog.Draw(e.Display, area.Center(dialog.Size()))
+	}
+}
+
+// menuHint tells the player how to work a menu with their bindings.
+func (e *Engine) menuHint() *ui.Label {
+	return &ui.Label{Text: fmt.Sprintf("[%s/%s] Select   [%s] Confirm",
+		e.Bindings.Key(input.ActionMoveNorth), e.Bindings.Key(input.ActionMoveSouth), e.Bindings.Key(input.ActionInteract)),
+		Color: core.DarkGray, Align: ui.AlignCenter}
+}
+
+// keyed pads a menu entry out so the keys bound to it line up on the right.
+func keyed(label string, key string) string {
+	if key == "" {
+		return label
+	}
+	return fmt.Sprintf("%-16s [%s]", label, key)
+}
+
+// titleScene is the first thing on screen, over the game that's already been built.
+type titleScene struct {
+	menu *menu
+}
+
+// Title screen entries
+const (
+	titleStart = iota
+	titleNewGame
+	titleQuit
+)
+
+func newTitleScene(e *Engine) *titleScene {
+	t := &titleScene{}
+	t.menu = newMenu(func(item int) {
+		switch item {
+		case titleStart:
+			e.Pop()
+		case titleNewGame:
+			e.Push(newSetupScene(e))
+		case titleQuit:
+			e.Running = false
+		}
+	})
+	t.menu.list.Items = []string{titleStart: "Start Mission", titleNewGame: "New Game...", titleQuit: "Quit"}
+	return t
+}
+
+func (t *titleScene) Overlay() bool { return false }
+
+func (t *titleScene) Update(e *Engine, events []core.InputEvent, actions []input.Action) {
+	for _, action := range actions {
+		t.menu.focus.HandleAction(action)
+		if e.Top() != t {
+			return
+		}
+	}
+}
+
+func (t *titleScene) Render(e *Engine) {
+	w, h := e.Display.Size()
+	screen := ui.Rect{W: w, H: h}
+
+	subtitle := e.Setup.Theme.Name
+	if e.Setup.Seeded {
+		subtitle = fmt.Sprintf("seed %06d  ·  %s", e.Setup.Seed, subtitle)
+	}
+	banner := &ui.Column{Children: []ui.Widget{
+		&ui.Label{Text: "D E R E L I C T   F A C I L I T Y", Color: core.Cyan, Align: ui.AlignCenter},
+		&ui.Label{Text: subtitle, Color: core.DarkGray, Align: ui.AlignCenter},
+	}}
+	banner.Draw(e.Display, ui.Rect{Y: h / 4, W: w, H: 2})
+
+	t.menu.draw(e, screen, "", core.Gray, nil, []ui.Widget{e.menuHint()})
+}
+
+// setupScene picks the seed and theme of a new game.
+type setupScene struct {
+	menu    *menu
+	setup   GameSetup
+	theme   int   // Index into world.TileVariants
+	entries []int // The entries on offer, top to bottom; the seed ones only for a seeded game
+}
+
+// New game screen entries
+const (
+	setupSeed = iota
+	setupTheme
+	setupRandom
+	setupLaunch
+	setupBack
+)
+
+func newSetupScene(e *Engine) *setupScene {
+	s := &setupScene{setup: e.Setup}
+	for i, theme := range world.TileVariants {
+		if theme.Name == e.Setup.Theme.Name {
+			s.theme = i
+		}
+	}
+	s.entries = []int{setupSeed, setupTheme, setupRandom, setupLaunch, setupBack}
+	if !e.Setup.Seeded {
+		s.entries = []int{setupTheme, setupLaunch, setupBack}
+	}
+	s.menu = newMenu(func(item int) {
+		switch s.entries[item] {
+		case setupRandom:
+			s.setup.Seed = uint64(rand.IntN(maxSeed + 1))
+		case setupLaunch:
+			e.StartGame(s.setup)
+		case setupBack:
+			e.Pop()
+		}
+	})
+	return s
+}
+
+func (s *setupScene) Overlay() bool { return false }
+
+func (s *setupScene) Update(e *Engine, events []core.InputEvent, actions []input.Action) {
+	for _, action := range actions {
+		// Left and right turn the value on the selected line
+		step := 0
+		switch action {
+		case input.ActionMoveWest:
+			step = -1
+		case input.ActionMoveEast:
+			step = 1
+		case input.ActionPause:
+			e.Pop()
+			return
+		}
+
+		selected := s.entries[s.menu.list.Selected]
+		switch {
+		case step != 0 && selected == setupSeed:
+			s.setup.Seed = uint64((int(s.setup.Seed) + step + maxSeed + 1) % (maxSeed + 1))
+		case step != 0 && selected == setupTheme:
+			s.theme = (s.theme + step + len(world.TileVariants)) % len(world.TileVariants)
+			s.setup.Theme = world.TileVariants[s.theme]
+		default:
+			s.menu.focus.HandleAction(action)
+		}
+		if e.Top() != s {
+			return
+		}
+	}
+}
+
+func (s *setupScene) Render(e *Engine) {
+	w, h := e.Display.Size()
+
+	labels := []string{
+		setupSeed:   fmt.Sprintf("Seed    ◄ %06d ►", s.setup.Seed),
+		setupTheme:  fmt.Sprintf("Theme   ◄ %s ►", s.setup.Theme.Name),
+		setupRandom: "Random Seed",
+		setupLaunch: "Launch",
+		setupBack:   "Back",
+	}
+	s.menu.list.Items = s.menu.list.Items[:0]
+	for _, entry := range s.entries {
+		s.menu.list.Items = append(s.menu.list.Items, labels[entry])
+	}
+
+	// A strip of wall and floor in the chosen theme
+	theme := s.setup.Theme.Variant
+	wall, floor := theme[world.TileTypeWall], theme[world.TileTypeFloor]
+	preview := &ui.Row{Children: []ui.Widget{
+		&ui.Fill{},
+		&ui.Label{Text: strings.Repeat(wall.Char, 4), Color: wall.Color},
+		&ui.Label{Text: strings.Repeat(floor.Char, 8), Color: floor.Color},
+		&ui.Label{Text: strings.Repeat(wall.Char, 4), Color: wall.Color},
+		&ui.Fill{},
+	}}
+
+	hint := &ui.Label{Text: fmt.Sprintf("[%s/%s] Change", e.Bindings.Key(input.ActionMoveWest), e.Bindings.Key(input.ActionMoveEast)),
+		Color: core.DarkGray, Align: ui.AlignCenter}
+	s.menu.draw(e, ui.Rect{W: w, H: h}, "NEW GAME", core.Cyan, nil, []ui.Widget{preview, hint, e.menuHint()})
+}
+
+// pauseScene is the pause menu, over the frozen game.
+type pauseScene struct {
+	menu *menu
+}
+
+// Pause menu entries
+const (
+	pauseResume = iota
+	pauseNoise
+	pauseLog
+	pauseAbort
+)
+
+func newPauseScene(e *Engine) *pauseScene {
+	p := &pauseScene{}
+	p.menu = newMenu(func(item int) {
+		switch item {
+		case pauseResume:
+			e.Resume()
+		case pauseNoise:
+			e.ShowNoise = !e.ShowNoise
+		case pauseLog:
+			e.Push(&logScene{})
+		case pauseAbort:
+			p.menu.focus.Open(ui.NewDialog("ABORT", "Abandon the facility?", []string{"Stay", "Abort"}, func(button int) {
+				p.menu.focus.Close()
+				if button == 1 {
+					e.Running = false
+				}
+			}))
+		}
+	})
+	return p
+}
+
+func (p *pauseScene) Overlay() bool { return true }
+
+func (p *pauseScene) Update(e *Engine, events []core.InputEvent, actions []input.Action) {
+	for _, action := range actions {
+		switch action {
+		case input.ActionPause:
+			if p.menu.focus.Modal() != nil {
+				p.menu.focus.Close()
+			} else {
+				e.Resume()
+			}
+		case input.ActionToggleNoise:
+			e.ShowNoise = !e.ShowNoise
+		case input.ActionMessageLog:
+			e.Push(&logScene{})
+		default:
+			p.menu.focus.HandleAction(action)
+		}
+		if e.Top() != p {
+			return
+		}
+	}
+}
+
+func (p *pauseScene) Render(e *Engine) {
+	noise := "Show Noise Map"
+	if e.ShowNoise {
+		noise = "Hide Noise Map"
+	}
+	p.menu.list.Items = []string{
+		pauseResume: keyed("Resume", e.Bindings.Key(input.ActionPause)),
+		pauseNoise:  keyed(noise, e.Bindings.Key(input.ActionToggleNoise)),
+		pauseLog:    keyed("Message Log", e.Bindings.Key(input.ActionMessageLog)),
+		pauseAbort:  keyed("Abort Mission", e.Bindings.Key(input.ActionQuit)),
+	}
+
+	stats := e.Paths.Stats()
+	cache := &ui.Label{Text: fmt.Sprintf("Path cache: %d hits, %d misses (%.0f%%), %d routes",
+		stats.Hits, stats.Misses, stats.HitRate()*100, stats.Size), Color: core.DarkGray}
+
+	view, _ := e.layout()
+	p.menu.draw(e, view, "SYSTEM PAUSED", core.Red, nil, []ui.Widget{cache})
+}
+
+// gameOverScene is shown over the facility once the player is dead.
+type gameOverScene struct {
+	menu *menu
+}
+
+// Game over entries
+const (
+	gameOverRetry = iota
+	gameOverNewGame
+	gameOverQuit
+)
+
+func newGameOverScene(e *Engine) *gameOverScene {
+	g := &gameOverScene{}
+	g.menu = newMenu(func(item int) {
+		switch item {
+		case gameOverRetry:
+			e.StartGame(e.Setup)
+		case gameOverNewGame:
+			e.Push(newSetupScene(e))
+		case gameOverQuit:
+			e.Running = false
+		}
+	})
+	g.menu.list.Items = []string{gameOverRetry: "Try Again", gameOverNewGame: "New Game...", gameOverQuit: "Quit"}
+	return g
+}
+
+func (g *gameOverScene) Overlay() bool { return true }
+
+func (g *gameOverScene) Update(e *Engine, events []core.InputEvent, actions []input.Action) {
+	for _, action := range actions {
+		g.menu.focus.HandleAction(action)
+		if e.Top() != g {
+			return
+		}
+	}
+}
+
+func (g *gameOverScene) Render(e *Engine) {
+	summary := []ui.Widget{
+		&ui.Label{Text: "Your suit telemetry has flatlined.", Color: core.White, Align: ui.AlignCenter},
+		&ui.Label{Text: fmt.Sprintf("Lasted %d cycles, %.0f%% of deck %d mapped", e.tickCount, e.Map.Coverage()*100, e.ActiveFloor+1),
+			Color: core.Gray, Align: ui.AlignCenter},
+	}
+	view, _ := e.layout()
+	g.menu.draw(e, view, "SIGNAL LOST", core.Red, summary, []ui.Widget{e.menuHint()})
+}
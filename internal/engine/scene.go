@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/core"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/input"
+	"github.com/vikash-paf/derelict-facility/internal/systems"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+/*
+	The engine runs a stack of scenes. The top one gets the frame's input and is the only one
+	that moves on; the game itself is a scene, so anything pushed over it (the pause menu, the
+	message log, a terminal) freezes it until it's popped again. Overlays let the scene below
+	show through, frozen and drawn first.
+*/
+
+// Scene is one screen of the game with its own input handling and drawing.
+type Scene interface {
+	// Update handles the frame's input and moves the scene on. Only the top scene is updated.
+	Update(e *Engine, events []core.InputEvent, actions []input.Action)
+	Render(e *Engine)
+	// Overlay reports whether the scene beneath shows through, like the game under the pause menu.
+	Overlay() bool
+}
+
+// Push puts a scene on top of the stack, where it takes the input.
+func (e *Engine) Push(scene Scene) {
+	e.scenes = append(e.scenes, scene)
+}
+
+// Pop takes the top scene off the stack. The bottom scene always stays.
+func (e *Engine) Pop() {
+	if len(e.scenes) > 1 {
+		e.scenes = e.scenes[:len(e.scenes)-1]
+	}
+}
+
+// Top is the scene taking the input.
+func (e *Engine) Top() Scene {
+	return e.scenes[len(e.scenes)-1]
+}
+
+// ShowTitle opens the title screen over the game that's already been built.
+func (e *Engine) ShowTitle() {
+	e.Push(newTitleScene(e))
+}
+
+// gameScene is the facility itself: the map, the entities on it and the HUD.
+type gameScene struct{}
+
+func (g *gameScene) Overlay() bool { return false }
+
+func (g *gameScene) Update(e *Engine, events []core.InputEvent, actions []input.Action) {
+	for _, action := range actions {
+		switch action {
+		case input.ActionPause:
+			e.Pause()
+		case input.ActionMessageLog:
+			e.Push(&logScene{})
+		case input.ActionInventory:
+			e.Push(newInventoryScene(e))
+		case input.ActionToggleNoise:
+			e.ShowNoise = !e.ShowNoise
+		}
+	}
+	if e.Top() != g {
+		return // Whatever just opened freezes the game from this frame on
+	}
+
+	e.Update(events, actions) // Calculate all game rules!
+
+	// The simulation may have ended the game, or logged the player in to a terminal
+	player, ok := systems.FindPlayer(e.EcsWorld)
+	if ok && e.EcsWorld.PlayerControls[player].Status == components.PlayerStatusDead {
+		e.Push(newGameOverScene(e))
+		return
+	}
+	terminalMask := components.MaskTerminal
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (e.EcsWorld.Masks[i]&terminalMask) == terminalMask && e.EcsWorld.Terminals[i].InUse {
+			e.Push(newTerminalScene(e, i))
+			return
+		}
+	}
+}
+
+func (g *gameScene) Render(e *Engine) {
+	// The facility greys out behind whatever is over it
+	theme := e.BaseTheme
+	covered := e.Top() != g
+	if covered {
+		theme = world.TileVariantPaused
+	}
+
+	e.renderMapLayer(theme)
+	if e.ShowNoise {
+		e.renderNoiseOverlay()
+	}
+	systems.RenderEntities(e.EcsWorld, e.Display, e.Map)
+	if !covered {
+		e.renderTooltip()
+	}
+	e.renderHUD()
+}
@@ -0,0 +1,263 @@
+package engine
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/core"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/input"
+	"github.com/vikash-paf/derelict-facility/internal/msglog"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+// fakeDisplay is a Display that draws nothing, for running the engine without a window.
+type fakeDisplay struct{}
+
+func (d *fakeDisplay) Init(gridWidth, gridHeight int, title string) error                { return nil }
+func (d *fakeDisplay) Close()                                                            {}
+func (d *fakeDisplay) Size() (gridWidth, gridHeight int)                                 { return 80, 30 }
+func (d *fakeDisplay) ShouldClose() bool                                                 { return false }
+func (d *fakeDisplay) BeginFrame()                                                       {}
+func (d *fakeDisplay) EndFrame()                                                         {}
+func (d *fakeDisplay) Clear(color core.Color)                                            {}
+func (d *fakeDisplay) DrawRect(gridX, gridY int, color core.Color)                       {}
+func (d *fakeDisplay) DrawText(gridX, gridY int, text string, color core.Color)          {}
+func (d *fakeDisplay) DrawSprite(gridX, gridY int, sheetX, sheetY int, color core.Color) {}
+func (d *fakeDisplay) PollInput() []core.InputEvent                                      { return nil }
+
+// recordingScene notes its name in rendered every time it's drawn.
+type recordingScene struct {
+	name     string
+	overlay  bool
+	rendered *[]string
+}
+
+func (r *recordingScene) Overlay() bool { return r.overlay }
+
+func (r *recordingScene) Update(e *Engine, events []core.InputEvent, actions []input.Action) {}
+
+func (r *recordingScene) Render(e *Engine) {
+	*r.rendered = append(*r.rendered, r.name)
+}
+
+// newTestEngine starts a game on a single open room, returning the player and the terminal next to them.
+func newTestEngine(t *testing.T) (*Engine, ecs.Entity, ecs.Entity) {
+	var player, terminal ecs.Entity
+	newGame := func(e *Engine, setup GameSetup) {
+		m := world.NewMap(10, 5)
+		for y := 0; y < m.Height; y++ {
+			for x := 0; x < m.Width; x++ {
+				m.SetTile(x, y, world.Tile{Type: world.TileTypeFloor, Walkable: true})
+			}
+		}
+
+		w := ecs.NewWorld()
+		player = w.CreateEntity()
+		w.AddPosition(player, components.Position{X: 2, Y: 2})
+		w.AddPlayerControl(player, components.PlayerControl{})
+		terminal = w.CreateEntity()
+		w.AddPosition(terminal, components.Position{X: 3, Y: 2})
+		w.AddTerminal(terminal, components.Terminal{})
+		e.AddFloor(m, w)
+	}
+
+	e := NewEngine(&fakeDisplay{}, newGame, GameSetup{Seed: 1, Seeded: true, Theme: world.TileVariants[0]})
+	t.Cleanup(func() {
+		for _, floor := range e.Floors {
+			floor.PathService.Close()
+		}
+	})
+	return e, player, terminal
+}
+
+func TestEngine_PushPop(t *testing.T) {
+	e, _, _ := newTestEngine(t)
+	game := e.Top()
+	if _, ok := game.(*gameScene); !ok {
+		t.Fatalf("Expected a new game to start in play, got %T", game)
+	}
+
+	var rendered []string
+	log := &recordingScene{name: "log", overlay: true, rendered: &rendered}
+	e.Push(log)
+	if e.Top() != log {
+		t.Fatalf("Expected the pushed scene on top")
+	}
+
+	e.Pop()
+	if e.Top() != game {
+		t.Fatalf("Expected popping to uncover the game")
+	}
+
+	// The bottom scene always stays
+	e.Pop()
+	if len(e.scenes) != 1 || e.Top() != game {
+		t.Errorf("Expected the game to stay after popping the last scene, got %d scenes", len(e.scenes))
+	}
+}
+
+func TestEngine_Render(t *testing.T) {
+	tests := []struct {
+		name     string
+		overlays []bool // Bottom to top, whether each scene is an overlay
+		want     []string
+	}{
+		{"single scene", []bool{false}, []string{"0"}},
+		{"overlay shows the scene below", []bool{false, true}, []string{"0", "1"}},
+		{"overlays stack", []bool{false, true, true}, []string{"0", "1", "2"}},
+		{"opaque scene hides the ones below", []bool{false, true, false}, []string{"2"}},
+		{"overlay over an opaque scene", []bool{false, false, true}, []string{"1", "2"}},
+		{"bottom scene drawn even as an overlay", []bool{true, true}, []string{"0", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _, _ := newTestEngine(t)
+			var rendered []string
+			e.scenes = nil
+			for i, overlay := range tt.overlays {
+				e.Push(&recordingScene{name: string(rune('0' + i)), overlay: overlay, rendered: &rendered})
+			}
+
+			e.render()
+			if !reflect.DeepEqual(rendered, tt.want) {
+				t.Errorf("Expected %v drawn, got %v", tt.want, rendered)
+			}
+		})
+	}
+}
+
+func TestEngine_PauseResume(t *testing.T) {
+	e, _, _ := newTestEngine(t)
+	game := e.Top()
+
+	e.Resume()
+	if e.Top() != game {
+		t.Fatalf("Expected Resume to leave an unpaused game alone")
+	}
+
+	e.Pause()
+	paused, ok := e.Top().(*pauseScene)
+	if !ok {
+		t.Fatalf("Expected Pause to open the pause menu, got %T", e.Top())
+	}
+	e.Pause()
+	if e.Top() != paused || len(e.scenes) != 2 {
+		t.Errorf("Expected Pause not to stack a second pause menu, got %d scenes", len(e.scenes))
+	}
+
+	// With the log open over the pause menu neither does anything
+	log := &logScene{}
+	e.Push(log)
+	e.Pause()
+	e.Resume()
+	if e.Top() != log || len(e.scenes) != 3 {
+		t.Errorf("Expected Pause and Resume to leave the log alone, got %T on top of %d scenes", e.Top(), len(e.scenes))
+	}
+
+	e.Pop()
+	e.Resume()
+	if e.Top() != game {
+		t.Errorf("Expected Resume to take the pause menu off the game, got %T", e.Top())
+	}
+}
+
+func TestEngine_StartGame(t *testing.T) {
+	e, _, _ := newTestEngine(t)
+	e.Update(nil, nil)
+	e.Pause()
+	e.Push(&logScene{})
+	oldWorld := e.EcsWorld
+
+	setup := GameSetup{Seed: 42, Seeded: true, Theme: world.TileVariants[0]}
+	e.StartGame(setup)
+
+	if len(e.scenes) != 1 {
+		t.Fatalf("Expected a new game to drop every scene but the game, got %d", len(e.scenes))
+	}
+	if _, ok := e.Top().(*gameScene); !ok {
+		t.Errorf("Expected the new game in play, got %T", e.Top())
+	}
+	if e.Setup != setup || e.tickCount != 0 || e.EcsWorld == oldWorld {
+		t.Errorf("Expected a fresh game from %+v, got %+v at tick %d", setup, e.Setup, e.tickCount)
+	}
+}
+
+func TestGameScene_Terminal(t *testing.T) {
+	e, _, terminal := newTestEngine(t)
+	game := e.Top()
+
+	e.EcsWorld.Terminals[terminal].InUse = true
+	game.Update(e, nil, nil)
+	term, ok := e.Top().(*terminalScene)
+	if !ok {
+		t.Fatalf("Expected a terminal in use to open its screen, got %T", e.Top())
+	}
+
+	// Saving is filed straight away, stamped with the tick the game froze on
+	logged := e.Log.Len()
+	term.menu.list.OnActivate(terminalSave)
+	if e.Log.Len() != logged+1 {
+		t.Fatalf("Expected the checkpoint to be logged as it's saved")
+	}
+	if m := e.Log.At(e.Log.Len() - 1); m.Tick != e.tickCount || m.Severity != msglog.SeverityGood {
+		t.Errorf("Expected a good message at tick %d, got %+v", e.tickCount, m)
+	}
+
+	term.menu.list.OnActivate(terminalLogOff)
+	if e.EcsWorld.Terminals[terminal].InUse {
+		t.Errorf("Expected logging off to free the terminal")
+	}
+	if e.Top() != game {
+		t.Errorf("Expected logging off to go back to the game, got %T", e.Top())
+	}
+}
+
+func TestEngine_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "derelict.sav")
+
+	e, _, terminal := newTestEngine(t)
+	e.SavePath = path
+	e.Log.Post(3, msglog.SeverityWarning, "The door has no power")
+	e.saveCheckpoint(terminal)
+	saved := e.Log.Tail(e.Log.Len())
+
+	// The checkpoint is written once, saving again leaves it alone
+	e.Log.Post(9, msglog.SeverityInfo, "Door opened")
+	e.saveCheckpoint(terminal)
+
+	loaded, _, _ := newTestEngine(t)
+	loaded.SavePath = path
+	if err := loaded.LoadSave(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := loaded.Log.Tail(loaded.Log.Len()); !reflect.DeepEqual(got, saved) {
+		t.Errorf("Expected %v back, got %v", saved, got)
+	}
+
+	// With nothing saved yet the game starts with an empty log
+	fresh, _, _ := newTestEngine(t)
+	fresh.SavePath = filepath.Join(t.TempDir(), "missing.sav")
+	if err := fresh.LoadSave(); err != nil || fresh.Log.Len() != 0 {
+		t.Errorf("Expected no error and no messages without a save, got %v and %d messages", err, fresh.Log.Len())
+	}
+}
+
+func TestGameScene_GameOver(t *testing.T) {
+	e, player, _ := newTestEngine(t)
+	game := e.Top()
+
+	game.Update(e, nil, nil)
+	if e.Top() != game {
+		t.Fatalf("Expected a healthy player to keep playing, got %T", e.Top())
+	}
+
+	e.EcsWorld.PlayerControls[player].Status = components.PlayerStatusDead
+	game.Update(e, nil, nil)
+	if _, ok := e.Top().(*gameOverScene); !ok {
+		t.Errorf("Expected a dead player to end the game, got %T", e.Top())
+	}
+}
@@ -0,0 +1,368 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/core"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/input"
+	"github.com/vikash-paf/derelict-facility/internal/systems"
+	"github.com/vikash-paf/derelict-facility/internal/ui"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+/*
+	The HUD and the scenes drawn over the game are laid out with the ui toolkit every frame,
+	from the window size the Display reports, so they follow the window rather than the map.
+	The HUD takes the bottom hudHeight rows and everything above it is the view.
+*/
+
+const (
+	hudLogLines    = 2               // Latest messages shown under the HUD
+	hudHeight      = 3 + hudLogLines // Divider, two status lines and the latest messages
+	hudBarWidth    = 5               // Cells in each of the HUD's O2, toxin and mapped bars
+	logScrollWheel = 3               // Messages one notch of the mouse wheel scrolls the log view by
+)
+
+// layout splits the window into the view above the HUD and the HUD itself.
+func (e *Engine) layout() (view, hud ui.Rect) {
+	w, h := e.Display.Size()
+	hudY := max(h-hudHeight, 0)
+	return ui.Rect{W: w, H: hudY}, ui.Rect{Y: hudY, W: w, H: h - hudY}
+}
+
+// logViewLines is how many messages fit in the log view, inside its border and under its header.
+func (e *Engine) logViewLines() int {
+	view, _ := e.layout()
+	return max(view.H-4, 1)
+}
+
+// logScene lists the message history over the map, newest at the bottom.
+type logScene struct {
+	scroll int // Messages scrolled back from the newest
+}
+
+func (l *logScene) Overlay() bool { return true }
+
+func (l *logScene) Update(e *Engine, events []core.InputEvent, actions []input.Action) {
+	for _, event := range events {
+		if event.Mouse == core.MouseWheel {
+			l.scrollBy(e, event.Wheel*logScrollWheel)
+		}
+	}
+	for _, action := range actions {
+		switch action {
+		case input.ActionMessageLog, input.ActionPause:
+			e.Pop()
+			return
+		case input.ActionMoveNorth:
+			l.scrollBy(e, 1)
+		case input.ActionMoveSouth:
+			l.scrollBy(e, -1)
+		}
+	}
+}
+
+// scrollBy moves back through the log by delta messages, or forward if it's negative.
+func (l *logScene) scrollBy(e *Engine, delta int) {
+	l.scroll = max(0, min(l.scroll+delta, e.Log.Len()-e.logViewLines()))
+}
+
+func (l *logScene) Render(e *Engine) {
+	lines := e.logViewLines()
+	end := e.Log.Len() - l.scroll
+	start := max(0, end-lines)
+
+	header := &ui.Row{Children: []ui.Widget{
+		&ui.Label{Text: fmt.Sprintf("%d messages    [%s/%s] Scroll    [%s] Close",
+			e.Log.Len(), e.Bindings.Key(input.ActionMoveNorth), e.Bindings.Key(input.ActionMoveSouth),
+			e.Bindings.Key(input.ActionMessageLog)), Color: core.Gray},
+		&ui.Fill{},
+	}}
+	if start > 0 {
+		header.Children = append(header.Children, &ui.Label{Text: "[ MORE ]", Color: core.DarkGray})
+	}
+
+	messages := &ui.Column{}
+	for i := start; i < end; i++ {
+		m := e.Log.At(i)
+		messages.Children = append(messages.Children, &ui.Label{Text: m.String(), Color: m.Severity.Color()})
+	}
+
+	panel := &ui.Panel{
+		Title:      "MESSAGE LOG",
+		Color:      core.Cyan,
+		Background: core.Black,
+		Padding:    1,
+		Child:      &ui.Column{Gap: 1, Children: []ui.Widget{header, messages}},
+	}
+	view, _ := e.layout()
+	panel.Draw(e.Display, view)
+}
+
+// inventoryScene lists the kit the player is carrying.
+type inventoryScene struct {
+	items []string
+}
+
+func newInventoryScene(e *Engine) *inventoryScene {
+	inv := &inventoryScene{}
+	if player, ok := systems.FindPlayer(e.EcsWorld); ok && (e.EcsWorld.Masks[player]&components.MaskLight) != 0 {
+		inv.items = append(inv.items, fmt.Sprintf("Hand torch, range %d", e.EcsWorld.Lights[player].Radius))
+	}
+	return inv
+}
+
+func (inv *inventoryScene) Overlay() bool { return true }
+
+func (inv *inventoryScene) Update(e *Engine, events []core.InputEvent, actions []input.Action) {
+	for _, action := range actions {
+		switch action {
+		case input.ActionInventory, input.ActionPause, input.ActionInteract:
+			e.Pop()
+			return
+		}
+	}
+}
+
+func (inv *inventoryScene) Render(e *Engine) {
+	items := &ui.Column{}
+	for _, item := range inv.items {
+		items.Children = append(items.Children, &ui.Label{Text: "- " + item, Color: core.White})
+	}
+	items.Children = append(items.Children, &ui.Label{Text: "Nothing else", Color: core.DarkGray})
+
+	panel := &ui.Panel{
+		Title:      "INVENTORY",
+		Color:      core.Yellow,
+		Background: core.Black,
+		Padding:    2,
+		Child: &ui.Column{Gap: 1, Children: []ui.Widget{
+			items,
+			&ui.Label{Text: fmt.Sprintf("[%s] Close", e.Bindings.Key(input.ActionInventory)), Color: core.DarkGray},
+		}},
+	}
+	view, _ := e.layout()
+	panel.Draw(e.Display, view.Center(panel.Size()))
+}
+
+// terminalScene is a facility terminal the player has logged in to.
+type terminalScene struct {
+	menu     *menu
+	terminal ecs.Entity
+}
+
+// Terminal menu entries
+const (
+	terminalSave = iota
+	terminalLogOff
+)
+
+func newTerminalScene(e *Engine, terminal ecs.Entity) *terminalScene {
+	t := &terminalScene{terminal: terminal}
+	t.menu = newMenu(func(item int) {
+		switch item {
+		case terminalSave:
+			e.saveCheckpoint(t.terminal) // The game is frozen under the terminal, so it files the messages itself
+		case terminalLogOff:
+			t.logOff(e)
+		}
+	})
+	return t
+}
+
+// logOff hands the player back to the game.
+func (t *terminalScene) logOff(e *Engine) {
+	e.EcsWorld.Terminals[t.terminal].InUse = false
+	e.Pop()
+}
+
+func (t *terminalScene) Overlay() bool { return true }
+
+func (t *terminalScene) Update(e *Engine, events []core.InputEvent, actions []input.Action) {
+	for _, action := range actions {
+		if action == input.ActionPause {
+			t.logOff(e)
+		} else {
+			t.menu.focus.HandleAction(action)
+		}
+		if e.Top() != t {
+			return
+		}
+	}
+}
+
+func (t *terminalScene) Render(e *Engine) {
+	save := "Save Checkpoint"
+	if e.EcsWorld.Terminals[t.terminal].HasSaved {
+		save = "Checkpoint Saved"
+	}
+	t.menu.list.Items = []string{terminalSave: save, terminalLogOff: "Log Off"}
+
+	pos := e.EcsWorld.Positions[t.terminal]
+	readout := &ui.Column{Children: []ui.Widget{
+		&ui.Label{Text: fmt.Sprintf("DECK %d/%d   SURVEYED %.0f%%", e.ActiveFloor+1, len(e.Floors), e.Map.Coverage()*100), Color: core.Green},
+		e.gridStatus(e.PowerGrid.GridAt(e.Map, pos.X, pos.Y)),
+	}}
+	if e.Map.Fire != nil {
+		if burning := e.Map.Fire.Burning(); burning > 0 {
+			readout.Children = append(readout.Children, &ui.Label{Text: fmt.Sprintf("FIRE ALERT: %d tiles burning", burning), Color: core.Red})
+		}
+	}
+
+	view, _ := e.layout()
+	t.menu.draw(e, view, "FACILITY TERMINAL", core.Green, []ui.Widget{readout}, []ui.Widget{e.menuHint()})
+}
+
+func (e *Engine) renderHUD() {
+	statusText := "HEALTHY"
+	status := components.PlayerStatusHealthy
+	autopilotEngaged := false
+	exploring := false
+	var interactPrompt string // Store the prompt text if near an interactable
+	playerGrid := world.NoGrid
+	var air world.Gas
+
+	// Find player state for HUD
+	targetMask := components.MaskPlayerControl | components.MaskPosition
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (e.EcsWorld.Masks[i] & targetMask) == targetMask {
+			control := e.EcsWorld.PlayerControls[i]
+			position := e.EcsWorld.Positions[i]
+
+			autopilotEngaged = control.Autopilot
+			exploring = control.Explore
+			status = control.Status
+			statusText = status.Title()
+			playerGrid = e.PowerGrid.GridAt(e.Map, position.X, position.Y)
+			air = e.Map.GasAt(position.X, position.Y)
+
+			// Check for adjacent interactables
+			interactMask := components.MaskPosition | components.MaskInteractable
+			for j := ecs.Entity(0); j < ecs.MaxEntities; j++ {
+				if (e.EcsWorld.Masks[j] & interactMask) == interactMask {
+					targetPos := e.EcsWorld.Positions[j]
+					dx := targetPos.X - position.X
+					dy := targetPos.Y - position.Y
+					if (dx*dx + dy*dy) <= 2 { // 1 tile away
+						key := e.Bindings.Key(input.ActionInteract)
+						interactPrompt = interactText(e.EcsWorld.Interactables[j], key, systems.IsPowered(e.EcsWorld, j))
+						break
+					}
+				}
+			}
+
+			break
+		}
+	}
+
+	view, hud := e.layout()
+
+	if interactPrompt != "" {
+		// Draw the prompt blinking above the HUD
+		if e.tickCount%30 < 15 {
+			prompt := &ui.Label{Text: interactPrompt, Color: core.Green, Align: ui.AlignCenter}
+			prompt.Draw(e.Display, view.Row(view.H-1))
+		}
+	}
+
+	// First line: who and where the player is
+	navCom := &ui.Label{Text: "[ NAV-COM: MANUAL OVERRIDE ]", Color: core.Gray}
+	if exploring {
+		navCom = &ui.Label{Text: "[ NAV-COM: SURVEY IN PROGRESS ]", Color: core.Red}
+	} else if autopilotEngaged {
+		navCom = &ui.Label{Text: "[ NAV-COM: AUTOPILOT ENGAGED ]", Color: core.Red}
+	}
+	status1 := &ui.Row{Gap: 2, Children: []ui.Widget{
+		&ui.Label{Text: fmt.Sprintf(" STATUS: %s ", statusText), Color: core.Cyan},
+		navCom,
+	}}
+	if len(e.Floors) > 1 {
+		status1.Children = append(status1.Children, &ui.Label{Text: fmt.Sprintf(" DECK: %d/%d ", e.ActiveFloor+1, len(e.Floors)), Color: core.Yellow})
+	}
+	status1.Children = append(status1.Children, e.gridStatus(playerGrid))
+	if e.Map.Fire != nil {
+		if burning := e.Map.Fire.Burning(); burning > 0 {
+			fireColor := core.Yellow
+			if e.tickCount%30 < 15 {
+				fireColor = core.Red
+			}
+			status1.Children = append(status1.Children, &ui.Label{Text: fmt.Sprintf(" FIRE: %d ", burning), Color: fireColor})
+		}
+	}
+	// %06d formats the integer to always be 6 digits (e.g., 000142)
+	status1.Children = append(status1.Children, &ui.Fill{}, &ui.Label{Text: fmt.Sprintf(" CYCLE: %06d ", e.tickCount), Color: core.White})
+
+	// Second line: the controls, squeezed by the readouts on the right if the window is narrow
+	status2 := &ui.Row{Gap: 1, Children: []ui.Widget{
+		&ui.Fill{Child: &ui.Label{Text: e.Bindings.Hint(), Color: core.Gray}},
+	}}
+	status2.Children = append(status2.Children,
+		&ui.Label{Text: "MAPPED", Color: core.Green},
+		&ui.ProgressBar{Value: float32(e.Map.Coverage()), Max: 1, Width: hudBarWidth, Color: core.Green, EmptyColor: core.DarkGray},
+	)
+	if e.Map.Air != nil {
+		airColor := core.Cyan
+		if status != components.PlayerStatusHealthy {
+			airColor = core.Red
+		}
+		status2.Children = append(status2.Children,
+			&ui.Label{Text: " O2", Color: airColor},
+			&ui.ProgressBar{Value: air.O2, Max: 1, Width: hudBarWidth, Color: airColor, EmptyColor: core.DarkGray},
+			&ui.Label{Text: "TOX", Color: airColor},
+			&ui.ProgressBar{Value: air.Toxins, Max: 1, Width: hudBarWidth, Color: core.Green, EmptyColor: core.DarkGray},
+		)
+	}
+
+	// The latest messages, with the key for the rest of them
+	messages := &ui.Column{}
+	for _, m := range e.Log.Tail(hudLogLines) {
+		messages.Children = append(messages.Children, &ui.Label{Text: m.String(), Color: m.Severity.Color()})
+	}
+	logLines := &ui.Row{Children: []ui.Widget{
+		&ui.Fill{Child: messages},
+		&ui.Label{Text: fmt.Sprintf("[%s] Message Log ", e.Bindings.Key(input.ActionMessageLog)), Color: core.DarkGray},
+	}}
+
+	divider := &ui.Rule{Color: core.Gray}
+	divider.Draw(e.Display, hud.Row(0))
+	rows := &ui.Column{Children: []ui.Widget{status1, status2, logLines}}
+	rows.Draw(e.Display, ui.Rect{X: hud.X + 1, Y: hud.Y + 1, W: hud.W - 2, H: hud.H - 1})
+}
+
+// gridStatus shows the load on the grid the player is standing on.
+func (e *Engine) gridStatus(gridID int) *ui.Label {
+	if gridID == world.NoGrid || gridID >= len(e.PowerGrid.Grids) {
+		return &ui.Label{Text: " GRID: NONE ", Color: core.Gray}
+	}
+
+	stats := e.PowerGrid.Grids[gridID]
+	switch {
+	case stats.Supply <= 0:
+		return &ui.Label{Text: " GRID: DEAD ", Color: core.Red}
+	case stats.BrownedOut():
+		return &ui.Label{Text: fmt.Sprintf(" GRID: %.0f/%.0f BROWN-OUT ", stats.Demand, stats.Supply), Color: core.Yellow}
+	default:
+		return &ui.Label{Text: fmt.Sprintf(" GRID: %.0f/%.0f ", stats.Demand, stats.Supply), Color: core.Green}
+	}
+}
+
+// interactText is the prompt for standing next to an interactable: the key to press and what it
+// does, or its status once there's nothing left to do.
+func interactText(interact components.Interactable, key string, powered bool) string {
+	var text string
+	switch {
+	case interact.Status != "":
+		text = fmt.Sprintf("[ %s ]", interact.Status)
+	case interact.Prompt != "":
+		text = fmt.Sprintf("[%s] %s", key, interact.Prompt)
+	default:
+		return ""
+	}
+
+	if !powered {
+		text += " (NO POWER)"
+	}
+	return text
+}
@@ -0,0 +1,31 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/components"
+)
+
+func TestInteractText(t *testing.T) {
+	tests := []struct {
+		name     string
+		interact components.Interactable
+		key      string
+		powered  bool
+		want     string
+	}{
+		{"prompt", components.Interactable{Prompt: "Open Door"}, "E", true, "[E] Open Door"},
+		{"rebound key", components.Interactable{Prompt: "Open Door"}, "F", true, "[F] Open Door"},
+		{"no power", components.Interactable{Prompt: "Start Pump"}, "E", false, "[E] Start Pump (NO POWER)"},
+		{"status", components.Interactable{Status: "CHECKPOINT SAVED"}, "E", true, "[ CHECKPOINT SAVED ]"},
+		{"nothing to say", components.Interactable{}, "E", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := interactText(tt.interact, tt.key, tt.powered); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
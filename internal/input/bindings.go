@@ -0,0 +1,299 @@
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+)
+
+/*
+	A bindings file maps actions to the keys and gamepad buttons that trigger them:
+
+	; Lines starting with ';' are comments.
+	move_north = W Up K Kp8 PadUp       <action> = <key> [key...]
+	toggle_noise = F3
+
+	Every action the file names has its keys replaced, the rest keep their defaults. A key the
+	file binds is taken off whatever default action had it, but binding it twice in the file is
+	an error. Key names are the ones in keys.go and are matched ignoring case.
+*/
+
+// Action is something the player asks for, whatever key they pressed to ask for it.
+type Action uint8
+
+const (
+	ActionNone Action = iota
+	ActionMoveNorth
+	ActionMoveSouth
+	ActionMoveWest
+	ActionMoveEast
+	ActionMoveNorthWest
+	ActionMoveNorthEast
+	ActionMoveSouthWest
+	ActionMoveSouthEast
+	ActionInteract
+	ActionToggleAutopilot
+	ActionToggleSurvey
+	ActionToggleNoise
+	ActionMessageLog
+	ActionInventory
+	ActionFocusNext
+	ActionPause
+	ActionQuit
+	actionCount
+)
+
+var actionNames = [actionCount]string{
+	ActionNone:            "none",
+	ActionMoveNorth:       "move_north",
+	ActionMoveSouth:       "move_south",
+	ActionMoveWest:        "move_west",
+	ActionMoveEast:        "move_east",
+	ActionMoveNorthWest:   "move_north_west",
+	ActionMoveNorthEast:   "move_north_east",
+	ActionMoveSouthWest:   "move_south_west",
+	ActionMoveSouthEast:   "move_south_east",
+	ActionInteract:        "interact",
+	ActionToggleAutopilot: "toggle_autopilot",
+	ActionToggleSurvey:    "toggle_survey",
+	ActionToggleNoise:     "toggle_noise",
+	ActionMessageLog:      "message_log",
+	ActionInventory:       "inventory",
+	ActionFocusNext:       "focus_next",
+	ActionPause:           "pause",
+	ActionQuit:            "quit",
+}
+
+// String is the action's name in a bindings file.
+func (a Action) String() string {
+	if a >= actionCount {
+		return "unknown"
+	}
+	return actionNames[a]
+}
+
+// Repeats reports whether holding the key down keeps triggering the action. Only moves do,
+// so holding [P] doesn't flick the autopilot on and off.
+func (a Action) Repeats() bool {
+	return a >= ActionMoveNorth && a <= ActionMoveSouthEast
+}
+
+// Step is the grid direction a move action walks in, (0, 0) for anything else.
+func (a Action) Step() (dx, dy int) {
+	switch a {
+	case ActionMoveNorth:
+		return 0, -1
+	case ActionMoveSouth:
+		return 0, 1
+	case ActionMoveWest:
+		return -1, 0
+	case ActionMoveEast:
+		return 1, 0
+	case ActionMoveNorthWest:
+		return -1, -1
+	case ActionMoveNorthEast:
+		return 1, -1
+	case ActionMoveSouthWest:
+		return -1, 1
+	case ActionMoveSouthEast:
+		return 1, 1
+	default:
+		return 0, 0
+	}
+}
+
+func parseAction(name string) (Action, bool) {
+	for a := ActionMoveNorth; a < actionCount; a++ {
+		if actionNames[a] == strings.ToLower(name) {
+			return a, true
+		}
+	}
+	return ActionNone, false
+}
+
+// defaultBindings cover W/A/S/D, the arrow keys, vi-keys, the numpad and a gamepad.
+var defaultBindings = [actionCount][]string{
+	ActionMoveNorth:       {"W", "Up", "K", "Kp8", "PadUp"},
+	ActionMoveSouth:       {"S", "Down", "J", "Kp2", "PadDown"},
+	ActionMoveWest:        {"A", "Left", "H", "Kp4", "PadLeft"},
+	ActionMoveEast:        {"D", "Right", "L", "Kp6", "PadRight"},
+	ActionMoveNorthWest:   {"Kp7", "Y"},
+	ActionMoveNorthEast:   {"Kp9", "U"},
+	ActionMoveSouthWest:   {"Kp1", "B"},
+	ActionMoveSouthEast:   {"Kp3", "N"},
+	ActionInteract:        {"E", "Enter", "PadA"},
+	ActionToggleAutopilot: {"P", "PadY"},
+	ActionToggleSurvey:    {"X", "PadX"},
+	ActionToggleNoise:     {"F3"},
+	ActionMessageLog:      {"M", "PadSelect"},
+	ActionInventory:       {"I", "PadLB"},
+	ActionFocusNext:       {"Tab", "PadRB"},
+	ActionPause:           {"Esc", "PadStart"},
+	ActionQuit:            {"Q"},
+}
+
+// Bindings maps keys to actions.
+type Bindings struct {
+	keys    [actionCount][]rune // In the order they were listed, the first one is shown in hints
+	actions map[rune]Action
+}
+
+// Default returns the built-in bindings.
+func Default() *Bindings {
+	b := &Bindings{}
+	for a, names := range defaultBindings {
+		for _, name := range names {
+			code, ok := KeyCode(name)
+			if !ok {
+				panic("input: unknown default key " + name)
+			}
+			b.keys[a] = append(b.keys[a], code)
+		}
+	}
+	b.index()
+	return b
+}
+
+func (b *Bindings) index() {
+	b.actions = make(map[rune]Action)
+	for a, codes := range b.keys {
+		for _, code := range codes {
+			b.actions[code] = Action(a)
+		}
+	}
+}
+
+// Load reads a bindings file from disk, on top of the defaults.
+func Load(path string) (*Bindings, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	b, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b, nil
+}
+
+// Parse reads the bindings file format, on top of the defaults.
+func Parse(r io.Reader) (*Bindings, error) {
+	var set [actionCount]bool
+	var keys [actionCount][]rune
+	boundOn := make(map[rune]int) // Line each key was bound on
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+
+		name, list, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected <action> = <key> [key...]", lineNumber)
+		}
+		action, ok := parseAction(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown action %q", lineNumber, strings.TrimSpace(name))
+		}
+		if set[action] {
+			return nil, fmt.Errorf("line %d: %s is already bound", lineNumber, action)
+		}
+		set[action] = true
+
+		for _, keyName := range strings.Fields(list) {
+			code, ok := KeyCode(keyName)
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown key %q", lineNumber, keyName)
+			}
+			if prev, taken := boundOn[code]; taken {
+				return nil, fmt.Errorf("line %d: %s is already bound on line %d", lineNumber, KeyName(code), prev)
+			}
+			boundOn[code] = lineNumber
+			keys[action] = append(keys[action], code)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	b := Default()
+	for a := range b.keys {
+		if set[a] {
+			b.keys[a] = keys[a]
+			continue
+		}
+		// Defaults give up the keys the file took for something else
+		kept := b.keys[a][:0]
+		for _, code := range b.keys[a] {
+			if _, taken := boundOn[code]; !taken {
+				kept = append(kept, code)
+			}
+		}
+		b.keys[a] = kept
+	}
+	b.index()
+	return b, nil
+}
+
+// Action returns what a key is bound to, ActionNone if nothing.
+func (b *Bindings) Action(key rune) Action {
+	return b.actions[key]
+}
+
+// Actions turns a frame's key events into the actions they're bound to, in the order they came in.
+// Held keys only count for actions that repeat.
+func (b *Bindings) Actions(events []core.InputEvent) []Action {
+	var actions []Action
+	for _, event := range events {
+		if event.Key == 0 {
+			continue
+		}
+		a := b.actions[event.Key]
+		if a == ActionNone || (event.Repeat && !a.Repeats()) {
+			continue
+		}
+		actions = append(actions, a)
+	}
+	return actions
+}
+
+// Key is the label of the first key bound to an action, like "ESC", or "" if it's unbound.
+func (b *Bindings) Key(a Action) string {
+	if a >= actionCount || len(b.keys[a]) == 0 {
+		return ""
+	}
+	return strings.ToUpper(KeyName(b.keys[a][0]))
+}
+
+// Hint is the HUD's control line, built from the first key bound to each action.
+// Unbound actions are left out of it.
+func (b *Bindings) Hint() string {
+	var parts []string
+	moves := []string{b.Key(ActionMoveNorth), b.Key(ActionMoveWest), b.Key(ActionMoveSouth), b.Key(ActionMoveEast)}
+	if !slices.Contains(moves, "") {
+		parts = append(parts, "["+strings.Join(moves, "/")+"] Move")
+	}
+	for _, h := range []struct {
+		action Action
+		label  string
+	}{
+		{ActionToggleAutopilot, "Toggle Autopilot"},
+		{ActionToggleSurvey, "Survey"},
+		{ActionPause, "Pause System"},
+		{ActionQuit, "Abort"},
+	} {
+		if key := b.Key(h.action); key != "" {
+			parts = append(parts, "["+key+"] "+h.label)
+		}
+	}
+	return strings.Join(parts, "   ")
+}
@@ -0,0 +1,134 @@
+package input
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+)
+
+func key(t *testing.T, name string) rune {
+	t.Helper()
+	code, ok := KeyCode(name)
+	if !ok {
+		t.Fatalf("Unknown key %q", name)
+	}
+	return code
+}
+
+func TestDefault(t *testing.T) {
+	b := Default()
+
+	tests := []struct {
+		key  string
+		want Action
+	}{
+		{"W", ActionMoveNorth},
+		{"Up", ActionMoveNorth},
+		{"k", ActionMoveNorth}, // vi-keys, names ignore case
+		{"Kp3", ActionMoveSouthEast},
+		{"PadLeft", ActionMoveWest},
+		{"PadA", ActionInteract},
+		{"Esc", ActionPause},
+		{"F3", ActionToggleNoise},
+		{"Z", ActionNone},
+	}
+	for _, tt := range tests {
+		if got := b.Action(key(t, tt.key)); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.key, tt.want, got)
+		}
+	}
+}
+
+func TestParse_Overlay(t *testing.T) {
+	b, err := Parse(strings.NewReader(`
+; Arrow keys only, and the noise map back on N
+move_north = Up
+toggle_noise = N
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := b.Action(key(t, "W")); got != ActionNone {
+		t.Errorf("W should have been replaced, still bound to %s", got)
+	}
+	if got := b.Action(key(t, "Up")); got != ActionMoveNorth {
+		t.Errorf("Up: expected move_north, got %s", got)
+	}
+	if got := b.Action(key(t, "N")); got != ActionToggleNoise {
+		t.Errorf("N: expected toggle_noise, got %s", got)
+	}
+	if got := b.Key(ActionMoveSouthEast); got != "KP3" {
+		t.Errorf("move_south_east should keep its other keys, first one is %q", got)
+	}
+	if got := b.Action(key(t, "S")); got != ActionMoveSouth {
+		t.Errorf("Actions the file leaves out should keep their defaults, S is bound to %s", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no equals", "move_north W", "line 1: expected"},
+		{"unknown action", "fly = W", `line 1: unknown action "fly"`},
+		{"unknown key", "quit = Hyper", `line 1: unknown key "Hyper"`},
+		{"key twice", "quit = Q\n\npause = q", "line 3: Q is already bound on line 1"},
+		{"action twice", "quit = Q\nquit = F10", "line 2: quit is already bound"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestActions(t *testing.T) {
+	b := Default()
+	events := []core.InputEvent{
+		{Key: key(t, "W"), Repeat: true},   // Held moves keep moving
+		{Key: key(t, "P"), Repeat: true},   // Held toggles don't flick back and forth
+		{Mouse: core.MouseHover, CellX: 3}, // Not a key
+		{Key: key(t, "Z")},                 // Not bound
+		{Key: core.GamepadKeyBase + 8},     // PadX
+		{Key: key(t, "E")},
+	}
+
+	want := []Action{ActionMoveNorth, ActionToggleSurvey, ActionInteract}
+	if got := b.Actions(events); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestHint(t *testing.T) {
+	want := "[W/A/S/D] Move   [P] Toggle Autopilot   [X] Survey   [ESC] Pause System   [Q] Abort"
+	if got := Default().Hint(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	b, err := Parse(strings.NewReader("move_north = K\nmove_south = J\nmove_west = H\nmove_east = L\nquit ="))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want = "[K/H/J/L] Move   [P] Toggle Autopilot   [X] Survey   [ESC] Pause System"
+	if got := b.Hint(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestLoad_ShippedBindings(t *testing.T) {
+	b, err := Load("../../assets/bindings.txt")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(b.keys, Default().keys) {
+		t.Errorf("assets/bindings.txt should spell out the defaults")
+	}
+}
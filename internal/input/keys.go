@@ -0,0 +1,71 @@
+package input
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+)
+
+// keyCodes names every key a binding can use. The codes are the ones the Display reports in
+// core.InputEvent.Key: raylib's keyboard codes, and gamepad buttons offset by core.GamepadKeyBase.
+var keyCodes = map[string]rune{
+	"Space": 32,
+	"Esc":   256,
+	"Enter": 257,
+	"Tab":   258,
+	"Right": 262,
+	"Left":  263,
+	"Down":  264,
+	"Up":    265,
+
+	"PadUp":     core.GamepadKeyBase + 1,
+	"PadRight":  core.GamepadKeyBase + 2,
+	"PadDown":   core.GamepadKeyBase + 3,
+	"PadLeft":   core.GamepadKeyBase + 4,
+	"PadY":      core.GamepadKeyBase + 5,
+	"PadB":      core.GamepadKeyBase + 6,
+	"PadA":      core.GamepadKeyBase + 7,
+	"PadX":      core.GamepadKeyBase + 8,
+	"PadLB":     core.GamepadKeyBase + 9,
+	"PadLT":     core.GamepadKeyBase + 10,
+	"PadRB":     core.GamepadKeyBase + 11,
+	"PadRT":     core.GamepadKeyBase + 12,
+	"PadSelect": core.GamepadKeyBase + 13,
+	"PadStart":  core.GamepadKeyBase + 15,
+}
+
+// keyLookup (by lowercased name) and keyNames (by code) are filled in from keyCodes.
+var (
+	keyLookup = map[string]rune{}
+	keyNames  = map[rune]string{}
+)
+
+func init() {
+	for c := 'A'; c <= 'Z'; c++ {
+		keyCodes[string(c)] = c
+	}
+	for c := '0'; c <= '9'; c++ {
+		keyCodes[string(c)] = c
+		keyCodes["Kp"+string(c)] = 320 + (c - '0')
+	}
+	for n := 1; n <= 12; n++ {
+		keyCodes["F"+strconv.Itoa(n)] = rune(289 + n)
+	}
+
+	for name, code := range keyCodes {
+		keyLookup[strings.ToLower(name)] = code
+		keyNames[code] = name
+	}
+}
+
+// KeyCode looks up a key by the name the bindings file uses for it, ignoring case.
+func KeyCode(name string) (rune, bool) {
+	code, ok := keyLookup[strings.ToLower(name)]
+	return code, ok
+}
+
+// KeyName is the bindings file name of a key code, "" if it has none.
+func KeyName(code rune) string {
+	return keyNames[code]
+}
@@ -0,0 +1,207 @@
+package msglog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+)
+
+/*
+	The message log is everything the facility has told the player, oldest first. Systems queue
+	messages on their ecs.World and the engine stamps them with the tick and files them here.
+	The same message posted again straight away bumps the count on the last line instead of
+	scrolling the rest away.
+
+	It saves as one message per line:
+
+	; Lines starting with ';' are comments.
+	142 good 1 Generator online         <tick> <severity> <count> <text>
+	300 warn 3 The door has no power
+*/
+
+const DefaultCapacity = 500 // Messages kept before the oldest are dropped
+
+// Severity decides how a message is coloured, and how urgent it is.
+type Severity uint8
+
+const (
+	SeverityInfo Severity = iota
+	SeverityGood
+	SeverityWarning
+	SeverityDanger
+)
+
+var severityNames = [...]string{"info", "good", "warn", "danger"}
+
+func (s Severity) Title() string {
+	switch s {
+	case SeverityInfo:
+		return "Info"
+	case SeverityGood:
+		return "Good"
+	case SeverityWarning:
+		return "Warning"
+	case SeverityDanger:
+		return "Danger"
+	default:
+		return "Unknown"
+	}
+}
+
+// Color is what the HUD draws a message of this severity in.
+func (s Severity) Color() core.Color {
+	switch s {
+	case SeverityGood:
+		return core.Green
+	case SeverityWarning:
+		return core.Yellow
+	case SeverityDanger:
+		return core.Red
+	default:
+		return core.White
+	}
+}
+
+// Message is one line of the log.
+type Message struct {
+	Tick     int
+	Severity Severity
+	Text     string
+	Count    int // Times it was posted in a row
+}
+
+// String formats the message the way the HUD shows it, like "[000142] Door opened (x3)".
+func (m Message) String() string {
+	if m.Count > 1 {
+		return fmt.Sprintf("[%06d] %s (x%d)", m.Tick, m.Text, m.Count)
+	}
+	return fmt.Sprintf("[%06d] %s", m.Tick, m.Text)
+}
+
+// Log keeps the most recent messages, up to its capacity.
+type Log struct {
+	messages []Message
+	capacity int
+}
+
+func New(capacity int) *Log {
+	return &Log{capacity: max(capacity, 1)}
+}
+
+// Post files a message at the given tick.
+func (l *Log) Post(tick int, severity Severity, text string) {
+	if n := len(l.messages); n > 0 {
+		last := &l.messages[n-1]
+		if last.Text == text && last.Severity == severity {
+			last.Tick = tick
+			last.Count++
+			return
+		}
+	}
+
+	l.push(Message{Tick: tick, Severity: severity, Text: text, Count: 1})
+}
+
+// push appends a message, dropping the oldest when the log is full.
+func (l *Log) push(m Message) {
+	if len(l.messages) == l.capacity {
+		copy(l.messages, l.messages[1:])
+		l.messages = l.messages[:l.capacity-1]
+	}
+	l.messages = append(l.messages, m)
+}
+
+// Len is the number of messages kept.
+func (l *Log) Len() int {
+	return len(l.messages)
+}
+
+// At returns the i-th message kept, 0 is the oldest.
+func (l *Log) At(i int) Message {
+	return l.messages[i]
+}
+
+// Tail returns the last n messages, oldest first. The slice is shared with the log, so it's only
+// good until the next Post.
+func (l *Log) Tail(n int) []Message {
+	return l.messages[max(len(l.messages)-n, 0):]
+}
+
+// Save writes the log in its save file format.
+func (l *Log) Save(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "; derelict-facility message log")
+	for _, m := range l.messages {
+		fmt.Fprintf(bw, "%d %s %d %s\n", m.Tick, severityNames[m.Severity], m.Count, m.Text)
+	}
+	return bw.Flush()
+}
+
+// Load reads a log saved to disk.
+func Load(path string, capacity int) (*Log, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	l, err := Parse(file, capacity)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Parse reads the save file format back into a log. Only the last capacity messages are kept.
+func Parse(r io.Reader, capacity int) (*Log, error) {
+	l := New(capacity)
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		m, err := parseMessage(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		l.push(m)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func parseMessage(line string) (Message, error) {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) != 4 {
+		return Message{}, fmt.Errorf("expected <tick> <severity> <count> <text>")
+	}
+
+	tick, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Message{}, fmt.Errorf("bad tick %q", fields[0])
+	}
+	severity := -1
+	for s, name := range severityNames {
+		if name == fields[1] {
+			severity = s
+		}
+	}
+	if severity < 0 {
+		return Message{}, fmt.Errorf("unknown severity %q", fields[1])
+	}
+	count, err := strconv.Atoi(fields[2])
+	if err != nil || count < 1 {
+		return Message{}, fmt.Errorf("bad count %q", fields[2])
+	}
+	return Message{Tick: tick, Severity: Severity(severity), Text: fields[3], Count: count}, nil
+}
@@ -0,0 +1,94 @@
+package msglog
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLog_Post(t *testing.T) {
+	l := New(3)
+	l.Post(1, SeverityInfo, "Door opened")
+	l.Post(2, SeverityInfo, "Door opened") // Repeats fold into the last line
+	l.Post(3, SeverityGood, "Generator online")
+	l.Post(4, SeverityInfo, "Door opened")
+	l.Post(5, SeverityDanger, "Oxygen low") // Pushes the oldest out
+
+	want := []Message{
+		{Tick: 3, Severity: SeverityGood, Text: "Generator online", Count: 1},
+		{Tick: 4, Severity: SeverityInfo, Text: "Door opened", Count: 1},
+		{Tick: 5, Severity: SeverityDanger, Text: "Oxygen low", Count: 1},
+	}
+	if got := l.Tail(10); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := l.Tail(1); len(got) != 1 || got[0].Text != "Oxygen low" {
+		t.Errorf("Expected only the newest message, got %v", got)
+	}
+}
+
+func TestMessage_String(t *testing.T) {
+	tests := []struct {
+		msg  Message
+		want string
+	}{
+		{Message{Tick: 142, Text: "Door opened", Count: 1}, "[000142] Door opened"},
+		{Message{Tick: 7, Text: "Door opened", Count: 3}, "[000007] Door opened (x3)"},
+	}
+	for _, tt := range tests {
+		if got := tt.msg.String(); got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, got)
+		}
+	}
+}
+
+func TestLog_SaveAndParse(t *testing.T) {
+	l := New(DefaultCapacity)
+	l.Post(10, SeverityGood, "Generator online")
+	l.Post(20, SeverityWarning, "The door has no power")
+	l.Post(21, SeverityWarning, "The door has no power")
+	l.Post(30, SeverityDanger, "Toxins in the air, get out")
+
+	var buf bytes.Buffer
+	if err := l.Save(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	loaded, err := Parse(&buf, DefaultCapacity)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Tail(l.Len()), l.Tail(l.Len())) {
+		t.Errorf("Expected %v back, got %v", l.Tail(l.Len()), loaded.Tail(loaded.Len()))
+	}
+
+	// A smaller log keeps the newest
+	small, err := Parse(strings.NewReader("1 info 1 a\n2 info 1 b\n3 info 1 c\n"), 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if small.Len() != 2 || small.At(0).Text != "b" {
+		t.Errorf("Expected b and c to be kept, got %v", small.Tail(2))
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"too short", "12 info", "line 1: expected"},
+		{"bad tick", "soon info 1 Hello", `line 1: bad tick "soon"`},
+		{"bad severity", "; header\n1 loud 1 Hello", `line 2: unknown severity "loud"`},
+		{"bad count", "1 info 0 Hello", `line 1: bad count "0"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input), DefaultCapacity)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
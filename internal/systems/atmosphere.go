@@ -0,0 +1,117 @@
+package systems
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/msglog"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+// Thresholds for the air the player is breathing, in the atmosphere's normalised units
+const (
+	toxicLevel      = 0.25 // Toxins at or above this make the player sick
+	minBreathableO2 = 0.6  // Less oxygen than this starts to hurt
+	minPressure     = 0.5  // So does a room that's close to vacuum
+
+	FatalExposure    = 1200 // Ticks of bad air (about 40 seconds at 33ms a tick) the player can take before collapsing
+	exposureRecovery = 2    // Exposure shaken off per tick in clean air
+)
+
+// ProcessAtmosphere runs the gas emitters and then moves the air one tick.
+// solids marks the tiles holding a Solid entity, so closed doors seal rooms off.
+func ProcessAtmosphere(w *ecs.World, gameMap *world.Map, solids []bool) {
+	if gameMap.Air == nil {
+		return
+	}
+
+	targetMask := components.MaskPosition | components.MaskGasEmitter
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & targetMask) != targetMask {
+			continue
+		}
+
+		emitter := w.GasEmitters[i]
+		if (w.Masks[i] & components.MaskPowerConsumer) != 0 {
+			supply := w.PowerConsumers[i].Supply
+			emitter.O2 *= supply
+			emitter.Toxins *= supply
+		}
+
+		pos := w.Positions[i]
+		gameMap.Air.Emit(gameMap, pos.X, pos.Y, emitter.O2, emitter.Toxins)
+	}
+
+	gameMap.Air.Step(gameMap, solids)
+}
+
+// ProcessSurvival sets each player's status from the air on their tile. Standing in a fire hurts
+// even when the air is fine. Every tick spent hurt or sick adds to the player's exposure, which wears
+// off again in clean air; a player who soaks up FatalExposure is dead.
+func ProcessSurvival(w *ecs.World, gameMap *world.Map) {
+	if gameMap.Air == nil {
+		return
+	}
+
+	targetMask := components.MaskPlayerControl | components.MaskPosition
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & targetMask) != targetMask {
+			continue
+		}
+
+		control := &w.PlayerControls[i]
+		if control.Status == components.PlayerStatusDead {
+			continue
+		}
+
+		pos := w.Positions[i]
+		gas := gameMap.GasAt(pos.X, pos.Y)
+		status := BreathingStatus(gas)
+		burning := status == components.PlayerStatusHealthy && gameMap.FireAt(pos.X, pos.Y) > 0
+		if burning {
+			status = components.PlayerStatusHurt
+		}
+
+		if status == components.PlayerStatusHealthy {
+			control.Exposure = max(control.Exposure-exposureRecovery, 0)
+		} else {
+			control.Exposure++
+			switch control.Exposure {
+			case FatalExposure / 2:
+				w.Post(msglog.SeverityDanger, "Your vision is swimming")
+			case FatalExposure:
+				control.Status = components.PlayerStatusDead
+				w.Post(msglog.SeverityDanger, "You collapse")
+				continue
+			}
+		}
+
+		if status != control.Status {
+			switch {
+			case status == components.PlayerStatusSick:
+				w.Post(msglog.SeverityDanger, "Toxins in the air, get out")
+			case burning:
+				w.Post(msglog.SeverityDanger, "You're standing in the fire")
+			case status == components.PlayerStatusHurt && gas.Pressure < minPressure:
+				w.Post(msglog.SeverityDanger, "Pressure dropping")
+			case status == components.PlayerStatusHurt:
+				w.Post(msglog.SeverityDanger, "Oxygen low")
+			default:
+				w.Post(msglog.SeverityGood, "Breathing easy again")
+			}
+		}
+		control.Status = status
+	}
+}
+
+// BreathingStatus is what breathing the given air does to the player. Toxins win over
+// suffocation, a poisoned room is the more urgent thing to get out of.
+func BreathingStatus(gas world.Gas) components.PlayerStatus {
+	switch {
+	case gas.Toxins >= toxicLevel:
+		return components.PlayerStatusSick
+	case gas.O2 < minBreathableO2 || gas.Pressure < minPressure:
+		return components.PlayerStatusHurt
+	default:
+		return components.PlayerStatusHealthy
+	}
+}
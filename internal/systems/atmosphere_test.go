@@ -0,0 +1,85 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+// newSurvivalTest builds a small open room full of clean air with the player standing at (2,1).
+func newSurvivalTest() (*ecs.World, *world.Map, ecs.Entity) {
+	m := world.NewMap(5, 3)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			m.SetTile(x, y, world.Tile{Type: world.TileTypeFloor, Walkable: true})
+		}
+	}
+	m.EnableAtmosphere()
+
+	w := ecs.NewWorld()
+	player := w.CreateEntity()
+	w.AddPosition(player, components.Position{X: 2, Y: 1})
+	w.AddPlayerControl(player, components.PlayerControl{})
+	return w, m, player
+}
+
+func TestProcessSurvival_Exposure(t *testing.T) {
+	tests := []struct {
+		name         string
+		toxins       float32 // Toxins on the player's tile
+		exposure     int     // Exposure the player starts with
+		ticks        int
+		wantExposure int
+		wantStatus   components.PlayerStatus
+	}{
+		{"clean air", 0, 0, 10, 0, components.PlayerStatusHealthy},
+		{"rises in bad air", 1, 0, 10, 10, components.PlayerStatusSick},
+		{"recovers in clean air", 0, 10, 3, 10 - 3*exposureRecovery, components.PlayerStatusHealthy},
+		{"never recovers below zero", 0, 1, 3, 0, components.PlayerStatusHealthy},
+		{"survives just short of fatal", 1, 0, FatalExposure - 1, FatalExposure - 1, components.PlayerStatusSick},
+		{"collapses at fatal", 1, 0, FatalExposure, FatalExposure, components.PlayerStatusDead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, m, player := newSurvivalTest()
+			m.Air.Toxins[m.GetIndex(2, 1)] = tt.toxins
+			w.PlayerControls[player].Exposure = tt.exposure
+
+			for i := 0; i < tt.ticks; i++ {
+				ProcessSurvival(w, m)
+			}
+
+			control := w.PlayerControls[player]
+			if control.Exposure != tt.wantExposure {
+				t.Errorf("Expected exposure %d, got %d", tt.wantExposure, control.Exposure)
+			}
+			if control.Status != tt.wantStatus {
+				t.Errorf("Expected status %v, got %v", tt.wantStatus, control.Status)
+			}
+		})
+	}
+}
+
+func TestProcessSurvival_DeadPlayerStaysDead(t *testing.T) {
+	w, m, player := newSurvivalTest()
+	m.Air.Toxins[m.GetIndex(2, 1)] = 1
+	for i := 0; i < FatalExposure; i++ {
+		ProcessSurvival(w, m)
+	}
+	if w.PlayerControls[player].Status != components.PlayerStatusDead {
+		t.Fatalf("Expected the player to collapse after %d ticks of bad air", FatalExposure)
+	}
+
+	// Clean air doesn't bring them back, nor does it wear the exposure off
+	m.Air.Toxins[m.GetIndex(2, 1)] = 0
+	for i := 0; i < 10; i++ {
+		ProcessSurvival(w, m)
+	}
+	control := w.PlayerControls[player]
+	if control.Status != components.PlayerStatusDead || control.Exposure != FatalExposure {
+		t.Errorf("Expected the player to stay dead at exposure %d, got %v at %d", FatalExposure, control.Status, control.Exposure)
+	}
+}
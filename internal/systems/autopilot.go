@@ -9,37 +9,109 @@ import (
 	"github.com/vikash-paf/derelict-facility/internal/world"
 )
 
+// Navigation is everything the autopilot plans routes with on one floor. Only Pathfinder is
+// required; without the rest every route is planned tile by tile, inline, and nothing is explored.
+type Navigation struct {
+	Pathfinder *world.Pathfinder
+	Hierarchy  *world.Hierarchy     // Long routes are planned over its portals and walked one leg at a time
+	Cache      *world.PathCache     // Routes shared between agents and ticks
+	Service    *world.PathService   // Tile by tile routes are worked out off the tick, and arrive through DeliverPaths
+	Snapshots  *world.SnapshotCache // What the service plans over, shared until the map changes
+	Frontier   *world.DijkstraMap   // Distance to the nearest unexplored edge, for exploring
+	Blocked    []bool               // Scratch for the tiles exploring can't get through, one per tile
+}
+
 // ProcessAutopilot handles the AI pathing logic for any Entity with PlayerControl.
-func ProcessAutopilot(w *ecs.World, gameMap *world.Map, pf *world.Pathfinder) {
+// Exploring entities head for the nearest unexplored edge until there's none left, the others
+// wander between random rooms. Closed doors on the way get opened.
+func ProcessAutopilot(w *ecs.World, gameMap *world.Map, nav Navigation) {
+	pf, cache, service := nav.Pathfinder, nav.Cache, nav.Service
 	targetMask := components.MaskPlayerControl | components.MaskPosition
 
+	// Is the map tile walkable, and no solid entity blocking the way?
+	isWalkable := func(x, y int) bool {
+		return gameMap.IsWalkable(x, y) && !IsSolidAt(w, x, y)
+	}
+
 	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
 		if (w.Masks[i] & targetMask) == targetMask {
 			ctrl := &w.PlayerControls[i]
 			pos := &w.Positions[i]
 
-			if !ctrl.Autopilot {
-				continue // AI is toggled off
+			if (!ctrl.Autopilot && !ctrl.Travelling) || ctrl.PathPending {
+				continue // AI is toggled off, or waiting on the path service
 			}
 
-			// 1. If we don't have a path, find a new destination!
+			// 1. If we don't have a path, refine the next leg of the route or find a new destination!
 			if len(ctrl.CurrentPath) == 0 {
+				if !ctrl.Autopilot {
+					ctrl.Travelling = false // Arrived at the clicked tile, or got blocked on the way
+					continue
+				}
+				start := entity.Point{X: pos.X, Y: pos.Y}
+
+				if ctrl.Explore {
+					path, ok := planExploration(w, gameMap, nav, start)
+					if !ok {
+						ctrl.Autopilot, ctrl.Explore = false, false // Nothing left we can get to
+					}
+					ctrl.CurrentPath = path
+					continue
+				}
+
+				if len(ctrl.Waypoints) > 0 {
+					next := ctrl.Waypoints[0]
+					ctrl.Waypoints = ctrl.Waypoints[1:]
+
+					path, ok := refineLeg(gameMap, pf, nav.Hierarchy, start, next, isWalkable)
+					if !ok {
+						ctrl.Waypoints = nil // The route went stale, plan a new one
+					}
+					ctrl.CurrentPath = path
+					continue
+				}
+
+				if len(gameMap.Rooms) == 0 {
+					continue // Nowhere to go
+				}
+
 				// Pick a random room
 				targetRoom := gameMap.Rooms[rand.Intn(len(gameMap.Rooms))]
 				targetX, targetY := targetRoom.Center()
-
-				start := entity.Point{X: pos.X, Y: pos.Y}
 				target := entity.Point{X: targetX, Y: targetY}
 
-				// Calculate the path
-				path := pf.FindPath(gameMap, start, target, func(x, y int) bool {
-					// 1. Is the map tile walkable?
-					if !gameMap.IsWalkable(x, y) {
-						return false
+				// The portals only link straight steps, diagonal walkers plan tile by tile
+				if nav.Hierarchy != nil && !ctrl.Diagonal {
+					waypoints, ok := cache.Get(gameMap, start, target, world.PathWaypoints)
+					if !ok {
+						waypoints = nav.Hierarchy.FindWaypoints(start, target)
+						cache.Put(gameMap, start, target, world.PathWaypoints, waypoints)
 					}
-					// 2. Is there a solid entity blocking the way?
-					return !IsSolidAt(w, x, y)
-				})
+					if len(waypoints) > 1 {
+						ctrl.Waypoints = waypoints[1:]
+					}
+					continue
+				}
+
+				// Calculate the path, steering around fires and flooding. The cache doesn't
+				// know where they are, so a cached path through one is planned again.
+				path, ok := cache.Get(gameMap, start, target, world.TilePath(ctrl.Diagonal))
+				if (!ok || crossesHazard(gameMap, path)) && service != nil {
+					service.Submit(world.PathRequest{
+						Owner:    int(i),
+						Start:    start,
+						Goal:     target,
+						Diagonal: ctrl.Diagonal,
+						Snapshot: nav.Snapshots.Get(gameMap, func() *world.PathSnapshot { return pathSnapshot(w, gameMap) }),
+					})
+					ctrl.PathPending = true
+					continue
+				}
+				if !ok || crossesHazard(gameMap, path) {
+					path = pf.FindPathWithOptions(gameMap, start, target, isWalkable,
+						world.PathOptions{Diagonal: ctrl.Diagonal, Cost: gameMap.HazardCost})
+					cache.Put(gameMap, start, target, world.TilePath(ctrl.Diagonal), path)
+				}
 
 				if len(path) > 1 {
 					ctrl.CurrentPath = path[1:]
@@ -49,15 +121,23 @@ func ProcessAutopilot(w *ecs.World, gameMap *world.Map, pf *world.Pathfinder) {
 				continue
 			}
 
-			// 2. Take the next step in the path
+			// 2. Take the next step in the path, once we've waded through the last one
+			if ctrl.MoveCooldown > 0 {
+				continue
+			}
 			nextStep := ctrl.CurrentPath[0]
 
 			if gameMap.IsWalkable(nextStep.X, nextStep.Y) && !IsSolidAt(w, nextStep.X, nextStep.Y) {
 				pos.X = nextStep.X
 				pos.Y = nextStep.Y
+				ctrl.MoveCooldown = WadeDelay(gameMap.WaterAt(pos.X, pos.Y))
+				footstep(w, gameMap, pos.X, pos.Y)
+			} else if OpenDoorAt(w, gameMap, nextStep.X, nextStep.Y) {
+				continue // Walk through next time, now that it's open
 			} else {
 				// Path is blocked! Clear it so we recalculate next tick.
 				ctrl.CurrentPath = nil
+				ctrl.Waypoints = nil
 				continue
 			}
 
@@ -66,3 +146,63 @@ func ProcessAutopilot(w *ecs.World, gameMap *world.Map, pf *world.Pathfinder) {
 		}
 	}
 }
+
+// refineLeg turns one leg of a portal route into the steps to take, leaving out the tile we stand on.
+// The cached legs don't know about fires or flooding, so a leg through either is planned again
+// around them. Returns false if the leg can't be walked any more.
+func refineLeg(gameMap *world.Map, pf *world.Pathfinder, nav *world.Hierarchy, start, next entity.Point, isWalkable func(x, y int) bool) ([]entity.Point, bool) {
+	leg := nav.Refine(start, next)
+	if leg == nil {
+		return nil, false
+	}
+
+	if crossesHazard(gameMap, leg) {
+		leg = pf.FindPathWithOptions(gameMap, start, next, isWalkable, world.PathOptions{Cost: gameMap.HazardCost})
+		if leg == nil {
+			return nil, false
+		}
+	}
+	return leg[1:], true
+}
+
+// crossesHazard reports whether a path walks through fire or standing water.
+func crossesHazard(gameMap *world.Map, path []entity.Point) bool {
+	for _, p := range path {
+		if gameMap.FireAt(p.X, p.Y) > 0 || gameMap.WaterAt(p.X, p.Y) >= world.DryDepth {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliverPaths hands the path service's results to the entities that asked for them, in the order
+// they asked. Results for the map as it still is go into cache as well.
+func DeliverPaths(w *ecs.World, gameMap *world.Map, cache *world.PathCache, results []world.PathResult) {
+	for _, res := range results {
+		e := ecs.Entity(res.Owner)
+		if (w.Masks[e]&components.MaskPlayerControl) == 0 || !w.PlayerControls[e].PathPending {
+			continue // Gave up waiting, or no longer there
+		}
+
+		ctrl := &w.PlayerControls[e]
+		ctrl.PathPending = false
+		if res.Revision == gameMap.Revision {
+			cache.Put(gameMap, res.Start, res.Goal, world.TilePath(ctrl.Diagonal), res.Path)
+		}
+		if len(res.Path) > 1 {
+			ctrl.CurrentPath = res.Path[1:]
+		}
+	}
+}
+
+// pathSnapshot freezes what the autopilot can walk through, and what the hazards cost, for the path service.
+func pathSnapshot(w *ecs.World, gameMap *world.Map) *world.PathSnapshot {
+	snapshot := world.NewPathSnapshot(gameMap, gameMap.HazardCost)
+	solidMask := components.MaskPosition | components.MaskSolid
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & solidMask) == solidMask {
+			snapshot.Block(w.Positions[i].X, w.Positions[i].Y)
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,91 @@
+package systems
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+// DescribeAt is the hover tooltip for (x, y): the tile, the air if it's bad, and whatever
+// can be seen standing there.
+func DescribeAt(w *ecs.World, gameMap *world.Map, x, y int) string {
+	parts := []string{gameMap.DescribeTile(x, y)}
+
+	tile := gameMap.GetTile(x, y)
+	if tile == nil || !tile.Visible {
+		return parts[0] // Only what's in sight right now is worth more than a name
+	}
+
+	if gameMap.Air != nil && tile.Walkable {
+		switch BreathingStatus(gameMap.GasAt(x, y)) {
+		case components.PlayerStatusSick:
+			parts = append(parts, "toxic air")
+		case components.PlayerStatusHurt:
+			parts = append(parts, "thin air")
+		}
+	}
+
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i]&components.MaskPosition) == 0 || w.Positions[i].X != x || w.Positions[i].Y != y {
+			continue
+		}
+		if name := entityName(w, i); name != "" {
+			parts = append(parts, name)
+		}
+	}
+
+	return strings.Join(parts, " | ")
+}
+
+// entityName is what an entity looks like to the player, "" for ones that aren't worth mentioning.
+func entityName(w *ecs.World, i ecs.Entity) string {
+	mask := w.Masks[i]
+	name := ""
+
+	switch {
+	case mask&components.MaskPlayerControl != 0:
+		return "You"
+	case mask&components.MaskPowerGenerator != 0:
+		name = "Generator (off)"
+		if w.PowerGenerators[i].IsActive {
+			name = "Generator (running)"
+		}
+	case mask&components.MaskDoor != 0:
+		name = "Door (closed)"
+		if w.Doors[i].IsOpen {
+			name = "Door (open)"
+		}
+	case mask&components.MaskTerminal != 0:
+		name = "Terminal"
+	case mask&components.MaskBreaker != 0:
+		name = "Breaker (open)"
+		if w.Breakers[i].Closed {
+			name = "Breaker (closed)"
+		}
+	case mask&components.MaskFloorLink != 0:
+		name = fmt.Sprintf("Way to deck %d", w.FloorLinks[i].TargetFloor+1)
+	case mask&components.MaskSuppressor != 0:
+		name = fmt.Sprintf("Fire suppressor (%d charges)", w.Suppressors[i].Charges)
+	case mask&components.MaskPump != 0:
+		name = "Bilge pump (stopped)"
+		if w.Pumps[i].Running {
+			name = "Bilge pump (running)"
+		}
+	case mask&components.MaskWaterSource != 0:
+		name = "Burst main"
+	case mask&components.MaskFlammable != 0:
+		name = "Fuel drum"
+	case mask&components.MaskLight != 0:
+		name = "Lamp"
+	default:
+		return ""
+	}
+
+	if !IsPowered(w, i) {
+		name += ", no power"
+	}
+	return name
+}
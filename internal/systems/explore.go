@@ -0,0 +1,61 @@
+package systems
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+// planExploration routes from start to the nearest frontier, the edge of what has been explored.
+// Closed doors with power count as open, the autopilot opens them on its way through.
+// The path leaves out start. Returns false once no frontier can be reached.
+func planExploration(w *ecs.World, gameMap *world.Map, nav Navigation, start entity.Point) ([]entity.Point, bool) {
+	blocked := nav.Blocked
+	clear(blocked)
+	solidMask := components.MaskPosition | components.MaskSolid
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & solidMask) != solidMask {
+			continue
+		}
+		if (w.Masks[i]&components.MaskDoor) != 0 && !w.Doors[i].IsOpen && IsPowered(w, i) {
+			continue // We can open it
+		}
+		if pos := w.Positions[i]; gameMap.GetTile(pos.X, pos.Y) != nil {
+			blocked[gameMap.GetIndex(pos.X, pos.Y)] = true
+		}
+	}
+	passable := func(x, y int) bool {
+		return gameMap.IsWalkable(x, y) && !blocked[gameMap.GetIndex(x, y)]
+	}
+
+	// Frontiers behind something we can't get through are no use
+	frontiers := gameMap.Frontiers(nil)
+	reachable := frontiers[:0]
+	for _, p := range frontiers {
+		if passable(p.X, p.Y) {
+			reachable = append(reachable, p)
+		}
+	}
+	if len(reachable) == 0 {
+		return nil, false
+	}
+
+	// Steer around fires and flooding the way the wandering autopilot does
+	field := nav.Frontier
+	field.Compute(reachable, passable, gameMap.HazardCost)
+	if field.At(start.X, start.Y) == world.Unreachable {
+		return nil, false
+	}
+
+	var path []entity.Point
+	for p := start; field.At(p.X, p.Y) > 0; {
+		next, ok := field.Descend(p.X, p.Y)
+		if !ok {
+			break
+		}
+		path = append(path, next)
+		p = next
+	}
+	return path, true
+}
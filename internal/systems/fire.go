@@ -0,0 +1,99 @@
+package systems
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/core"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/msglog"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+const (
+	sparkChance     = 0.002 // Chance per fire step that a browned-out device sparks a fire on its tile
+	flammableFeed   = 5     // Fuel a burning Flammable hands to its tile each fire step
+	fireLightRadius = 3
+	deviceReach     = 3 // How far a room-wide device reaches when it stands outside of a room
+)
+
+// fireColor is the orange glow a fire casts around it.
+var fireColor = core.Color{R: 255, G: 140, B: 40, A: 255}
+
+// ProcessFire burns the map one fire step. Browned-out devices may spark, burning Flammables feed
+// their tile, and then the fire spreads. solids marks the tiles holding a Solid entity, so closed
+// doors hold the flames back.
+func ProcessFire(w *ecs.World, gameMap *world.Map, solids []bool) {
+	fire := gameMap.Fire
+	if fire == nil {
+		return
+	}
+
+	burning := fire.Burning()
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & components.MaskPosition) == 0 {
+			continue
+		}
+		pos := w.Positions[i]
+
+		// Starved wiring arcs, a device getting some power but not enough can start a fire
+		if (w.Masks[i] & components.MaskPowerConsumer) != 0 {
+			supply := w.PowerConsumers[i].Supply
+			if supply > 0 && supply < 1 && fire.Roll() < sparkChance {
+				fire.Ignite(gameMap, pos.X, pos.Y)
+			}
+		}
+
+		if (w.Masks[i]&components.MaskFlammable) != 0 && gameMap.FireAt(pos.X, pos.Y) > 0 {
+			flammable := &w.Flammables[i]
+			feed := min(flammable.Fuel, flammableFeed)
+			flammable.Fuel -= feed
+			fire.AddFuel(gameMap, pos.X, pos.Y, feed)
+		}
+	}
+
+	fire.Step(gameMap, solids)
+
+	switch after := fire.Burning(); {
+	case burning == 0 && after > 0:
+		w.Post(msglog.SeverityDanger, "Fire on the deck")
+	case burning > 0 && after == 0:
+		w.Post(msglog.SeverityGood, "The last of the fire is out")
+	}
+}
+
+// Suppress discharges a suppressor, putting out every fire in its room, or around it when it
+// stands outside one. Returns how many burning tiles it put out, or -1 if it's out of charges.
+func Suppress(w *ecs.World, gameMap *world.Map, e ecs.Entity) int {
+	suppressor := &w.Suppressors[e]
+	if suppressor.Charges <= 0 {
+		return -1
+	}
+	suppressor.Charges--
+
+	if gameMap.Fire == nil {
+		return 0
+	}
+
+	pos := w.Positions[e]
+	return gameMap.Fire.ExtinguishRect(gameMap, gameMap.RoomArea(pos.X, pos.Y, deviceReach))
+}
+
+// addFireLight lights the map around every burning tile, brighter the hotter it burns.
+func addFireLight(gameMap *world.Map, tick int, blocksLight func(x, y int) bool) {
+	fire := gameMap.Fire
+	if fire == nil {
+		return
+	}
+
+	for i, intensity := range fire.Intensity {
+		if intensity == 0 {
+			continue
+		}
+		gameMap.AddLight(world.LightSource{
+			X:         i % gameMap.Width,
+			Y:         i / gameMap.Width,
+			Radius:    fireLightRadius,
+			Color:     fireColor,
+			Intensity: float32(intensity) / world.MaxFireIntensity * flickerFactor(0.4, tick, ecs.Entity(i)),
+		}, blocksLight)
+	}
+}
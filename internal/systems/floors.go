@@ -0,0 +1,31 @@
+package systems
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+)
+
+// FindPlayer returns the first entity the user controls.
+func FindPlayer(w *ecs.World) (ecs.Entity, bool) {
+	targetMask := components.MaskPlayerControl | components.MaskPosition
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & targetMask) == targetMask {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// FloorLinkAt returns the floor a stair or elevator at the given coordinates leads to.
+func FloorLinkAt(w *ecs.World, x, y int) (int, bool) {
+	targetMask := components.MaskPosition | components.MaskFloorLink
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & targetMask) == targetMask {
+			pos := w.Positions[i]
+			if pos.X == x && pos.Y == y {
+				return w.FloorLinks[i].TargetFloor, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -1,10 +1,11 @@
 package systems
 
 import (
-	rl "github.com/gen2brain/raylib-go/raylib"
 	"github.com/vikash-paf/derelict-facility/internal/components"
 	"github.com/vikash-paf/derelict-facility/internal/core"
 	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/input"
+	"github.com/vikash-paf/derelict-facility/internal/msglog"
 	"github.com/vikash-paf/derelict-facility/internal/world"
 )
 
@@ -22,26 +23,31 @@ func IsSolidAt(w *ecs.World, x, y int) bool {
 	return false
 }
 
-// ProcessPlayerInput handles intentional movement from W/A/S/D.
-func ProcessPlayerInput(w *ecs.World, events []core.InputEvent, gameMap *world.Map) {
+// ProcessPlayerInput handles intentional movement and the other actions the player's keys are bound to.
+func ProcessPlayerInput(w *ecs.World, actions []input.Action, gameMap *world.Map) {
 	dx, dy := 0, 0
 	toggleAutopilot := false
+	toggleExplore := false
 	interactPressed := false
 
-	for _, event := range events {
-		switch event.Key {
-		case rl.KeyW:
-			dy = -1
-		case rl.KeyS:
-			dy = 1
-		case rl.KeyA:
-			dx = -1
-		case rl.KeyD:
-			dx = 1
-		case rl.KeyP:
-			toggleAutopilot = true
-		case rl.KeyE:
+	for _, action := range actions {
+		switch action {
+		case input.ActionInteract:
 			interactPressed = true
+		case input.ActionToggleAutopilot:
+			toggleAutopilot = true
+		case input.ActionToggleSurvey:
+			toggleExplore = true
+		default:
+			// Cardinal moves only set their own axis, so two held at once still make a diagonal
+			if sx, sy := action.Step(); sx != 0 || sy != 0 {
+				if sx != 0 {
+					dx = sx
+				}
+				if sy != 0 {
+					dy = sy
+				}
+			}
 		}
 	}
 
@@ -52,37 +58,62 @@ func ProcessPlayerInput(w *ecs.World, events []core.InputEvent, gameMap *world.M
 			controls := &w.PlayerControls[i]
 			positions := &w.Positions[i]
 
-			if toggleAutopilot {
-				controls.Autopilot = !controls.Autopilot
+			if controls.MoveCooldown > 0 {
+				controls.MoveCooldown--
+			}
+
+			if toggleAutopilot || toggleExplore {
+				if toggleExplore {
+					controls.Explore = !controls.Explore
+					controls.Autopilot = controls.Explore
+				} else {
+					controls.Autopilot = !controls.Autopilot
+					controls.Explore = false
+				}
 				controls.CurrentPath = nil // clear path when toggling
+				controls.Waypoints = nil
+				controls.PathPending = false
+				controls.Travelling = false
 			}
 
 			if interactPressed {
 				// Find adjacent interactable entities
-				handleInteraction(w, positions.X, positions.Y)
+				handleInteraction(w, gameMap, positions.X, positions.Y)
 			}
 
-			// Don't manually move if Autopilot is running
-			if controls.Autopilot || (dx == 0 && dy == 0) {
+			// A key press takes back control from a clicked walk
+			if controls.Travelling && (dx != 0 || dy != 0) {
+				controls.Travelling = false
+				controls.CurrentPath = nil
+			}
+
+			// Don't manually move if Autopilot is running, or while still wading through the last step
+			if controls.Autopilot || (dx == 0 && dy == 0) || controls.MoveCooldown > 0 {
 				continue
 			}
 
-			newX := positions.X + dx
-			newY := positions.Y + dy
+			// Two keys at once make a diagonal, which only counts as horizontal without diagonal moves
+			stepX, stepY := dx, dy
+			if !controls.Diagonal && stepX != 0 {
+				stepY = 0
+			}
 
-			// ensure valid move
-			if newX >= 0 && newX < gameMap.Width && newY >= 0 && newY < gameMap.Height {
-				tile := gameMap.GetTile(newX, newY)
-				if tile != nil && tile.Walkable && !IsSolidAt(w, newX, newY) {
-					positions.X = newX
-					positions.Y = newY
-				}
+			// ensure valid move, diagonals can't squeeze between two walls
+			opts := world.PathOptions{Diagonal: controls.Diagonal}
+			canEnter := func(x, y int) bool {
+				return gameMap.IsWalkable(x, y) && !IsSolidAt(w, x, y)
+			}
+			if opts.CanStep(positions.X, positions.Y, stepX, stepY, canEnter) {
+				positions.X += stepX
+				positions.Y += stepY
+				controls.MoveCooldown = WadeDelay(gameMap.WaterAt(positions.X, positions.Y))
+				footstep(w, gameMap, positions.X, positions.Y)
 			}
 		}
 	}
 }
 
-func handleInteraction(w *ecs.World, playerX, playerY int) {
+func handleInteraction(w *ecs.World, gameMap *world.Map, playerX, playerY int) {
 	targetMask := components.MaskPosition | components.MaskInteractable
 	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
 		if (w.Masks[i] & targetMask) == targetMask {
@@ -100,6 +131,12 @@ func handleInteraction(w *ecs.World, playerX, playerY int) {
 				if (w.Masks[i] & components.MaskPowerGenerator) != 0 {
 					gen := &w.PowerGenerators[i]
 					gen.IsActive = !gen.IsActive
+					if gen.IsActive {
+						w.EmitSound(pos.X, pos.Y, generatorStartNoise)
+						w.Post(msglog.SeverityGood, "Generator online")
+					} else {
+						w.Post(msglog.SeverityWarning, "Generator offline")
+					}
 
 					// Update visual feedback
 					if (w.Masks[i] & components.MaskGlyph) != 0 {
@@ -117,38 +154,97 @@ func handleInteraction(w *ecs.World, playerX, playerY int) {
 
 				// 2. Door
 				if (w.Masks[i] & components.MaskDoor) != 0 {
-					door := &w.Doors[i]
-					door.IsOpen = !door.IsOpen
+					if !IsPowered(w, i) {
+						w.Post(msglog.SeverityWarning, "The door has no power")
+						return // Dead doors stay the way they are
+					}
+					toggleDoor(w, gameMap, i)
+					return // Stop after interacting
+				}
+
+				// 3. Terminal
+				if (w.Masks[i] & components.MaskTerminal) != 0 {
+					if !IsPowered(w, i) {
+						w.Post(msglog.SeverityWarning, "The terminal screen is dark")
+						return // The screen is dark
+					}
+					// Log in, the engine brings up its screen
+					w.Terminals[i].InUse = true
+					return // Stop after interacting
+				}
 
-					if door.IsOpen {
-						// Open the door
-						w.RemoveSolid(i)
-						w.Interactables[i].Prompt = "Press [E] to Close Door"
+				// 4. Breaker
+				if (w.Masks[i] & components.MaskBreaker) != 0 {
+					breaker := &w.Breakers[i]
+					breaker.Closed = !breaker.Closed
+					w.EmitSound(pos.X, pos.Y, switchNoise)
+
+					if breaker.Closed {
+						w.Post(msglog.SeverityInfo, "Breaker closed, the circuit is live")
+						w.Interactables[i].Prompt = "Open Breaker"
+						if (w.Masks[i] & components.MaskGlyph) != 0 {
+							w.Glyphs[i].Color = core.Yellow
+						}
+					} else {
+						w.Post(msglog.SeverityInfo, "Breaker open, the circuit is cut")
+						w.Interactables[i].Prompt = "Close Breaker"
 						if (w.Masks[i] & components.MaskGlyph) != 0 {
-							w.Glyphs[i].Char = "/"
 							w.Glyphs[i].Color = core.Gray
 						}
+					}
+					return // Stop after interacting
+				}
+
+				// 5. Fire suppression
+				if (w.Masks[i] & components.MaskSuppressor) != 0 {
+					if !IsPowered(w, i) {
+						w.Post(msglog.SeverityWarning, "No pressure in the suppressant lines")
+						return // No pressure in the lines
+					}
+					put := Suppress(w, gameMap, i)
+					if put < 0 {
+						w.Post(msglog.SeverityWarning, "The suppressor is empty")
+						return // Already spent
+					}
+					w.EmitSound(pos.X, pos.Y, suppressorNoise)
+					if put > 0 {
+						w.Post(msglog.SeverityGood, "Suppressant discharged, the fire is out")
+					} else {
+						w.Post(msglog.SeverityInfo, "Suppressant discharged")
+					}
+					if w.Suppressors[i].Charges > 0 {
+						w.Interactables[i].Prompt = "Discharge Suppressant"
 					} else {
-						// Close the door
-						w.AddSolid(i)
-						w.Interactables[i].Prompt = "Press [E] to Open Door"
+						w.Interactables[i] = components.Interactable{Status: "SUPPRESSANT EXHAUSTED"}
 						if (w.Masks[i] & components.MaskGlyph) != 0 {
-							w.Glyphs[i].Char = "+"
-							w.Glyphs[i].Color = core.White
+							w.Glyphs[i].Color = core.Gray
 						}
 					}
 					return // Stop after interacting
 				}
 
-				// 3. Terminal
-				if (w.Masks[i] & components.MaskTerminal) != 0 {
-					terminal := &w.Terminals[i]
-					if !terminal.HasSaved {
-						terminal.HasSaved = true
-						w.Interactables[i].Prompt = "[ CHECKPOINT SAVED ]"
+				// 6. Bilge pump
+				if (w.Masks[i] & components.MaskPump) != 0 {
+					pump := &w.Pumps[i]
+					if !pump.Running && !IsPowered(w, i) {
+						w.Post(msglog.SeverityWarning, "The pump motor won't turn over")
+						return // The motor won't turn over
+					}
+					pump.Running = !pump.Running
+					w.EmitSound(pos.X, pos.Y, switchNoise)
+
+					if pump.Running {
+						w.Post(msglog.SeverityInfo, "Pump running")
+						w.Interactables[i].Prompt = "Stop Pump"
 						if (w.Masks[i] & components.MaskGlyph) != 0 {
 							w.Glyphs[i].Color = core.Green
 						}
+					} else {
+						w.Post(msglog.SeverityInfo, "Pump stopped")
+						w.Interactables[i].Prompt = "Start Pump"
+						if (w.Masks[i] & components.MaskGlyph) != 0 {
+							w.Glyphs[i].Color = core.Blue
+						}
 					}
 					return // Stop after interacting
 				}
@@ -157,15 +253,64 @@ func handleInteraction(w *ecs.World, playerX, playerY int) {
 	}
 }
 
-// IsPowerActive returns true if at least one generator is currently active
-func IsPowerActive(w *ecs.World) bool {
-	targetMask := components.MaskPowerGenerator
+// SaveCheckpoint records a checkpoint at a terminal. Returns false if it already holds one.
+func SaveCheckpoint(w *ecs.World, i ecs.Entity) bool {
+	terminal := &w.Terminals[i]
+	if terminal.HasSaved {
+		return false
+	}
+	terminal.HasSaved = true
+	w.Post(msglog.SeverityGood, "Checkpoint saved")
+	w.Interactables[i] = components.Interactable{Status: "CHECKPOINT SAVED"}
+	if (w.Masks[i] & components.MaskGlyph) != 0 {
+		w.Glyphs[i].Color = core.Green
+	}
+	return true
+}
+
+// toggleDoor opens a closed door or closes an open one. The routes cached for the map go stale
+// with it, straight away rather than at the end of the tick.
+func toggleDoor(w *ecs.World, gameMap *world.Map, i ecs.Entity) {
+	pos := w.Positions[i]
+	door := &w.Doors[i]
+	door.IsOpen = !door.IsOpen
+	w.EmitSound(pos.X, pos.Y, doorNoise)
+	gameMap.BumpRevision()
+
+	if door.IsOpen {
+		// Open the door
+		w.RemoveSolid(i)
+		w.Post(msglog.SeverityInfo, "Door opened")
+		w.Interactables[i].Prompt = "Close Door"
+		if (w.Masks[i] & components.MaskGlyph) != 0 {
+			w.Glyphs[i].Char = "/"
+			w.Glyphs[i].Color = core.Gray
+		}
+	} else {
+		// Close the door
+		w.AddSolid(i)
+		w.Post(msglog.SeverityInfo, "Door closed")
+		w.Interactables[i].Prompt = "Open Door"
+		if (w.Masks[i] & components.MaskGlyph) != 0 {
+			w.Glyphs[i].Char = "+"
+			w.Glyphs[i].Color = core.White
+		}
+	}
+}
+
+// OpenDoorAt opens the closed door on (x, y) the way the player would. Returns false if there's
+// no closed door there, or it has no power to open.
+func OpenDoorAt(w *ecs.World, gameMap *world.Map, x, y int) bool {
+	doorMask := components.MaskPosition | components.MaskDoor
 	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
-		if (w.Masks[i] & targetMask) == targetMask {
-			if w.PowerGenerators[i].IsActive {
-				return true
-			}
+		if (w.Masks[i]&doorMask) != doorMask || w.Positions[i].X != x || w.Positions[i].Y != y {
+			continue
+		}
+		if w.Doors[i].IsOpen || !IsPowered(w, i) {
+			return false
 		}
+		toggleDoor(w, gameMap, i)
+		return true
 	}
 	return false
 }
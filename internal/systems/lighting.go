@@ -0,0 +1,74 @@
+package systems
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+// flickerPeriod is how many ticks a flickering light holds its brightness before picking a new one.
+const flickerPeriod = 4
+
+// ProcessLighting rebuilds the map's light buffer from every entity with a Light, and every fire.
+// It must run before ComputeFOV, which only reveals lit tiles.
+func ProcessLighting(w *ecs.World, gameMap *world.Map, tick int, blocksLight func(x, y int) bool) {
+	if gameMap.Light == nil {
+		return
+	}
+	gameMap.ClearLight()
+
+	targetMask := components.MaskPosition | components.MaskLight
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & targetMask) != targetMask {
+			continue
+		}
+
+		// A generator's floodlights are only on while it runs
+		if (w.Masks[i]&components.MaskPowerGenerator) != 0 && !w.PowerGenerators[i].IsActive {
+			continue
+		}
+
+		light := w.Lights[i]
+		intensity := light.Intensity
+		flicker := light.Flicker
+
+		// Mains lights dim with their supply, and stutter when the grid is browned out
+		if (w.Masks[i] & components.MaskPowerConsumer) != 0 {
+			consumer := w.PowerConsumers[i]
+			if consumer.Supply <= 0 {
+				continue
+			}
+			intensity *= consumer.Supply
+			if consumer.Supply < 1 {
+				flicker = max(flicker, 1-consumer.Supply)
+			}
+		}
+
+		pos := w.Positions[i]
+		gameMap.AddLight(world.LightSource{
+			X:         pos.X,
+			Y:         pos.Y,
+			Radius:    light.Radius,
+			Color:     light.Color,
+			Intensity: intensity * flickerFactor(flicker, tick, i),
+		}, blocksLight)
+	}
+
+	addFireLight(gameMap, tick, blocksLight)
+}
+
+// flickerFactor scales a light's intensity between 1-amount and 1. It hashes the tick and entity
+// instead of using an RNG so the same tick always looks the same, and lamps don't flicker in sync.
+func flickerFactor(amount float32, tick int, e ecs.Entity) float32 {
+	if amount <= 0 {
+		return 1
+	}
+
+	h := uint32(tick/flickerPeriod)*2654435761 ^ uint32(e)*2246822519
+	h ^= h >> 15
+	h *= 2654435761
+	h ^= h >> 13
+	noise := float32(h&0xffff) / 0xffff // 0..1
+
+	return 1 - amount*noise
+}
@@ -0,0 +1,49 @@
+package systems
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/core"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+// ProcessMouse sends the player walking to the tile they clicked, around walls, solids and hazards.
+// Only explored tiles can be picked, and the route only crosses explored tiles, so it never gives
+// away what's still dark. The walk takes over from the autopilot and stops at the tile.
+func ProcessMouse(w *ecs.World, events []core.InputEvent, gameMap *world.Map, pf *world.Pathfinder) {
+	for _, event := range events {
+		if event.Mouse != core.MouseClick {
+			continue
+		}
+		tile := gameMap.GetTile(event.CellX, event.CellY)
+		if tile == nil || !tile.Explored || !tile.Walkable {
+			continue
+		}
+
+		targetMask := components.MaskPlayerControl | components.MaskPosition
+		for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+			if (w.Masks[i] & targetMask) != targetMask {
+				continue
+			}
+			ctrl := &w.PlayerControls[i]
+			pos := w.Positions[i]
+
+			start := entity.Point{X: pos.X, Y: pos.Y}
+			target := entity.Point{X: event.CellX, Y: event.CellY}
+			path := pf.FindPathWithOptions(gameMap, start, target, func(x, y int) bool {
+				t := gameMap.GetTile(x, y)
+				return t != nil && t.Explored && t.Walkable && !IsSolidAt(w, x, y)
+			}, world.PathOptions{Diagonal: ctrl.Diagonal, Cost: gameMap.HazardCost})
+			if len(path) < 2 {
+				continue // Already there, or no way there
+			}
+
+			ctrl.Autopilot, ctrl.Explore = false, false
+			ctrl.Waypoints = nil
+			ctrl.PathPending = false
+			ctrl.CurrentPath = path[1:]
+			ctrl.Travelling = true
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package systems
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+// How far each kind of noise carries, in open floor tiles
+const (
+	footstepNoise       = 4
+	splashNoise         = 8 // A footstep in water
+	switchNoise         = 8 // Breakers and pump controls clunking over
+	doorNoise           = 12
+	suppressorNoise     = 16
+	generatorStartNoise = 24
+	generatorHum        = 8 // Running machinery never stops making noise
+	pumpHum             = 6
+)
+
+// ProcessNoise rebuilds the noise map from the running machinery and every sound queued this tick.
+// solids marks the tiles holding a Solid entity, so closed doors muffle the noise.
+func ProcessNoise(w *ecs.World, gameMap *world.Map, noise *world.NoiseMap, solids []bool) {
+	noise.Clear()
+
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & components.MaskPosition) == 0 {
+			continue
+		}
+		pos := w.Positions[i]
+
+		if (w.Masks[i]&components.MaskPowerGenerator) != 0 && w.PowerGenerators[i].IsActive {
+			noise.Emit(gameMap, pos.X, pos.Y, generatorHum, solids)
+		}
+		if (w.Masks[i]&components.MaskPump) != 0 && w.Pumps[i].Running {
+			noise.Emit(gameMap, pos.X, pos.Y, pumpHum, solids)
+		}
+	}
+
+	for _, s := range w.Sounds {
+		noise.Emit(gameMap, s.X, s.Y, s.Loudness, solids)
+	}
+	w.Sounds = w.Sounds[:0]
+}
+
+// footstep makes the noise of something stepping onto (x, y), louder when it splashes.
+func footstep(w *ecs.World, gameMap *world.Map, x, y int) {
+	loudness := footstepNoise
+	if gameMap.WaterAt(x, y) >= world.DryDepth {
+		loudness = splashNoise
+	}
+	w.EmitSound(x, y, loudness)
+}
@@ -0,0 +1,90 @@
+package systems
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+// minOperatingSupply is the share of its demand a device needs to work. Below it doors and
+// terminals are dead, and lights only glow.
+const minOperatingSupply = 0.5
+
+// ProcessPower relabels the grids (breakers may have been flipped), balances every grid's
+// generators against its consumers and tells each consumer how much power it got.
+// Devices standing in deep water are shorted out and get nothing.
+func ProcessPower(w *ecs.World, gameMap *world.Map, grid *world.PowerGrid) {
+	// Only a handful of breakers are ever open, a short list beats a per-tile lookup here
+	var openBreakers []entity.Point
+	breakerMask := components.MaskPosition | components.MaskBreaker
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i]&breakerMask) == breakerMask && !w.Breakers[i].Closed {
+			pos := w.Positions[i]
+			openBreakers = append(openBreakers, entity.Point{X: pos.X, Y: pos.Y})
+		}
+	}
+
+	grid.Label(gameMap, func(x, y int) bool {
+		for _, p := range openBreakers {
+			if p.X == x && p.Y == y {
+				return true
+			}
+		}
+		return false
+	})
+
+	// 1. Add up supply and demand per grid
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & components.MaskPosition) == 0 {
+			continue
+		}
+		isGenerator := (w.Masks[i] & components.MaskPowerGenerator) != 0
+		isConsumer := (w.Masks[i] & components.MaskPowerConsumer) != 0
+		if !isGenerator && !isConsumer {
+			continue
+		}
+
+		pos := w.Positions[i]
+		id := grid.GridAt(gameMap, pos.X, pos.Y)
+		if id == world.NoGrid {
+			continue
+		}
+
+		// Anything standing in deep water has shorted out and neither gives nor takes power
+		if IsShorted(gameMap, pos.X, pos.Y) {
+			continue
+		}
+
+		if isGenerator && w.PowerGenerators[i].IsActive {
+			grid.Grids[id].Supply += w.PowerGenerators[i].Output
+		}
+		if isConsumer {
+			grid.Grids[id].Demand += w.PowerConsumers[i].Demand
+		}
+	}
+
+	// 2. Share each grid's supply out between its consumers
+	targetMask := components.MaskPosition | components.MaskPowerConsumer
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & targetMask) != targetMask {
+			continue
+		}
+
+		consumer := &w.PowerConsumers[i]
+		pos := w.Positions[i]
+		consumer.Supply = 0
+		if id := grid.GridAt(gameMap, pos.X, pos.Y); id != world.NoGrid && !IsShorted(gameMap, pos.X, pos.Y) {
+			consumer.Supply = grid.Grids[id].Ratio()
+		}
+		consumer.Powered = consumer.Supply >= minOperatingSupply
+	}
+}
+
+// IsPowered reports whether an entity can work. Anything that doesn't draw power always can.
+func IsPowered(w *ecs.World, e ecs.Entity) bool {
+	if (w.Masks[e] & components.MaskPowerConsumer) == 0 {
+		return true
+	}
+	return w.PowerConsumers[e].Powered
+}
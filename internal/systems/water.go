@@ -0,0 +1,53 @@
+package systems
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/components"
+	"github.com/vikash-paf/derelict-facility/internal/ecs"
+	"github.com/vikash-paf/derelict-facility/internal/world"
+)
+
+// maxWadeDelay is how many ticks a step through waist-deep water holds the player up.
+const maxWadeDelay = 12
+
+// ProcessWater pours in water from every source, runs the pumps and then lets the water flow.
+// solids marks the tiles holding a Solid entity, so closed doors hold the water back.
+func ProcessWater(w *ecs.World, gameMap *world.Map, solids []bool) {
+	water := gameMap.Water
+	if water == nil {
+		return
+	}
+
+	for i := ecs.Entity(0); i < ecs.MaxEntities; i++ {
+		if (w.Masks[i] & components.MaskPosition) == 0 {
+			continue
+		}
+		pos := w.Positions[i]
+
+		if (w.Masks[i] & components.MaskWaterSource) != 0 {
+			water.Pour(gameMap, pos.X, pos.Y, w.WaterSources[i].Rate)
+		}
+
+		if (w.Masks[i]&components.MaskPump) != 0 && w.Pumps[i].Running {
+			rate := w.Pumps[i].Rate
+			if (w.Masks[i] & components.MaskPowerConsumer) != 0 {
+				rate *= w.PowerConsumers[i].Supply
+			}
+			water.Pump(gameMap, gameMap.RoomArea(pos.X, pos.Y, deviceReach), rate)
+		}
+	}
+
+	water.Step(gameMap, solids)
+}
+
+// WadeDelay is how many ticks a step into water this deep costs, nothing on a dry floor.
+func WadeDelay(depth float32) int {
+	if depth < world.DryDepth {
+		return 0
+	}
+	return int(min(depth, 1) * maxWadeDelay)
+}
+
+// IsShorted reports whether a device at (x, y) is standing in enough water to short out.
+func IsShorted(gameMap *world.Map, x, y int) bool {
+	return gameMap.WaterAt(x, y) >= world.ShortCircuitDepth
+}
@@ -0,0 +1,80 @@
+package ui
+
+import "github.com/vikash-paf/derelict-facility/internal/input"
+
+// Focus decides which widget the player's actions go to. FocusNext cycles through the widgets
+// that were added, while a modal is open it gets every action until it's closed.
+type Focus struct {
+	widgets []Focusable
+	current int
+	modals  []Focusable
+}
+
+// Add puts a widget in the cycle. The first one added starts out focused.
+func (f *Focus) Add(w Focusable) {
+	f.widgets = append(f.widgets, w)
+	w.SetFocused(len(f.widgets) == 1 && len(f.modals) == 0)
+}
+
+// Focused is the widget actions go to, nil if there's none.
+func (f *Focus) Focused() Focusable {
+	if n := len(f.modals); n > 0 {
+		return f.modals[n-1]
+	}
+	if len(f.widgets) == 0 {
+		return nil
+	}
+	return f.widgets[f.current]
+}
+
+// Modal is the dialog on top, nil if none is open.
+func (f *Focus) Modal() Focusable {
+	if n := len(f.modals); n > 0 {
+		return f.modals[n-1]
+	}
+	return nil
+}
+
+// Open shows a modal over everything else and hands it focus.
+func (f *Focus) Open(modal Focusable) {
+	if w := f.Focused(); w != nil {
+		w.SetFocused(false)
+	}
+	f.modals = append(f.modals, modal)
+	modal.SetFocused(true)
+}
+
+// Close dismisses the top modal and gives focus back to whatever had it before.
+func (f *Focus) Close() {
+	n := len(f.modals)
+	if n == 0 {
+		return
+	}
+	f.modals[n-1].SetFocused(false)
+	f.modals = f.modals[:n-1]
+	if w := f.Focused(); w != nil {
+		w.SetFocused(true)
+	}
+}
+
+// Next moves focus on to the next widget in the cycle. It does nothing while a modal is open.
+func (f *Focus) Next() {
+	if len(f.modals) > 0 || len(f.widgets) == 0 {
+		return
+	}
+	f.widgets[f.current].SetFocused(false)
+	f.current = (f.current + 1) % len(f.widgets)
+	f.widgets[f.current].SetFocused(true)
+}
+
+// HandleAction passes an action on to the focused widget, returning false if nothing used it.
+func (f *Focus) HandleAction(a input.Action) bool {
+	if a == input.ActionFocusNext && len(f.modals) == 0 && len(f.widgets) > 1 {
+		f.Next()
+		return true
+	}
+	if w := f.Focused(); w != nil {
+		return w.HandleAction(a)
+	}
+	return false
+}
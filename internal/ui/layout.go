@@ -0,0 +1,105 @@
+package ui
+
+// Column stacks its children top to bottom, each as tall as it asks for.
+// Children that don't fit are left out.
+type Column struct {
+	Children []Widget
+	Gap      int // Blank lines between children
+}
+
+func (col *Column) Size() (int, int) {
+	w, h := 0, 0
+	for i, child := range col.Children {
+		cw, ch := child.Size()
+		w = max(w, cw)
+		h += ch
+		if i > 0 {
+			h += col.Gap
+		}
+	}
+	return w, h
+}
+
+func (col *Column) Draw(c Canvas, r Rect) {
+	y := r.Y
+	for _, child := range col.Children {
+		_, ch := child.Size()
+		ch = min(ch, r.Y+r.H-y)
+		if ch <= 0 {
+			return
+		}
+		child.Draw(c, Rect{X: r.X, Y: y, W: r.W, H: ch})
+		y += ch + col.Gap
+	}
+}
+
+// Row lines its children up left to right. Fixed children get the width they ask for, in order,
+// and the Fill children split whatever is left between them.
+type Row struct {
+	Children []Widget
+	Gap      int // Blank cells between children
+}
+
+func (row *Row) Size() (int, int) {
+	w, h := 0, 0
+	for i, child := range row.Children {
+		cw, ch := child.Size()
+		w += cw
+		h = max(h, ch)
+		if i > 0 {
+			w += row.Gap
+		}
+	}
+	return w, h
+}
+
+func (row *Row) Draw(c Canvas, r Rect) {
+	// Work out what's left over for the Fills once everything else has its width
+	spare := r.W - row.Gap*max(len(row.Children)-1, 0)
+	fills := 0
+	for _, child := range row.Children {
+		if _, ok := child.(*Fill); ok {
+			fills++
+			continue
+		}
+		cw, _ := child.Size()
+		spare -= cw
+	}
+	spare = max(spare, 0)
+
+	x := r.X
+	for _, child := range row.Children {
+		cw, _ := child.Size()
+		if _, ok := child.(*Fill); ok {
+			cw = spare / fills
+			spare -= cw
+			fills--
+		}
+		cw = min(cw, r.X+r.W-x)
+		if cw <= 0 {
+			return
+		}
+		child.Draw(c, Rect{X: x, Y: r.Y, W: cw, H: r.H})
+		x += cw + row.Gap
+	}
+}
+
+// Fill takes up the space a Row has left over. Without a child it's just a gap that pushes
+// the rest of the row to the right; with one, the child is clipped to whatever room there is.
+type Fill struct {
+	Child Widget
+}
+
+func (f *Fill) Size() (int, int) {
+	if f.Child == nil {
+		return 0, 1
+	}
+	_, h := f.Child.Size()
+	return 0, h
+}
+
+func (f *Fill) Draw(c Canvas, r Rect) {
+	if f.Child != nil {
+		f.Child.Draw(c, r)
+	}
+}
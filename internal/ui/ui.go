@@ -0,0 +1,117 @@
+// Package ui draws panels, menus and dialogs on the character grid, and routes the player's
+// actions to whichever of them has focus.
+//
+// Widgets don't remember where they are. The screen is laid out from scratch every frame: a
+// widget is handed the Rect it may draw in and clips itself to it, so the same widget tree fits
+// any window size.
+package ui
+
+import (
+	"unicode/utf8"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+	"github.com/vikash-paf/derelict-facility/internal/input"
+)
+
+// Canvas is the part of display.Display widgets draw with.
+type Canvas interface {
+	DrawRect(gridX, gridY int, color core.Color)
+	DrawText(gridX, gridY int, text string, color core.Color)
+}
+
+// Widget is anything that can be laid out and drawn.
+type Widget interface {
+	// Size is how many cells the widget would like, layouts may give it less.
+	Size() (width, height int)
+	Draw(c Canvas, r Rect)
+}
+
+// Focusable is a widget that takes the player's actions while it has focus.
+type Focusable interface {
+	Widget
+	// HandleAction reacts to an action, returning false if the widget had no use for it.
+	HandleAction(a input.Action) bool
+	SetFocused(focused bool)
+}
+
+// Rect is an area of the grid, in cells.
+type Rect struct {
+	X, Y, W, H int
+}
+
+// Empty reports whether the rect has no cells in it.
+func (r Rect) Empty() bool {
+	return r.W <= 0 || r.H <= 0
+}
+
+// Inset shrinks the rect by n cells on every side.
+func (r Rect) Inset(n int) Rect {
+	return Rect{X: r.X + n, Y: r.Y + n, W: max(r.W-2*n, 0), H: max(r.H-2*n, 0)}
+}
+
+// Row returns the i-th line of the rect as a rect one cell high.
+func (r Rect) Row(i int) Rect {
+	if i < 0 || i >= r.H {
+		return Rect{X: r.X, Y: r.Y + i}
+	}
+	return Rect{X: r.X, Y: r.Y + i, W: r.W, H: 1}
+}
+
+// Center places a w by h rect in the middle of this one, shrunk to fit if it has to be.
+func (r Rect) Center(w, h int) Rect {
+	w, h = min(w, r.W), min(h, r.H)
+	return Rect{X: r.X + (r.W-w)/2, Y: r.Y + (r.H-h)/2, W: w, H: h}
+}
+
+// Align places text within a line.
+type Align uint8
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// TextWidth is how many cells a string takes up, one per rune.
+func TextWidth(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// clip cuts a string down to at most n cells.
+func clip(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if TextWidth(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n])
+}
+
+// drawLine draws text on the first line of r, clipped and aligned.
+func drawLine(c Canvas, r Rect, text string, align Align, color core.Color) {
+	if r.Empty() {
+		return
+	}
+	text = clip(text, r.W)
+	x := r.X
+	switch align {
+	case AlignCenter:
+		x += (r.W - TextWidth(text)) / 2
+	case AlignRight:
+		x += r.W - TextWidth(text)
+	}
+	if text != "" {
+		c.DrawText(x, r.Y, text, color)
+	}
+}
+
+// fill paints every cell of r, so a panel hides what was drawn under it.
+func fill(c Canvas, r Rect, color core.Color) {
+	for y := r.Y; y < r.Y+r.H; y++ {
+		for x := r.X; x < r.X+r.W; x++ {
+			c.DrawRect(x, y, color)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+	"github.com/vikash-paf/derelict-facility/internal/input"
+)
+
+// testCanvas records what was drawn as a grid of runes, one per cell.
+type testCanvas struct {
+	cells [][]rune
+}
+
+func newTestCanvas(w, h int) *testCanvas {
+	c := &testCanvas{cells: make([][]rune, h)}
+	for y := range c.cells {
+		c.cells[y] = []rune(strings.Repeat(" ", w))
+	}
+	return c
+}
+
+func (c *testCanvas) DrawRect(x, y int, color core.Color) {}
+
+func (c *testCanvas) DrawText(x, y int, text string, color core.Color) {
+	for i, r := range []rune(text) {
+		if y >= 0 && y < len(c.cells) && x+i >= 0 && x+i < len(c.cells[y]) {
+			c.cells[y][x+i] = r
+		}
+	}
+}
+
+func (c *testCanvas) String() string {
+	lines := make([]string, len(c.cells))
+	for y, row := range c.cells {
+		lines[y] = string(row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestRect_Center(t *testing.T) {
+	tests := []struct {
+		r    Rect
+		w, h int
+		want Rect
+	}{
+		{Rect{W: 10, H: 10}, 4, 2, Rect{X: 3, Y: 4, W: 4, H: 2}},
+		{Rect{X: 5, Y: 5, W: 4, H: 4}, 10, 2, Rect{X: 5, Y: 6, W: 4, H: 2}}, // Shrunk to fit
+	}
+	for _, tt := range tests {
+		if got := tt.r.Center(tt.w, tt.h); got != tt.want {
+			t.Errorf("%+v.Center(%d, %d): expected %+v, got %+v", tt.r, tt.w, tt.h, tt.want, got)
+		}
+	}
+}
+
+func TestLabel_Align(t *testing.T) {
+	tests := []struct {
+		align Align
+		width int
+		want  string
+	}{
+		{AlignLeft, 8, "ab      "},
+		{AlignCenter, 8, "   ab   "},
+		{AlignRight, 8, "      ab"},
+	}
+	for _, tt := range tests {
+		c := newTestCanvas(tt.width, 1)
+		(&Label{Text: "ab", Align: tt.align}).Draw(c, Rect{W: tt.width, H: 1})
+		if got := c.String(); got != tt.want {
+			t.Errorf("Align %d: expected %q, got %q", tt.align, tt.want, got)
+		}
+	}
+
+	// Too long for its rect, it's cut off rather than spilling over
+	c := newTestCanvas(6, 1)
+	(&Label{Text: "STATUS: HEALTHY"}).Draw(c, Rect{X: 1, W: 4, H: 1})
+	if got := c.String(); got != " STAT " {
+		t.Errorf("Expected the label clipped to its rect, got %q", got)
+	}
+}
+
+func TestRow_Fill(t *testing.T) {
+	row := &Row{Gap: 1, Children: []Widget{
+		&Label{Text: "A"},
+		&Fill{Child: &Label{Text: "hint hint hint"}},
+		&Label{Text: "B"},
+	}}
+
+	c := newTestCanvas(20, 1)
+	row.Draw(c, Rect{W: 20, H: 1})
+	if got, want := c.String(), "A hint hint hint   B"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// Narrow, the Fill gives way and the fixed children keep their place
+	c = newTestCanvas(10, 1)
+	row.Draw(c, Rect{W: 10, H: 1})
+	if got, want := c.String(), "A hint h B"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestPanel_Draw(t *testing.T) {
+	panel := &Panel{Title: "LOG", Child: &Column{Children: []Widget{&Label{Text: "one"}, &Label{Text: "two"}}}}
+	w, h := panel.Size()
+	c := newTestCanvas(w, h)
+	panel.Draw(c, Rect{W: w, H: h})
+
+	want := strings.Join([]string{
+		"╔═ LOG ═╗",
+		"║one    ║",
+		"║two    ║",
+		"╚═══════╝",
+	}, "\n")
+	if got := c.String(); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestProgressBar_Draw(t *testing.T) {
+	tests := []struct {
+		value float32
+		want  string
+	}{
+		{0, "░░░░"},
+		{0.5, "██░░"},
+		{1, "████"},
+		{2, "████"}, // Capped
+	}
+	for _, tt := range tests {
+		c := newTestCanvas(4, 1)
+		(&ProgressBar{Value: tt.value, Max: 1, Width: 4}).Draw(c, Rect{W: 4, H: 1})
+		if got := c.String(); got != tt.want {
+			t.Errorf("Value %v: expected %q, got %q", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestList_HandleAction(t *testing.T) {
+	chosen := -1
+	list := &List{Items: []string{"Resume", "Options", "Quit"}, OnActivate: func(i int) { chosen = i }}
+	list.SetFocused(true)
+
+	steps := []struct {
+		action   input.Action
+		handled  bool
+		selected int
+	}{
+		{input.ActionMoveSouth, true, 1},
+		{input.ActionMoveSouth, true, 2},
+		{input.ActionMoveSouth, true, 0}, // Wraps around
+		{input.ActionMoveNorth, true, 2},
+		{input.ActionMoveEast, false, 2}, // Not for a vertical list
+	}
+	for i, step := range steps {
+		if got := list.HandleAction(step.action); got != step.handled {
+			t.Errorf("Step %d: expected handled %v, got %v", i, step.handled, got)
+		}
+		if list.Selected != step.selected {
+			t.Errorf("Step %d: expected selection %d, got %d", i, step.selected, list.Selected)
+		}
+	}
+
+	list.HandleAction(input.ActionInteract)
+	if chosen != 2 {
+		t.Errorf("Expected Quit to be chosen, got %d", chosen)
+	}
+
+	c := newTestCanvas(10, 3)
+	list.Draw(c, Rect{W: 10, H: 3})
+	if got := strings.Split(c.String(), "\n")[2]; got != "► Quit    " {
+		t.Errorf("Expected the focused selection marked, got %q", got)
+	}
+}
+
+func TestFocus_Modal(t *testing.T) {
+	menu := &List{Items: []string{"a", "b"}}
+	other := &List{Items: []string{"c", "d"}}
+	var f Focus
+	f.Add(menu)
+	f.Add(other)
+
+	if f.Focused() != menu || !menu.focused || other.focused {
+		t.Fatalf("Expected the first widget added to have focus")
+	}
+	f.HandleAction(input.ActionFocusNext)
+	if f.Focused() != other || menu.focused || !other.focused {
+		t.Fatalf("Expected FocusNext to move focus on")
+	}
+	f.HandleAction(input.ActionFocusNext)
+
+	answer := -1
+	dialog := NewDialog("QUIT", "Really?", []string{"No", "Yes"}, func(i int) { answer = i })
+	f.Open(dialog)
+	if menu.focused {
+		t.Errorf("The menu should lose focus under a modal")
+	}
+
+	// Everything goes to the dialog until it's closed
+	f.HandleAction(input.ActionMoveSouth)
+	f.HandleAction(input.ActionFocusNext)
+	if menu.Selected != 0 || f.Focused() != dialog {
+		t.Errorf("Actions leaked past the modal")
+	}
+	f.HandleAction(input.ActionMoveEast)
+	f.HandleAction(input.ActionInteract)
+	if answer != 1 {
+		t.Errorf("Expected Yes to be chosen, got %d", answer)
+	}
+
+	f.Close()
+	if f.Modal() != nil || f.Focused() != menu || !menu.focused {
+		t.Errorf("Expected focus back on the menu once the dialog closed")
+	}
+}
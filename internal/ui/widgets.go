@@ -0,0 +1,254 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+	"github.com/vikash-paf/derelict-facility/internal/input"
+)
+
+// Label is a single line of text.
+type Label struct {
+	Text  string
+	Color core.Color
+	Align Align
+}
+
+func (l *Label) Size() (int, int) {
+	return TextWidth(l.Text), 1
+}
+
+func (l *Label) Draw(c Canvas, r Rect) {
+	drawLine(c, r, l.Text, l.Align, l.Color)
+}
+
+// Rule is a horizontal line across the whole of its rect.
+type Rule struct {
+	Color core.Color
+}
+
+func (rl *Rule) Size() (int, int) {
+	return 0, 1
+}
+
+func (rl *Rule) Draw(c Canvas, r Rect) {
+	drawLine(c, r, strings.Repeat("═", r.W), AlignLeft, rl.Color)
+}
+
+// Panel draws a double-line border around its child, with the title set into the top edge.
+type Panel struct {
+	Title      string
+	Color      core.Color // The border and title
+	Background core.Color // Painted under the panel first, unless it's fully transparent
+	Padding    int        // Blank cells between the border and the child on the left and right
+	Child      Widget
+}
+
+func (p *Panel) Size() (int, int) {
+	w, h := 0, 0
+	if p.Child != nil {
+		w, h = p.Child.Size()
+	}
+	w = max(w+2*p.Padding, TextWidth(p.Title)+4)
+	return w + 2, h + 2
+}
+
+func (p *Panel) Draw(c Canvas, r Rect) {
+	if r.W < 2 || r.H < 2 {
+		return
+	}
+	if p.Background.A > 0 {
+		fill(c, r, p.Background)
+	}
+
+	top := "╔" + strings.Repeat("═", r.W-2) + "╗"
+	if p.Title != "" && r.W >= TextWidth(p.Title)+6 {
+		title := "═ " + p.Title + " "
+		top = "╔" + title + strings.Repeat("═", r.W-2-TextWidth(title)) + "╗"
+	}
+	c.DrawText(r.X, r.Y, top, p.Color)
+	for y := r.Y + 1; y < r.Y+r.H-1; y++ {
+		c.DrawText(r.X, y, "║", p.Color)
+		c.DrawText(r.X+r.W-1, y, "║", p.Color)
+	}
+	c.DrawText(r.X, r.Y+r.H-1, "╚"+strings.Repeat("═", r.W-2)+"╝", p.Color)
+
+	if p.Child != nil {
+		inner := Rect{X: r.X + 1 + p.Padding, Y: r.Y + 1, W: r.W - 2 - 2*p.Padding, H: r.H - 2}
+		if !inner.Empty() {
+			p.Child.Draw(c, inner)
+		}
+	}
+}
+
+// ProgressBar fills Width cells in proportion to Value out of Max.
+type ProgressBar struct {
+	Value, Max float32
+	Width      int
+	Color      core.Color
+	EmptyColor core.Color
+}
+
+func (b *ProgressBar) Size() (int, int) {
+	return b.Width, 1
+}
+
+func (b *ProgressBar) Draw(c Canvas, r Rect) {
+	w := min(b.Width, r.W)
+	if w <= 0 || r.H <= 0 {
+		return
+	}
+	filled := 0
+	if b.Max > 0 {
+		filled = int(b.Value/b.Max*float32(w) + 0.5)
+		filled = max(0, min(filled, w))
+	}
+	if filled > 0 {
+		c.DrawText(r.X, r.Y, strings.Repeat("█", filled), b.Color)
+	}
+	if filled < w {
+		c.DrawText(r.X+filled, r.Y, strings.Repeat("░", w-filled), b.EmptyColor)
+	}
+}
+
+// List is a menu of items with one selected. Up and down (left and right for a Horizontal
+// list) move the selection, Interact picks it.
+type List struct {
+	Items         []string
+	Selected      int
+	Color         core.Color
+	SelectedColor core.Color
+	Horizontal    bool // Items side by side like buttons, "[ Yes ]  [ No ]"
+	Align         Align
+	OnActivate    func(index int)
+	focused       bool
+}
+
+const listGap = 2 // Cells between the items of a horizontal list
+
+func (l *List) item(i int) string {
+	if l.Horizontal {
+		return "[ " + l.Items[i] + " ]"
+	}
+	return "  " + l.Items[i]
+}
+
+func (l *List) Size() (int, int) {
+	if l.Horizontal {
+		w := 0
+		for i := range l.Items {
+			w += TextWidth(l.item(i))
+		}
+		return w + listGap*max(len(l.Items)-1, 0), 1
+	}
+	w := 0
+	for i := range l.Items {
+		w = max(w, TextWidth(l.item(i)))
+	}
+	return w, len(l.Items)
+}
+
+func (l *List) color(i int) core.Color {
+	if i == l.Selected && l.focused {
+		return l.SelectedColor
+	}
+	return l.Color
+}
+
+func (l *List) Draw(c Canvas, r Rect) {
+	if r.Empty() {
+		return
+	}
+	if l.Horizontal {
+		w, _ := l.Size()
+		x := r.X
+		switch l.Align {
+		case AlignCenter:
+			x += max((r.W-w)/2, 0)
+		case AlignRight:
+			x += max(r.W-w, 0)
+		}
+		for i := range l.Items {
+			text := clip(l.item(i), r.X+r.W-x)
+			if text == "" {
+				return
+			}
+			c.DrawText(x, r.Y, text, l.color(i))
+			x += TextWidth(l.item(i)) + listGap
+		}
+		return
+	}
+
+	// Scroll just far enough to keep the selection in view
+	first := max(0, l.Selected-r.H+1)
+	for row := 0; row < r.H && first+row < len(l.Items); row++ {
+		i := first + row
+		text := l.item(i)
+		if i == l.Selected && l.focused {
+			text = "► " + l.Items[i]
+		}
+		drawLine(c, r.Row(row), text, l.Align, l.color(i))
+	}
+}
+
+func (l *List) HandleAction(a input.Action) bool {
+	if len(l.Items) == 0 {
+		return false
+	}
+	prev, next := input.ActionMoveNorth, input.ActionMoveSouth
+	if l.Horizontal {
+		prev, next = input.ActionMoveWest, input.ActionMoveEast
+	}
+
+	switch a {
+	case prev:
+		l.Selected = (l.Selected + len(l.Items) - 1) % len(l.Items)
+	case next:
+		l.Selected = (l.Selected + 1) % len(l.Items)
+	case input.ActionInteract:
+		if l.OnActivate != nil {
+			l.OnActivate(l.Selected)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (l *List) SetFocused(focused bool) {
+	l.focused = focused
+}
+
+// Dialog is a modal question with a row of buttons under it.
+type Dialog struct {
+	Panel   Panel
+	Text    Label
+	Buttons List
+}
+
+// NewDialog builds a dialog, calling onChoose with the button picked.
+func NewDialog(title, text string, buttons []string, onChoose func(index int)) *Dialog {
+	d := &Dialog{
+		Panel:   Panel{Title: title, Color: core.Yellow, Background: core.Black, Padding: 2},
+		Text:    Label{Text: text, Color: core.White, Align: AlignCenter},
+		Buttons: List{Items: buttons, Color: core.Gray, SelectedColor: core.Yellow, Horizontal: true, Align: AlignCenter, OnActivate: onChoose},
+	}
+	d.Panel.Child = &Column{Children: []Widget{&d.Text, &d.Buttons}, Gap: 1}
+	return d
+}
+
+func (d *Dialog) Size() (int, int) {
+	return d.Panel.Size()
+}
+
+func (d *Dialog) Draw(c Canvas, r Rect) {
+	d.Panel.Draw(c, r)
+}
+
+func (d *Dialog) HandleAction(a input.Action) bool {
+	return d.Buttons.HandleAction(a)
+}
+
+func (d *Dialog) SetFocused(focused bool) {
+	d.Buttons.SetFocused(focused)
+}
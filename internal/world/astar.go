@@ -1,103 +1,195 @@
 package world
 
 import (
-	"container/heap"
+	"slices"
 
 	"github.com/vikash-paf/derelict-facility/internal/algo"
 	"github.com/vikash-paf/derelict-facility/internal/entity"
+	"github.com/vikash-paf/derelict-facility/internal/math"
 )
 
-// Pathfinder holds reusable buffers for A* pathfinding to avoid allocations.
+// Step costs in FindPathWithOptions. A diagonal step is about √2 times a straight one,
+// scaled up so the costs stay integers.
+const (
+	StraightCost = 10
+	DiagonalCost = 14
+)
+
+// Heuristic estimates the cost left from a tile to the target.
+type Heuristic uint8
+
+const (
+	HeuristicAuto      Heuristic = iota // Manhattan for 4-way movement, Octile for 8-way
+	HeuristicManhattan                  // Exact for 4-way movement on an open floor
+	HeuristicOctile                     // Exact for 8-way movement with diagonals costing DiagonalCost
+	HeuristicChebyshev                  // Counts diagonals as straight steps, weaker but never overestimates
+)
+
+// PathOptions tunes how FindPathWithOptions moves. The zero value is 4-way movement where every
+// step costs the same, which is what FindPath does.
+type PathOptions struct {
+	Diagonal   bool // Allow the four diagonal steps as well
+	CutCorners bool // Let a diagonal step brush past one blocked corner, it never squeezes between two
+	Heuristic  Heuristic
+
+	// Cost is the extra cost of stepping onto a tile, in straight steps. It may be nil.
+	Cost func(x, y int) int
+}
+
+// CanStep reports whether the options allow moving from (x, y) by (dx, dy), each -1, 0 or 1.
+// Diagonal steps need the two tiles they pass between to be open, or just one with CutCorners.
+func (o PathOptions) CanStep(x, y, dx, dy int, isWalkable func(x, y int) bool) bool {
+	if !isWalkable(x+dx, y+dy) {
+		return false
+	}
+	if dx == 0 || dy == 0 {
+		return true
+	}
+	if !o.Diagonal {
+		return false
+	}
+
+	side1, side2 := isWalkable(x+dx, y), isWalkable(x, y+dy)
+	if o.CutCorners {
+		return side1 || side2
+	}
+	return side1 && side2
+}
+
+// estimate is the heuristic cost from a to b, in the same units as the step costs.
+func (o PathOptions) estimate(a, b entity.Point) int {
+	dx, dy := math.Abs(a.X-b.X), math.Abs(a.Y-b.Y)
+
+	h := o.Heuristic
+	if h == HeuristicAuto {
+		h = HeuristicManhattan
+		if o.Diagonal {
+			h = HeuristicOctile
+		}
+	}
+
+	switch h {
+	case HeuristicOctile:
+		return StraightCost*(dx+dy) + (DiagonalCost-2*StraightCost)*min(dx, dy)
+	case HeuristicChebyshev:
+		return StraightCost * max(dx, dy)
+	default:
+		return StraightCost * (dx + dy)
+	}
+}
+
+// Neighbour offsets, orthogonal first so FindPath only has to look at the front of the list
+var (
+	stepDX = [8]int{0, 0, 1, -1, 1, 1, -1, -1}
+	stepDY = [8]int{-1, 1, 0, 0, -1, 1, 1, -1}
+)
+
+// Pathfinder holds reusable buffers for pathfinding, so a search allocates nothing but the path it returns.
 type Pathfinder struct {
-	closedSet      []uint64
-	openSetTracker []*algo.Node
-	openSet        algo.PriorityQueue
-	generation     uint64
+	nodes *algo.NodePool // A* and jump search nodes, one per tile
 }
 
 // NewPathfinder initializes the buffers for a map of the given dimensions.
 func NewPathfinder(width, height int) *Pathfinder {
 	mapArea := width * height
 	return &Pathfinder{
-		closedSet:      make([]uint64, mapArea),
-		openSetTracker: make([]*algo.Node, mapArea),
-		openSet:        make(algo.PriorityQueue, 0, 100),
-		generation:     0,
+		nodes: algo.NewNodePool(mapArea),
 	}
 }
 
+// FindPath finds the shortest 4-way path from start to target, both ends included.
 func (pf *Pathfinder) FindPath(m *Map, start, target entity.Point, isWalkable func(x, y int) bool) []entity.Point {
+	return pf.FindPathInto(nil, m, start, target, isWalkable, PathOptions{})
+}
+
+// FindPathWithOptions finds the cheapest path from start to target, both ends included, moving and
+// weighing steps the way opts says. Returns nil if the target can't be reached.
+// 4-way searches where every step costs the same use Jump Point Search, everything else plain A*.
+func (pf *Pathfinder) FindPathWithOptions(m *Map, start, target entity.Point, isWalkable func(x, y int) bool, opts PathOptions) []entity.Point {
+	return pf.FindPathInto(nil, m, start, target, isWalkable, opts)
+}
+
+// FindPathInto is FindPathWithOptions writing the path over dst, growing it only if it's too short.
+// Callers that keep dst around between searches don't allocate at all.
+func (pf *Pathfinder) FindPathInto(dst []entity.Point, m *Map, start, target entity.Point, isWalkable func(x, y int) bool, opts PathOptions) []entity.Point {
 	// 1. Initial Validation
 	if !isWalkable(target.X, target.Y) {
 		return nil
 	}
 
-	pf.generation++
-	pf.openSet = pf.openSet[:0] // Clear queue without reallocating
-	clear(pf.openSetTracker)    // Quickly nil out all pointers
-
-	// Start GCost must be 0 (the distance from start to start is zero)
-	startNode := &algo.Node{
-		Point: start,
-		GCost: 0,
-		HCost: ManhattanDistance(start, target),
+	if !opts.Diagonal && opts.Cost == nil {
+		return pf.findJumpPath(dst, m, start, target, isWalkable, opts)
 	}
-	startNode.FCost = startNode.GCost + startNode.HCost
+	return pf.findAStarPath(dst, m, start, target, isWalkable, opts)
+}
 
-	heap.Push(&pf.openSet, startNode)
-	pf.openSetTracker[m.GetIndexFromPoint(start)] = startNode
+// findAStarPath is FindPathInto for any options, looking at every tile it passes.
+func (pf *Pathfinder) findAStarPath(dst []entity.Point, m *Map, start, target entity.Point, isWalkable func(x, y int) bool, opts PathOptions) []entity.Point {
+	nodes := pf.nodes
+	nodes.Reset()
 
-	for pf.openSet.Len() > 0 {
-		currentNode := heap.Pop(&pf.openSet).(*algo.Node)
-		currIdx := m.GetIndexFromPoint(currentNode.Point)
+	// Start GCost must be 0 (the distance from start to start is zero)
+	nodes.Push(m.GetIndexFromPoint(start), 0, opts.estimate(start, target), -1)
 
-		if currentNode.Point == target {
-			return reconstructPath(currentNode)
-		}
+	directions := 4
+	if opts.Diagonal {
+		directions = 8
+	}
 
-		pf.openSetTracker[currIdx] = nil
-		pf.closedSet[currIdx] = pf.generation
+	for nodes.Len() > 0 {
+		currIdx := nodes.Pop()
+		cx, cy := currIdx%m.Width, currIdx/m.Width
 
-		// Orthogonal neighbors (N, S, E, W)
-		dx := []int{0, 0, 1, -1}
-		dy := []int{-1, 1, 0, 0}
+		if cx == target.X && cy == target.Y {
+			return pf.reconstructPath(dst, m.Width, currIdx)
+		}
 
-		for i := 0; i < 4; i++ {
-			nx, ny := currentNode.Point.X+dx[i], currentNode.Point.Y+dy[i]
+		gCost := nodes.Nodes[currIdx].GCost
+		for i := 0; i < directions; i++ {
+			nx, ny := cx+stepDX[i], cy+stepDY[i]
 
-			// Boundary and Walkability check using callback
-			if !isWalkable(nx, ny) {
+			// Boundary, walkability and corner rules using the callback
+			if !opts.CanStep(cx, cy, stepDX[i], stepDY[i], isWalkable) {
 				continue
 			}
 
 			nIdx := m.GetIndex(nx, ny)
-			if pf.closedSet[nIdx] == pf.generation {
+			if nodes.Closed(nIdx) {
 				continue
 			}
 
-			newGCost := currentNode.GCost + 1
-			neighborNode := pf.openSetTracker[nIdx]
+			stepCost := StraightCost
+			if i >= 4 {
+				stepCost = DiagonalCost
+			}
+			if opts.Cost != nil {
+				stepCost += StraightCost * opts.Cost(nx, ny)
+			}
 
-			if neighborNode == nil {
+			newGCost := gCost + stepCost
+			if !nodes.Seen(nIdx) {
 				// Discover new node
-				newNode := &algo.Node{
-					Point:  entity.Point{X: nx, Y: ny},
-					Parent: currentNode,
-					GCost:  newGCost,
-					HCost:  ManhattanDistance(entity.Point{X: nx, Y: ny}, target),
-				}
-				newNode.FCost = newNode.GCost + newNode.HCost
-				heap.Push(&pf.openSet, newNode)
-				pf.openSetTracker[nIdx] = newNode
-			} else if newGCost < neighborNode.GCost {
+				nodes.Push(nIdx, newGCost, opts.estimate(entity.Point{X: nx, Y: ny}, target), int32(currIdx))
+			} else if newGCost < nodes.Nodes[nIdx].GCost {
 				// Found a more optimal path to a node already in the Open Set
-				neighborNode.Parent = currentNode
-				neighborNode.GCost = newGCost
-				neighborNode.FCost = newGCost + neighborNode.HCost
-				heap.Fix(&pf.openSet, neighborNode.HeapIndex)
+				nodes.Improve(nIdx, newGCost, int32(currIdx))
 			}
 		}
 	}
 
 	return nil
 }
+
+// reconstructPath traces the parents back from the target's node, writing the path over dst.
+func (pf *Pathfinder) reconstructPath(dst []entity.Point, width, end int) []entity.Point {
+	n := 0
+	for i := int32(end); i >= 0; i = pf.nodes.Nodes[i].Parent {
+		n++
+	}
+
+	path := slices.Grow(dst[:0], n)[:n]
+	for i, k := int32(end), n-1; i >= 0; i, k = pf.nodes.Nodes[i].Parent, k-1 {
+		path[k] = entity.Point{X: int(i) % width, Y: int(i) / width}
+	}
+	return path
+}
@@ -98,7 +98,7 @@ func TestFindPath_Regression_HeapIndex(t *testing.T) {
 
 	// We don't need complex logic here; the fact that FindPath runs
 	// without a panic and reaches the target is a good sign for the logic
-	// we implemented with openSetTracker and HeapIndex.
+	// we implemented with the node pool's heap indices.
 	pf := NewPathfinder(m.Width, m.Height)
 	path := pf.FindPath(m, start, target, func(x, y int) bool {
 		return m.IsWalkable(x, y)
@@ -131,3 +131,161 @@ func TestVisual_PathfindingCorridor(t *testing.T) {
 	// This is the visual confirmation
 	VisualizePath(m, path, start, target)
 }
+
+// pathCost adds up what a path costs to walk with 8-way step costs.
+func pathCost(path []entity.Point) int {
+	cost := 0
+	for i := 1; i < len(path); i++ {
+		if path[i].X != path[i-1].X && path[i].Y != path[i-1].Y {
+			cost += DiagonalCost
+		} else {
+			cost += StraightCost
+		}
+	}
+	return cost
+}
+
+func TestFindPathWithOptions_Diagonal(t *testing.T) {
+	m := setupTestMap(10, 10, nil)
+	start, target := entity.Point{X: 1, Y: 1}, entity.Point{X: 6, Y: 6}
+	pf := NewPathfinder(m.Width, m.Height)
+
+	if path := pf.FindPath(m, start, target, m.IsWalkable); len(path) != 11 {
+		t.Errorf("Expected 4-way movement to take 11 tiles, got %d", len(path))
+	}
+
+	path := pf.FindPathWithOptions(m, start, target, m.IsWalkable, PathOptions{Diagonal: true})
+	if len(path) != 6 {
+		t.Errorf("Expected 8-way movement to go straight across in 6 tiles, got %d: %v", len(path), path)
+	}
+}
+
+func TestPathOptions_CanStep(t *testing.T) {
+	// The step under test is always from (1,1) to (2,2)
+	tests := []struct {
+		name  string
+		walls []entity.Point
+		opts  PathOptions
+		want  bool
+	}{
+		{"no diagonals", nil, PathOptions{}, false},
+		{"open corner", nil, PathOptions{Diagonal: true}, true},
+		{"one corner blocked", []entity.Point{{X: 2, Y: 1}}, PathOptions{Diagonal: true}, false},
+		{"one corner cut", []entity.Point{{X: 2, Y: 1}}, PathOptions{Diagonal: true, CutCorners: true}, true},
+		{"squeezing between two", []entity.Point{{X: 2, Y: 1}, {X: 1, Y: 2}}, PathOptions{Diagonal: true, CutCorners: true}, false},
+		{"into a wall", []entity.Point{{X: 2, Y: 2}}, PathOptions{Diagonal: true, CutCorners: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := setupTestMap(4, 4, tt.walls)
+			if got := tt.opts.CanStep(1, 1, 1, 1, m.IsWalkable); got != tt.want {
+				t.Errorf("CanStep = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPathWithOptions_HeuristicsAgree(t *testing.T) {
+	m := newTestFacility()
+	start, target := entity.Point{X: 1, Y: 1}, entity.Point{X: 9, Y: 9}
+	pf := NewPathfinder(m.Width, m.Height)
+
+	var costs []int
+	for _, h := range []Heuristic{HeuristicOctile, HeuristicChebyshev} {
+		path := pf.FindPathWithOptions(m, start, target, m.IsWalkable, PathOptions{Diagonal: true, Heuristic: h})
+		if len(path) == 0 || path[len(path)-1] != target {
+			t.Fatalf("Heuristic %d found no path", h)
+		}
+		costs = append(costs, pathCost(path))
+	}
+
+	// Both never overestimate, so both find the cheapest route
+	if costs[0] != costs[1] {
+		t.Errorf("Expected octile and Chebyshev to agree on the cost, got %v", costs)
+	}
+}
+
+func TestMap_HazardCost(t *testing.T) {
+	m := setupTestMap(3, 1, nil)
+	m.Tiles[1].Variant = 2
+	m.EnableWater()
+	m.Water.Pour(m, 2, 0, 1)
+
+	if got := m.HazardCost(0, 0); got != 0 {
+		t.Errorf("Expected a bare floor to cost nothing, got %d", got)
+	}
+	if got := m.HazardCost(1, 0); got != 1 {
+		t.Errorf("Expected debris to cost 1, got %d", got)
+	}
+	if got := m.HazardCost(2, 0); got != m.WaterCost(2, 0) || got == 0 {
+		t.Errorf("Expected deep water to cost its depth, got %d", got)
+	}
+}
+
+// BenchmarkFindPath_AStar runs plain A* across a generated facility, 4-way and 8-way with hazard costs.
+// The node pool leaves the path as the only allocation, which the Into cases reuse.
+func BenchmarkFindPath_AStar(b *testing.B) {
+	m, px, py := NewFacilityGenerator(12345).Generate(400, 200)
+	fx, fy := m.Rooms[len(m.Rooms)-1].Center()
+	start, target := entity.Point{X: px, Y: py}, entity.Point{X: fx, Y: fy}
+	pf := NewPathfinder(m.Width, m.Height)
+
+	cases := []struct {
+		name string
+		opts PathOptions
+	}{
+		{"FourWay", PathOptions{}},
+		{"Diagonal", PathOptions{Diagonal: true, Cost: m.HazardCost}},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if pf.FindPathWithOptions(m, start, target, m.IsWalkable, c.opts) == nil {
+					b.Fatal("no path")
+				}
+			}
+		})
+		b.Run(c.name+"Into", func(b *testing.B) {
+			b.ReportAllocs()
+			var path []entity.Point
+			for i := 0; i < b.N; i++ {
+				if path = pf.FindPathInto(path, m, start, target, m.IsWalkable, c.opts); path == nil {
+					b.Fatal("no path")
+				}
+			}
+		})
+	}
+}
+
+func TestFindPathInto_NoAllocations(t *testing.T) {
+	m := newTestFacility()
+	pf := NewPathfinder(m.Width, m.Height)
+	start, target := entity.Point{X: 1, Y: 1}, entity.Point{X: 8, Y: 9}
+	isWalkable := m.IsWalkable
+
+	cases := []struct {
+		name string
+		opts PathOptions
+	}{
+		{"JPS", PathOptions{}},
+		{"Diagonal", PathOptions{Diagonal: true, Cost: m.DebrisCost}},
+		{"AStar", PathOptions{Cost: m.DebrisCost}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := pf.FindPathInto(nil, m, start, target, isWalkable, tc.opts)
+			if len(path) == 0 || path[0] != start || path[len(path)-1] != target {
+				t.Fatalf("Expected a path from %v to %v, got %v", start, target, path)
+			}
+
+			allocs := testing.AllocsPerRun(50, func() {
+				path = pf.FindPathInto(path, m, start, target, isWalkable, tc.opts)
+			})
+			if allocs != 0 {
+				t.Errorf("Expected no allocations reusing the path, got %v", allocs)
+			}
+		})
+	}
+}
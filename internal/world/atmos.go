@@ -0,0 +1,165 @@
+package world
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+/*
+	The atmosphere is three gas layers over the map, all normalised so 1.0 is what a healthy
+	facility holds: oxygen, pressure and toxins. Every tick each open tile swaps a share of the
+	difference with its open neighbours (plain diffusion, which conserves the total), then breaches
+	bleed their tiles out into space.
+
+	Layers are kept as flat float32 arrays and double-buffered, so a step is a couple of linear
+	passes over memory with no allocation. That is what keeps a 400x200 map cheap.
+*/
+
+const (
+	diffusionRate = 0.2 // Share of the difference exchanged with each neighbour per tick, stable up to 0.25
+	ventRate      = 0.5 // Share of a breached tile's gas lost to space per tick
+)
+
+// Gas is the air on a single tile.
+type Gas struct {
+	O2       float32
+	Pressure float32
+	Toxins   float32
+}
+
+// Atmosphere holds the gas layers of a map as a structure of arrays.
+type Atmosphere struct {
+	O2       []float32
+	Pressure []float32
+	Toxins   []float32
+
+	nextO2, nextPressure, nextToxins []float32
+	open                             []bool // Tiles air can move through this tick
+	breaches                         []int
+}
+
+// EnableAtmosphere fills every walkable tile with breathable air and starts venting the map's breaches.
+func (m *Map) EnableAtmosphere() {
+	n := m.Width * m.Height
+	a := &Atmosphere{
+		O2:           make([]float32, n),
+		Pressure:     make([]float32, n),
+		Toxins:       make([]float32, n),
+		nextO2:       make([]float32, n),
+		nextPressure: make([]float32, n),
+		nextToxins:   make([]float32, n),
+		open:         make([]bool, n),
+	}
+
+	for i, tile := range m.Tiles {
+		if tile.Walkable {
+			a.O2[i] = 1
+			a.Pressure[i] = 1
+		}
+	}
+	for _, b := range m.Breaches {
+		if m.GetTile(b.X, b.Y) != nil {
+			a.breaches = append(a.breaches, m.GetIndex(b.X, b.Y))
+		}
+	}
+
+	m.Air = a
+}
+
+// GasAt returns the air on a tile, or vacuum if it's off the map or the atmosphere is disabled.
+func (m *Map) GasAt(x, y int) Gas {
+	if m.Air == nil || x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return Gas{}
+	}
+	i := m.GetIndex(x, y)
+	return Gas{O2: m.Air.O2[i], Pressure: m.Air.Pressure[i], Toxins: m.Air.Toxins[i]}
+}
+
+// Emit pumps gas into a tile. Oxygen only tops the tile up to normal, like a life support vent would;
+// toxins are added as they are, and a negative amount scrubs them out. Pressure follows the change.
+func (a *Atmosphere) Emit(m *Map, x, y int, o2, toxins float32) {
+	if m.GetTile(x, y) == nil {
+		return
+	}
+	i := m.GetIndex(x, y)
+
+	addedO2 := min(o2, max(0, 1-a.O2[i]))
+	addedToxins := max(toxins, -a.Toxins[i])
+	a.O2[i] += addedO2
+	a.Toxins[i] += addedToxins
+	a.Pressure[i] = max(0, a.Pressure[i]+addedO2+addedToxins)
+}
+
+// Step advances the air by one tick. blocked marks tiles that stop the air on top of walls,
+// like closed doors; it may be nil. A blocked tile keeps whatever gas was trapped in it.
+func (a *Atmosphere) Step(m *Map, blocked []bool) {
+	w, h := m.Width, m.Height
+
+	for i, tile := range m.Tiles {
+		a.open[i] = tile.Walkable && (blocked == nil || !blocked[i])
+	}
+
+	for y := 0; y < h; y++ {
+		row := y * w
+		for x := 0; x < w; x++ {
+			i := row + x
+			o2, p, tox := a.O2[i], a.Pressure[i], a.Toxins[i]
+			if !a.open[i] {
+				a.nextO2[i], a.nextPressure[i], a.nextToxins[i] = o2, p, tox
+				continue
+			}
+
+			var dO2, dP, dTox float32
+			if x > 0 && a.open[i-1] {
+				dO2 += a.O2[i-1] - o2
+				dP += a.Pressure[i-1] - p
+				dTox += a.Toxins[i-1] - tox
+			}
+			if x < w-1 && a.open[i+1] {
+				dO2 += a.O2[i+1] - o2
+				dP += a.Pressure[i+1] - p
+				dTox += a.Toxins[i+1] - tox
+			}
+			if y > 0 && a.open[i-w] {
+				dO2 += a.O2[i-w] - o2
+				dP += a.Pressure[i-w] - p
+				dTox += a.Toxins[i-w] - tox
+			}
+			if y < h-1 && a.open[i+w] {
+				dO2 += a.O2[i+w] - o2
+				dP += a.Pressure[i+w] - p
+				dTox += a.Toxins[i+w] - tox
+			}
+
+			a.nextO2[i] = o2 + diffusionRate*dO2
+			a.nextPressure[i] = p + diffusionRate*dP
+			a.nextToxins[i] = tox + diffusionRate*dTox
+		}
+	}
+
+	// Breaches are open to space, a sealed door in front of one holds the air back
+	for _, i := range a.breaches {
+		if !a.open[i] {
+			continue
+		}
+		a.nextO2[i] *= 1 - ventRate
+		a.nextPressure[i] *= 1 - ventRate
+		a.nextToxins[i] *= 1 - ventRate
+	}
+
+	a.O2, a.nextO2 = a.nextO2, a.O2
+	a.Pressure, a.nextPressure = a.nextPressure, a.Pressure
+	a.Toxins, a.nextToxins = a.nextToxins, a.Toxins
+}
+
+// placeBreaches tears a hole in the hull in a corner of the airlock, if the facility has one.
+func placeBreaches(m *Map) []entity.Point {
+	if m.Graph == nil {
+		return nil
+	}
+	var breaches []entity.Point
+	for _, room := range m.Graph.RoomsOfType(RoomTypeAirlock) {
+		r := m.Rooms[room]
+		breaches = append(breaches, entity.Point{X: r.X1, Y: r.Y1})
+	}
+	return breaches
+}
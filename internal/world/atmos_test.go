@@ -0,0 +1,119 @@
+package world
+
+import (
+	"math"
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+func totalGas(layer []float32) float64 {
+	var sum float64
+	for _, v := range layer {
+		sum += float64(v)
+	}
+	return sum
+}
+
+func TestAtmosphere_DiffusionConservesGas(t *testing.T) {
+	m := newTestMap(`
+##########
+#........#
+#........#
+##########`)
+	m.EnableAtmosphere()
+	m.Air.Emit(m, 1, 1, 0, 4)
+
+	before := totalGas(m.Air.Toxins)
+	for i := 0; i < 500; i++ {
+		m.Air.Step(m, nil)
+	}
+	after := totalGas(m.Air.Toxins)
+
+	if math.Abs(before-after) > 1e-3 {
+		t.Errorf("Expected a sealed room to keep its toxins, went from %v to %v", before, after)
+	}
+
+	// After long enough the toxins are spread evenly over the 16 floor tiles
+	for _, p := range []entity.Point{{X: 1, Y: 1}, {X: 8, Y: 2}} {
+		if got := m.GasAt(p.X, p.Y).Toxins; math.Abs(float64(got)-0.25) > 0.01 {
+			t.Errorf("Expected (%d,%d) to settle at 0.25 toxins, got %v", p.X, p.Y, got)
+		}
+	}
+	if got := m.GasAt(0, 0).Toxins; got != 0 {
+		t.Errorf("Expected no gas inside the walls, got %v", got)
+	}
+}
+
+func TestAtmosphere_ClosedDoorSealsBreach(t *testing.T) {
+	m := newTestMap(`
+###########
+#....#....#
+#.........#
+#....#....#
+###########`)
+	m.Breaches = []entity.Point{{X: 9, Y: 2}}
+	m.EnableAtmosphere()
+
+	door := m.GetIndex(5, 2)
+	blocked := make([]bool, m.Width*m.Height)
+	blocked[door] = true
+
+	for i := 0; i < 200; i++ {
+		m.Air.Step(m, blocked)
+	}
+	if got := m.GasAt(2, 2); got.O2 != 1 || got.Pressure != 1 {
+		t.Errorf("Expected the sealed room to keep its air, got %+v", got)
+	}
+	if got := m.GasAt(8, 2); got.Pressure > 0.1 {
+		t.Errorf("Expected the breached room to vent, got %+v", got)
+	}
+
+	// Open the door and the left room bleeds out too
+	blocked[door] = false
+	for i := 0; i < 1000; i++ {
+		m.Air.Step(m, blocked)
+	}
+	if got := m.GasAt(2, 2); got.Pressure > 0.1 || got.O2 > 0.1 {
+		t.Errorf("Expected the left room to vent through the open door, got %+v", got)
+	}
+}
+
+func TestAtmosphere_Emit(t *testing.T) {
+	m := newTestMap(`
+...
+...`)
+	m.EnableAtmosphere()
+	m.Air.O2[0] = 0.8
+	m.Air.Pressure[0] = 0.8
+
+	// Oxygen only tops up to normal
+	m.Air.Emit(m, 0, 0, 0.5, 0)
+	if got := m.GasAt(0, 0); got.O2 != 1 || math.Abs(float64(got.Pressure)-1) > 1e-6 {
+		t.Errorf("Expected O2 topped up to 1, got %+v", got)
+	}
+
+	// Scrubbing can't take toxins below zero
+	m.Air.Emit(m, 1, 0, 0, 0.2)
+	m.Air.Emit(m, 1, 0, 0, -0.5)
+	if got := m.GasAt(1, 0); got.Toxins != 0 || math.Abs(float64(got.Pressure)-1) > 1e-6 {
+		t.Errorf("Expected the toxins scrubbed back out, got %+v", got)
+	}
+
+	// Off the map is ignored
+	m.Air.Emit(m, -1, 5, 1, 1)
+}
+
+func TestFacilityGenerator_BreachesTheAirlock(t *testing.T) {
+	m, _, _ := NewFacilityGenerator(12345).Generate(120, 40)
+
+	airlocks := m.Graph.RoomsOfType(RoomTypeAirlock)
+	if len(m.Breaches) != len(airlocks) {
+		t.Fatalf("Expected one breach per airlock, got %d breaches for %d airlocks", len(m.Breaches), len(airlocks))
+	}
+	for _, b := range m.Breaches {
+		if room := m.Graph.RoomAt(b.X, b.Y); room < 0 || m.Graph.Types[room] != RoomTypeAirlock {
+			t.Errorf("Expected breach %v inside an airlock", b)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package world
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+	"github.com/vikash-paf/derelict-facility/internal/math"
+)
+
+// maxRepairPasses bounds how many times the generator tries to stitch unreachable regions back
+// to the spawn. Every pass connects at least one region, so this is only a safety net.
+const maxRepairPasses = 64
+
+// Region is a connected pocket of walkable tiles that the spawn point cannot reach.
+type Region struct {
+	Start entity.Point // Any tile inside the region, used as the anchor for repairs
+	Size  int          // Number of walkable tiles in the region
+}
+
+// ConnectivityReport describes how much of a map can be reached from the spawn point.
+type ConnectivityReport struct {
+	Walkable           int            // Total walkable tiles on the map
+	Reachable          int            // Walkable tiles reachable from the spawn
+	SpawnBlocked       bool           // The spawn tile is not walkable, or a door sits on top of it
+	UnreachableRooms   []int          // Indices into Map.Rooms whose centre can't be reached
+	UnreachableRegions []Region       // Every walkable pocket cut off from the spawn
+	DeadEnds           []entity.Point // Walkable tiles with exactly one walkable orthogonal neighbour
+}
+
+// Connected reports whether the player can walk from the spawn to every walkable tile.
+func (r ConnectivityReport) Connected() bool {
+	return !r.SpawnBlocked && len(r.UnreachableRegions) == 0
+}
+
+// FloodFill marks every tile reachable from (startX, startY) in reached and returns how many there are.
+// reached must hold Width*Height entries; it is cleared before the fill so it can be reused between calls.
+func FloodFill(m *Map, startX, startY int, passable func(x, y int) bool, reached []bool) int {
+	clear(reached)
+	if !passable(startX, startY) {
+		return 0
+	}
+
+	queue := make([]entity.Point, 0, 64)
+	queue = append(queue, entity.Point{X: startX, Y: startY})
+	reached[m.GetIndex(startX, startY)] = true
+
+	// Orthogonal neighbours only, matching how the player and the Pathfinder move
+	dx := [4]int{0, 0, 1, -1}
+	dy := [4]int{-1, 1, 0, 0}
+
+	for head := 0; head < len(queue); head++ {
+		p := queue[head]
+		for i := 0; i < 4; i++ {
+			nx, ny := p.X+dx[i], p.Y+dy[i]
+			if nx < 0 || nx >= m.Width || ny < 0 || ny >= m.Height {
+				continue
+			}
+			idx := m.GetIndex(nx, ny)
+			if reached[idx] || !passable(nx, ny) {
+				continue
+			}
+			reached[idx] = true
+			queue = append(queue, entity.Point{X: nx, Y: ny})
+		}
+	}
+
+	return len(queue)
+}
+
+// ValidateConnectivity flood-fills from the spawn point and reports every room, region and dead end
+// the player can't get to. Doors are treated as passable since the player can always open them.
+func ValidateConnectivity(m *Map, spawnX, spawnY int) ConnectivityReport {
+	var report ConnectivityReport
+
+	reached := make([]bool, m.Width*m.Height)
+	report.Reachable = FloodFill(m, spawnX, spawnY, m.IsWalkable, reached)
+	report.SpawnBlocked = !m.IsWalkable(spawnX, spawnY)
+
+	for _, door := range m.Doors {
+		if door.X == spawnX && door.Y == spawnY {
+			report.SpawnBlocked = true
+		}
+	}
+
+	for i, room := range m.Rooms {
+		cx, cy := room.Center()
+		if !reached[m.GetIndex(cx, cy)] {
+			report.UnreachableRooms = append(report.UnreachableRooms, i)
+		}
+	}
+
+	// Every walkable tile the first fill missed belongs to an unreachable region.
+	// Fill each of them once so we can report their size.
+	pocket := make([]bool, m.Width*m.Height)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if !m.IsWalkable(x, y) {
+				continue
+			}
+			report.Walkable++
+
+			if isDeadEnd(m, x, y) {
+				report.DeadEnds = append(report.DeadEnds, entity.Point{X: x, Y: y})
+			}
+
+			idx := m.GetIndex(x, y)
+			if reached[idx] {
+				continue
+			}
+
+			size := FloodFill(m, x, y, m.IsWalkable, pocket)
+			for i, inPocket := range pocket {
+				if inPocket {
+					reached[i] = true // Don't report the same pocket twice
+				}
+			}
+			report.UnreachableRegions = append(report.UnreachableRegions, Region{
+				Start: entity.Point{X: x, Y: y},
+				Size:  size,
+			})
+		}
+	}
+
+	return report
+}
+
+// isDeadEnd reports whether a walkable tile has exactly one walkable orthogonal neighbour.
+func isDeadEnd(m *Map, x, y int) bool {
+	neighbours := 0
+	if m.IsWalkable(x, y-1) {
+		neighbours++
+	}
+	if m.IsWalkable(x+1, y) {
+		neighbours++
+	}
+	if m.IsWalkable(x, y+1) {
+		neighbours++
+	}
+	if m.IsWalkable(x-1, y) {
+		neighbours++
+	}
+	return neighbours == 1
+}
+
+// repairConnectivity carves extra L-corridors from every unreachable region to the closest
+// reachable tile until the whole map can be walked from the spawn.
+func (f *FacilityGenerator) repairConnectivity(m *Map, spawnX, spawnY int) ConnectivityReport {
+	report := ValidateConnectivity(m, spawnX, spawnY)
+
+	reached := make([]bool, m.Width*m.Height)
+	for pass := 0; pass < maxRepairPasses && len(report.UnreachableRegions) > 0; pass++ {
+		FloodFill(m, spawnX, spawnY, m.IsWalkable, reached)
+
+		for _, region := range report.UnreachableRegions {
+			target, ok := nearestReached(m, region.Start, reached)
+			if !ok {
+				continue
+			}
+
+			if f.rng.IntN(2) == 1 {
+				f.createHorizontalCorridor(m, region.Start.X, target.X, region.Start.Y)
+				f.createVerticalCorridor(m, region.Start.Y, target.Y, target.X)
+			} else {
+				f.createVerticalCorridor(m, region.Start.Y, target.Y, region.Start.X)
+				f.createHorizontalCorridor(m, region.Start.X, target.X, target.Y)
+			}
+		}
+
+		report = ValidateConnectivity(m, spawnX, spawnY)
+	}
+
+	return report
+}
+
+// nearestReached finds the reached tile closest (Manhattan) to p.
+func nearestReached(m *Map, p entity.Point, reached []bool) (entity.Point, bool) {
+	best := entity.Point{}
+	bestDist := -1
+	for i, ok := range reached {
+		if !ok {
+			continue
+		}
+		x, y := i%m.Width, i/m.Width
+		dist := math.Abs(x-p.X) + math.Abs(y-p.Y)
+		if bestDist < 0 || dist < bestDist {
+			best = entity.Point{X: x, Y: y}
+			bestDist = dist
+		}
+	}
+	return best, bestDist >= 0
+}
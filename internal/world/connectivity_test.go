@@ -0,0 +1,131 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+func TestValidateConnectivity(t *testing.T) {
+	m := newTestMap(`
+#########
+#...#...#
+#...#...#
+#.......#
+#########
+#..######
+#########`)
+	m.Rooms = []Rect{
+		{X1: 1, Y1: 1, X2: 3, Y2: 3},
+		{X1: 5, Y1: 1, X2: 7, Y2: 3},
+		{X1: 1, Y1: 5, X2: 2, Y2: 5}, // Sealed off behind the wall on row 4
+	}
+
+	report := ValidateConnectivity(m, 2, 2)
+
+	if report.Connected() {
+		t.Fatalf("Expected the map to be disconnected\n%s", InspectVisibility(m, 2, 2))
+	}
+	if len(report.UnreachableRooms) != 1 || report.UnreachableRooms[0] != 2 {
+		t.Errorf("Expected room 2 to be unreachable, got %v", report.UnreachableRooms)
+	}
+	if len(report.UnreachableRegions) != 1 || report.UnreachableRegions[0].Size != 2 {
+		t.Errorf("Expected a single 2-tile region, got %+v", report.UnreachableRegions)
+	}
+	if report.Walkable != report.Reachable+2 {
+		t.Errorf("Expected %d reachable tiles, got %d", report.Walkable-2, report.Reachable)
+	}
+
+	// The sealed room is a 2-tile pocket, so both of its tiles are dead ends
+	wantDeadEnds := map[entity.Point]bool{{X: 1, Y: 5}: true, {X: 2, Y: 5}: true}
+	for _, p := range report.DeadEnds {
+		delete(wantDeadEnds, p)
+	}
+	if len(wantDeadEnds) != 0 {
+		t.Errorf("Missing dead ends %v, got %v", wantDeadEnds, report.DeadEnds)
+	}
+}
+
+func TestValidateConnectivity_DoorOnSpawn(t *testing.T) {
+	m := newTestMap(`
+#####
+#...#
+#####`)
+	m.Doors = []entity.Point{{X: 2, Y: 1}}
+
+	report := ValidateConnectivity(m, 2, 1)
+	if !report.SpawnBlocked || report.Connected() {
+		t.Errorf("Expected a door on the spawn to be reported as blocking, got %+v", report)
+	}
+}
+
+func TestFacilityGenerator_repairConnectivity(t *testing.T) {
+	m := newTestMap(`
+##########
+#..#######
+#..#######
+##########
+#######..#
+#######..#
+##########`)
+
+	fg := NewFacilityGenerator(42)
+	report := fg.repairConnectivity(m, 1, 1)
+
+	if !report.Connected() {
+		t.Fatalf("Expected repair to connect the map, got %+v\n%s", report, InspectVisibility(m, 1, 1))
+	}
+	if !isCorridorConnected(m, 1, 1, 8, 5) {
+		t.Errorf("Expected a corridor between the two pockets\n%s", InspectVisibility(m, 1, 1))
+	}
+}
+
+func TestFacilityGenerator_AlwaysConnected(t *testing.T) {
+	sizes := []struct{ width, height int }{
+		{roomMinSize, roomMinSize},
+		{7, 5},
+		{20, roomMinSize},
+		{40, 20},
+		{120, 40},
+	}
+
+	seeds := 3000
+	if testing.Short() {
+		seeds = 200
+	}
+
+	for _, size := range sizes {
+		for seed := 0; seed < seeds; seed++ {
+			fg := NewFacilityGenerator(uint64(seed))
+			m, px, py := fg.Generate(size.width, size.height)
+			if m == nil {
+				t.Fatalf("seed %d (%dx%d): expected a map", seed, size.width, size.height)
+			}
+			if len(m.Rooms) == 0 {
+				t.Fatalf("seed %d (%dx%d): expected at least one room", seed, size.width, size.height)
+			}
+
+			report := ValidateConnectivity(m, px, py)
+			if !report.Connected() {
+				t.Fatalf("seed %d (%dx%d): map is not fully connected: %+v", seed, size.width, size.height, report)
+			}
+		}
+	}
+}
+
+func TestFacilityGenerator_generateReport(t *testing.T) {
+	// Generate trusts the report to decide whether to try the next seed, so it has to match the map
+	for seed := uint64(0); seed < 50; seed++ {
+		m, px, py, report := NewFacilityGenerator(seed).generate(60, 25)
+		want := ValidateConnectivity(m, px, py)
+		if report.Connected() != want.Connected() || len(report.UnreachableRegions) != len(want.UnreachableRegions) {
+			t.Fatalf("seed %d: generate reported %+v, the map has %+v", seed, report, want)
+		}
+
+		// A connected first attempt is kept as it is
+		again, _, _ := NewFacilityGenerator(seed).Generate(60, 25)
+		if report.Connected() && again.Seed != seed {
+			t.Errorf("seed %d: expected the first attempt to be kept, got seed %d", seed, again.Seed)
+		}
+	}
+}
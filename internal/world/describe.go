@@ -0,0 +1,31 @@
+package world
+
+import (
+	"strings"
+)
+
+// DescribeTile names a tile and what's going on there, like "Floor, debris, flooded, burning",
+// for tooltips. Tiles the player hasn't explored are just "Unexplored".
+func (m *Map) DescribeTile(x, y int) string {
+	tile := m.GetTile(x, y)
+	if tile == nil || !tile.Explored {
+		return "Unexplored"
+	}
+
+	parts := []string{tile.Type.Title()}
+	if tile.Type == TileTypeFloor && tile.Variant != 0 {
+		parts = append(parts, "debris")
+	}
+
+	switch depth := m.WaterAt(x, y); {
+	case depth >= ShortCircuitDepth:
+		parts = append(parts, "deep water")
+	case depth >= DryDepth:
+		parts = append(parts, "flooded")
+	}
+	if m.FireAt(x, y) > 0 {
+		parts = append(parts, "burning")
+	}
+
+	return strings.Join(parts, ", ")
+}
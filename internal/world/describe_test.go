@@ -0,0 +1,34 @@
+package world
+
+import (
+	"testing"
+)
+
+func TestMap_DescribeTile(t *testing.T) {
+	m := newTestMap(`
+#....`)
+	for i := range m.Tiles {
+		m.Tiles[i].Explored = i != 4
+	}
+	m.Tiles[2].Variant = 1
+	m.EnableWater()
+	m.Water.Pour(m, 3, 0, ShortCircuitDepth)
+	m.EnableFire(1)
+	m.Fire.Ignite(m, 1, 0)
+
+	cases := []struct {
+		x    int
+		want string
+	}{
+		{0, "Wall"},
+		{1, "Floor, burning"},
+		{2, "Floor, debris"},
+		{3, "Floor, deep water"},
+		{4, "Unexplored"},
+	}
+	for _, tc := range cases {
+		if got := m.DescribeTile(tc.x, 0); got != tc.want {
+			t.Errorf("Tile %d: expected %q, got %q", tc.x, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package world
+
+import (
+	"math"
+
+	"github.com/vikash-paf/derelict-facility/internal/algo"
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+/*
+	A Dijkstra map holds, for every tile, the cost of the cheapest walk to the nearest of a set of
+	goals. Anything standing on it can roll downhill to get to a goal, so a hundred agents chasing
+	the player share one search instead of running a hundred A*s.
+
+	Like Pathfinder, a DijkstraMap is sized for a map once and reuses its buffers, so recomputing
+	it every tick doesn't allocate.
+*/
+
+// Unreachable is the distance of a tile no goal can be walked to from.
+const Unreachable = math.MaxInt
+
+// DefaultFleeScale is how strongly a flee map prefers getting further away over getting out of
+// corners. Anything above 1 lets a cornered agent run past the threat to open space.
+const DefaultFleeScale = 1.2
+
+// DijkstraMap is the walking cost from every tile to the nearest goal.
+type DijkstraMap struct {
+	Dist   []int
+	width  int
+	height int
+	nodes  *algo.NodePool // Open tiles, ordered on their distance with no heuristic
+}
+
+// NewDijkstraMap allocates the buffers for a map of the given dimensions.
+func NewDijkstraMap(width, height int) *DijkstraMap {
+	return &DijkstraMap{
+		Dist:   make([]int, width*height),
+		width:  width,
+		height: height,
+		nodes:  algo.NewNodePool(width * height),
+	}
+}
+
+// Compute fills in the distance from every tile to the nearest goal. Stepping onto a tile costs 1
+// plus whatever cost says, which may be nil. Goals that aren't passable are ignored.
+func (d *DijkstraMap) Compute(goals []entity.Point, passable func(x, y int) bool, cost func(x, y int) int) {
+	d.reset()
+	for _, g := range goals {
+		if g.X < 0 || g.X >= d.width || g.Y < 0 || g.Y >= d.height || !passable(g.X, g.Y) {
+			continue
+		}
+		d.seed(g.Y*d.width+g.X, 0)
+	}
+	d.relax(passable, cost)
+}
+
+// Flee turns another map into one that leads away from its goals: every distance is scaled by
+// -scale and then smoothed out again, so going downhill means getting further from the goals
+// while still steering around dead ends. scale is usually DefaultFleeScale.
+func (d *DijkstraMap) Flee(from *DijkstraMap, scale float64, passable func(x, y int) bool, cost func(x, y int) int) {
+	d.reset()
+	for i, dist := range from.Dist {
+		if dist != Unreachable {
+			d.seed(i, -int(float64(dist)*scale))
+		}
+	}
+	d.relax(passable, cost)
+}
+
+// At is the distance from (x, y) to the nearest goal, Unreachable off the map.
+func (d *DijkstraMap) At(x, y int) int {
+	if x < 0 || x >= d.width || y < 0 || y >= d.height {
+		return Unreachable
+	}
+	return d.Dist[y*d.width+x]
+}
+
+// Descend returns the neighbour of (x, y) that is closest to a goal. Returns false when no
+// neighbour is closer than (x, y) itself, which means it is standing on a goal or can't get to one.
+func (d *DijkstraMap) Descend(x, y int) (entity.Point, bool) {
+	if x < 0 || x >= d.width || y < 0 || y >= d.height {
+		return entity.Point{}, false
+	}
+	i := d.downhill(y*d.width + x)
+	if i < 0 {
+		return entity.Point{}, false
+	}
+	return entity.Point{X: i % d.width, Y: i / d.width}, true
+}
+
+// downhill is the index of the lowest neighbour of tile i below it, -1 if there isn't one or no goal
+// can be reached from i.
+// Ties go to the first of north, south, west and east, so agents all pick the same way.
+func (d *DijkstraMap) downhill(i int) int {
+	if d.Dist[i] == Unreachable {
+		return -1
+	}
+	best, bestDist := -1, d.Dist[i]
+	x, y := i%d.width, i/d.width
+	if y > 0 && d.Dist[i-d.width] < bestDist {
+		best, bestDist = i-d.width, d.Dist[i-d.width]
+	}
+	if y < d.height-1 && d.Dist[i+d.width] < bestDist {
+		best, bestDist = i+d.width, d.Dist[i+d.width]
+	}
+	if x > 0 && d.Dist[i-1] < bestDist {
+		best, bestDist = i-1, d.Dist[i-1]
+	}
+	if x < d.width-1 && d.Dist[i+1] < bestDist {
+		best = i + 1
+	}
+	return best
+}
+
+func (d *DijkstraMap) reset() {
+	for i := range d.Dist {
+		d.Dist[i] = Unreachable
+	}
+	d.nodes.Reset()
+}
+
+func (d *DijkstraMap) seed(i, dist int) {
+	if dist >= d.Dist[i] || d.nodes.Closed(i) {
+		return
+	}
+	d.Dist[i] = dist
+	if d.nodes.Seen(i) {
+		d.nodes.Improve(i, dist, -1)
+	} else {
+		d.nodes.Push(i, dist, 0, -1)
+	}
+}
+
+// relax spreads the seeded distances over the map.
+func (d *DijkstraMap) relax(passable func(x, y int) bool, cost func(x, y int) int) {
+	w := d.width
+	for d.nodes.Len() > 0 {
+		i := d.nodes.Pop()
+
+		x, y := i%w, i/w
+		for _, n := range [4]entity.Point{{X: x, Y: y - 1}, {X: x, Y: y + 1}, {X: x - 1, Y: y}, {X: x + 1, Y: y}} {
+			if n.X < 0 || n.X >= w || n.Y < 0 || n.Y >= d.height || !passable(n.X, n.Y) {
+				continue
+			}
+			nd := d.Dist[i] + 1
+			if cost != nil {
+				nd += cost(n.X, n.Y)
+			}
+			d.seed(n.Y*w+n.X, nd)
+		}
+	}
+}
+
+// FlowField is a Dijkstra map with the way downhill worked out for every tile up front, so any
+// number of agents heading for the same goals can look up their next step in constant time.
+type FlowField struct {
+	Dist *DijkstraMap
+	next []int32 // Index of the tile to step onto, -1 on goals and unreachable tiles
+}
+
+// NewFlowField allocates the buffers for a map of the given dimensions.
+func NewFlowField(width, height int) *FlowField {
+	return &FlowField{
+		Dist: NewDijkstraMap(width, height),
+		next: make([]int32, width*height),
+	}
+}
+
+// Compute points every tile towards the nearest goal. See DijkstraMap.Compute.
+func (f *FlowField) Compute(goals []entity.Point, passable func(x, y int) bool, cost func(x, y int) int) {
+	f.Dist.Compute(goals, passable, cost)
+	f.build()
+}
+
+// Flee points every tile away from the goals of another map. See DijkstraMap.Flee.
+func (f *FlowField) Flee(from *DijkstraMap, scale float64, passable func(x, y int) bool, cost func(x, y int) int) {
+	f.Dist.Flee(from, scale, passable, cost)
+	f.build()
+}
+
+func (f *FlowField) build() {
+	for i := range f.next {
+		f.next[i] = int32(f.Dist.downhill(i))
+	}
+}
+
+// Next is the step to take from (x, y). Returns false on a goal, or where no goal can be reached.
+func (f *FlowField) Next(x, y int) (entity.Point, bool) {
+	d := f.Dist
+	if x < 0 || x >= d.width || y < 0 || y >= d.height {
+		return entity.Point{}, false
+	}
+	i := f.next[y*d.width+x]
+	if i < 0 {
+		return entity.Point{}, false
+	}
+	return entity.Point{X: int(i) % d.width, Y: int(i) / d.width}, true
+}
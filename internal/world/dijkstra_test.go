@@ -0,0 +1,139 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+func TestDijkstraMap_Compute(t *testing.T) {
+	m := newTestMap(fireRooms)
+	d := NewDijkstraMap(m.Width, m.Height)
+
+	// Two goals, one in each room
+	d.Compute([]entity.Point{{X: 1, Y: 1}, {X: 9, Y: 3}}, m.IsWalkable, nil)
+
+	tests := []struct {
+		x, y int
+		want int
+	}{
+		{1, 1, 0},
+		{9, 3, 0},
+		{3, 2, 3},
+		{7, 2, 3},
+		{5, 2, 5}, // The doorway is as far from either
+		{0, 0, Unreachable},
+		{-1, 2, Unreachable},
+	}
+	for _, tt := range tests {
+		if got := d.At(tt.x, tt.y); got != tt.want {
+			t.Errorf("At(%d,%d) = %d, want %d", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestDijkstraMap_CostAndPassability(t *testing.T) {
+	m := newTestMap(fireRooms)
+	d := NewDijkstraMap(m.Width, m.Height)
+	goal := []entity.Point{{X: 1, Y: 2}}
+
+	d.Compute(goal, m.IsWalkable, func(x, y int) int {
+		if x == 5 {
+			return 10 // A slow doorway
+		}
+		return 0
+	})
+	if got := d.At(6, 2); got != 15 {
+		t.Errorf("Expected the doorway's cost on the way through, got %d", got)
+	}
+
+	d.Compute(goal, func(x, y int) bool { return m.IsWalkable(x, y) && x != 5 }, nil)
+	if got := d.At(6, 2); got != Unreachable {
+		t.Errorf("Expected the far room cut off, got %d", got)
+	}
+
+	d.Compute([]entity.Point{{X: 0, Y: 0}}, m.IsWalkable, nil)
+	if got := d.At(1, 1); got != Unreachable {
+		t.Errorf("Expected a goal inside a wall to be ignored, got %d", got)
+	}
+}
+
+func TestDijkstraMap_DescendReachesGoal(t *testing.T) {
+	m := newTestFacility()
+	d := NewDijkstraMap(m.Width, m.Height)
+	goal := entity.Point{X: 8, Y: 9}
+	d.Compute([]entity.Point{goal}, m.IsWalkable, nil)
+
+	p := entity.Point{X: 17, Y: 1}
+	steps := 0
+	for {
+		next, ok := d.Descend(p.X, p.Y)
+		if !ok {
+			break
+		}
+		if d.At(next.X, next.Y) >= d.At(p.X, p.Y) {
+			t.Fatalf("Expected every step to go downhill, %v to %v", p, next)
+		}
+		p = next
+		steps++
+	}
+
+	if p != goal {
+		t.Errorf("Expected to roll down to %v, stopped at %v", goal, p)
+	}
+	if want := d.Dist[m.GetIndex(17, 1)]; steps != want {
+		t.Errorf("Expected the shortest walk of %d steps, took %d", want, steps)
+	}
+}
+
+func TestDijkstraMap_FleeGetsFurtherAway(t *testing.T) {
+	m := newTestFacility()
+	toward := NewDijkstraMap(m.Width, m.Height)
+	toward.Compute([]entity.Point{{X: 9, Y: 3}}, m.IsWalkable, nil)
+
+	away := NewDijkstraMap(m.Width, m.Height)
+	away.Flee(toward, DefaultFleeScale, m.IsWalkable, nil)
+
+	p := entity.Point{X: 9, Y: 2}
+	for i := 0; i < 30; i++ {
+		next, ok := away.Descend(p.X, p.Y)
+		if !ok {
+			break
+		}
+		p = next
+	}
+
+	if got := toward.At(p.X, p.Y); got < 8 {
+		t.Errorf("Expected fleeing to end well away from the threat, ended %d steps from it at %v", got, p)
+	}
+}
+
+func TestFlowField_MatchesDescend(t *testing.T) {
+	m := newTestFacility()
+	f := NewFlowField(m.Width, m.Height)
+	f.Compute([]entity.Point{{X: 2, Y: 2}, {X: 16, Y: 2}}, m.IsWalkable, nil)
+
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			want, wantOK := f.Dist.Descend(x, y)
+			got, ok := f.Next(x, y)
+			if got != want || ok != wantOK {
+				t.Errorf("Next(%d,%d) = %v, %v, want %v, %v", x, y, got, ok, want, wantOK)
+			}
+		}
+	}
+}
+
+func TestFlowField_ComputeDoesNotAllocate(t *testing.T) {
+	m := newTestFacility()
+	f := NewFlowField(m.Width, m.Height)
+	goals := []entity.Point{{X: 8, Y: 9}}
+	f.Compute(goals, m.IsWalkable, nil) // Warm up the queue
+
+	allocs := testing.AllocsPerRun(20, func() {
+		f.Compute(goals, m.IsWalkable, m.HazardCost)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected recomputing a flow field not to allocate, got %v allocs", allocs)
+	}
+}
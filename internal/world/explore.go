@@ -0,0 +1,49 @@
+package world
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+// IsFrontier reports whether (x, y) is an explored tile that can be walked, next to one that hasn't been explored yet.
+func (m *Map) IsFrontier(x, y int) bool {
+	tile := m.GetTile(x, y)
+	if tile == nil || !tile.Walkable || !tile.Explored {
+		return false
+	}
+	for d := 0; d < 4; d++ {
+		if n := m.GetTile(x+stepDX[d], y+stepDY[d]); n != nil && !n.Explored {
+			return true
+		}
+	}
+	return false
+}
+
+// Frontiers appends every frontier tile to dst, row by row.
+func (m *Map) Frontiers(dst []entity.Point) []entity.Point {
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.IsFrontier(x, y) {
+				dst = append(dst, entity.Point{X: x, Y: y})
+			}
+		}
+	}
+	return dst
+}
+
+// Coverage is the share of walkable tiles that have been explored, from 0 to 1.
+func (m *Map) Coverage() float64 {
+	walkable, explored := 0, 0
+	for _, tile := range m.Tiles {
+		if !tile.Walkable {
+			continue
+		}
+		walkable++
+		if tile.Explored {
+			explored++
+		}
+	}
+	if walkable == 0 {
+		return 1
+	}
+	return float64(explored) / float64(walkable)
+}
@@ -0,0 +1,62 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+// exploreRect marks every tile inside r (edges included) as explored.
+func exploreRect(m *Map, r Rect) {
+	for y := r.Y1; y <= r.Y2; y++ {
+		for x := r.X1; x <= r.X2; x++ {
+			m.Tiles[m.GetIndex(x, y)].Explored = true
+		}
+	}
+}
+
+func TestMap_Frontiers(t *testing.T) {
+	m := newTestMap(`
+#######
+#.....#
+#######`)
+	exploreRect(m, Rect{X1: 0, Y1: 0, X2: 3, Y2: 2})
+
+	got := m.Frontiers(nil)
+	want := entity.Point{X: 3, Y: 1}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("Expected the one frontier at %v, got %v", want, got)
+	}
+
+	// A wall on the edge of the explored area isn't a frontier, we can't walk onto it
+	if m.IsFrontier(3, 0) {
+		t.Error("Expected a wall not to be a frontier")
+	}
+
+	exploreRect(m, Rect{X1: 0, Y1: 0, X2: 6, Y2: 2})
+	if got := m.Frontiers(nil); len(got) != 0 {
+		t.Errorf("Expected no frontiers once everything is explored, got %v", got)
+	}
+}
+
+func TestMap_Coverage(t *testing.T) {
+	m := newTestMap(`
+######
+#....#
+######`)
+
+	cases := []struct {
+		explored Rect
+		want     float64
+	}{
+		{Rect{X1: 0, Y1: 0, X2: 0, Y2: 2}, 0},   // Walls don't count
+		{Rect{X1: 0, Y1: 0, X2: 2, Y2: 2}, 0.5}, // Half the floor
+		{Rect{X1: 0, Y1: 0, X2: 5, Y2: 2}, 1.0}, // All of it
+	}
+	for _, tc := range cases {
+		exploreRect(m, tc.explored)
+		if got := m.Coverage(); got != tc.want {
+			t.Errorf("Explored %v: expected coverage %v, got %v", tc.explored, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package world
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+)
+
+// MapExport is everything needed to write a map out: the map itself plus an optional entity overlay.
+type MapExport struct {
+	Map            *Map
+	SpawnX, SpawnY int
+	Entities       []MapEntity // Optional, doors come from Map.Doors
+}
+
+// WriteText writes the map in the map file format, so it can be loaded back with LoadMapFile.
+// Only entities the game can spawn from a map file are written; the stairs and elevators of
+// a multi-floor facility lead nowhere on a single hand-authored floor, so they're left out.
+func (e MapExport) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	if e.Map.Seed != 0 {
+		fmt.Fprintf(bw, "; seed %d\n", e.Map.Seed)
+	}
+	fmt.Fprintln(bw, "[map]")
+	for _, row := range e.rows() {
+		fmt.Fprintln(bw, row)
+	}
+
+	var entities []MapEntity
+	for _, ent := range e.Entities {
+		if IsMapEntityKind(ent.Kind) {
+			entities = append(entities, ent)
+		}
+	}
+
+	if len(entities) > 0 || len(e.Map.Breaches) > 0 || e.Map.Conduits != nil {
+		fmt.Fprintln(bw, "[entities]")
+		for _, b := range e.Map.Breaches {
+			fmt.Fprintf(bw, "%s %d %d\n", mapFileEntityBreach, b.X, b.Y)
+		}
+		// Without them the loader would wire every walkable tile into one grid
+		for i, wired := range e.Map.Conduits {
+			if wired {
+				fmt.Fprintf(bw, "%s %d %d\n", mapFileEntityConduit, i%e.Map.Width, i/e.Map.Width)
+			}
+		}
+		for _, ent := range entities {
+			fields := append([]string{ent.Kind, fmt.Sprint(ent.X), fmt.Sprint(ent.Y)}, ent.Args...)
+			fmt.Fprintln(bw, strings.Join(fields, " "))
+		}
+	}
+
+	return bw.Flush()
+}
+
+// rows renders the grid with the default legend characters.
+func (e MapExport) rows() []string {
+	m := e.Map
+	grid := make([][]rune, m.Height)
+	for y := range grid {
+		grid[y] = make([]rune, m.Width)
+		for x := range grid[y] {
+			tile := m.Tiles[m.GetIndex(x, y)]
+			switch {
+			case tile.Type == TileTypeWall:
+				grid[y][x] = '#'
+			case tile.Type == TileTypeFloor && tile.Walkable:
+				grid[y][x] = '.'
+			default:
+				grid[y][x] = ' '
+			}
+		}
+	}
+
+	for _, d := range m.Doors {
+		if m.GetTile(d.X, d.Y) != nil {
+			grid[d.Y][d.X] = '+'
+		}
+	}
+	if m.GetTile(e.SpawnX, e.SpawnY) != nil {
+		grid[e.SpawnY][e.SpawnX] = '@'
+	}
+
+	rows := make([]string, m.Height)
+	for y := range grid {
+		rows[y] = string(grid[y])
+	}
+	return rows
+}
+
+type pointJSON struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type roomJSON struct {
+	X1   int    `json:"x1"`
+	Y1   int    `json:"y1"`
+	X2   int    `json:"x2"`
+	Y2   int    `json:"y2"`
+	Type string `json:"type,omitempty"`
+}
+
+type entityJSON struct {
+	Kind string   `json:"kind"`
+	X    int      `json:"x"`
+	Y    int      `json:"y"`
+	Args []string `json:"args,omitempty"`
+}
+
+type mapJSON struct {
+	Seed     uint64       `json:"seed"`
+	Width    int          `json:"width"`
+	Height   int          `json:"height"`
+	Spawn    pointJSON    `json:"spawn"`
+	Tiles    []string     `json:"tiles"` // One string per row, using the map file characters
+	Rooms    []roomJSON   `json:"rooms"`
+	Doors    []pointJSON  `json:"doors"`
+	Breaches []pointJSON  `json:"breaches,omitempty"`
+	Entities []entityJSON `json:"entities,omitempty"`
+}
+
+// WriteJSON writes the map as structured JSON: tiles, rooms with their types, doors, entities and the seed.
+func (e MapExport) WriteJSON(w io.Writer) error {
+	m := e.Map
+	out := mapJSON{
+		Seed:   m.Seed,
+		Width:  m.Width,
+		Height: m.Height,
+		Spawn:  pointJSON{X: e.SpawnX, Y: e.SpawnY},
+		Tiles:  e.rows(),
+		Rooms:  make([]roomJSON, 0, len(m.Rooms)),
+		Doors:  make([]pointJSON, 0, len(m.Doors)),
+	}
+
+	for i, r := range m.Rooms {
+		room := roomJSON{X1: r.X1, Y1: r.Y1, X2: r.X2, Y2: r.Y2}
+		if m.Graph != nil && i < len(m.Graph.Types) {
+			room.Type = m.Graph.Types[i].Title()
+		}
+		out.Rooms = append(out.Rooms, room)
+	}
+	for _, d := range m.Doors {
+		out.Doors = append(out.Doors, pointJSON{X: d.X, Y: d.Y})
+	}
+	for _, b := range m.Breaches {
+		out.Breaches = append(out.Breaches, pointJSON{X: b.X, Y: b.Y})
+	}
+	for _, ent := range e.Entities {
+		out.Entities = append(out.Entities, entityJSON{Kind: ent.Kind, X: ent.X, Y: ent.Y, Args: ent.Args})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// Overlay colours for the PNG export, picked to stand out against every theme
+var (
+	exportDoorColor   = core.Yellow
+	exportSpawnColor  = core.Green
+	exportEntityColor = core.Magenta
+)
+
+// WritePNG renders the map from a theme's colours, scale pixels per tile. Floors are drawn dimmer than walls
+// so the layout reads even on single-colour themes; doors, the spawn and entities are drawn on top.
+func (e MapExport) WritePNG(w io.Writer, theme TileVariant, scale int) error {
+	if scale < 1 {
+		scale = 1
+	}
+	m := e.Map
+	img := image.NewRGBA(image.Rect(0, 0, m.Width*scale, m.Height*scale))
+
+	fill := func(x, y int, c core.Color) {
+		rgba := color.RGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+		for py := y * scale; py < (y+1)*scale; py++ {
+			for px := x * scale; px < (x+1)*scale; px++ {
+				img.SetRGBA(px, py, rgba)
+			}
+		}
+	}
+
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			tile := m.Tiles[m.GetIndex(x, y)]
+			c := theme[tile.Type].Color
+			if tile.Type == TileTypeFloor {
+				c = core.Color{R: c.R / 3, G: c.G / 3, B: c.B / 3, A: c.A}
+			}
+			fill(x, y, c)
+		}
+	}
+
+	for _, d := range m.Doors {
+		fill(d.X, d.Y, exportDoorColor)
+	}
+	for _, ent := range e.Entities {
+		if m.GetTile(ent.X, ent.Y) != nil {
+			fill(ent.X, ent.Y, exportEntityColor)
+		}
+	}
+	if m.GetTile(e.SpawnX, e.SpawnY) != nil {
+		fill(e.SpawnX, e.SpawnY, exportSpawnColor)
+	}
+
+	return png.Encode(w, img)
+}
+
+// LinkEntities lists the stairs and elevators on a floor as map entities, for exporting.
+// Their only argument is the floor they lead to.
+func (f *Facility) LinkEntities(floor int) []MapEntity {
+	var ents []MapEntity
+	for _, l := range f.LinksOn(floor) {
+		ents = append(ents, MapEntity{
+			Kind: strings.ToLower(l.Kind.Title()),
+			X:    l.X,
+			Y:    l.Y,
+			Args: []string{fmt.Sprint(l.Other(floor))},
+		})
+	}
+	return ents
+}
@@ -0,0 +1,145 @@
+package world
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+func TestMapExport_TextRoundTrip(t *testing.T) {
+	for seed := uint64(0); seed < 20; seed++ {
+		m, px, py := NewFacilityGenerator(seed).Generate(60, 25)
+		export := MapExport{
+			Map:      m,
+			SpawnX:   px,
+			SpawnY:   py,
+			Entities: []MapEntity{{Kind: "terminal", X: px, Y: py + 1, Args: []string{}}},
+		}
+
+		var buf bytes.Buffer
+		if err := export.WriteText(&buf); err != nil {
+			t.Fatalf("seed %d: unexpected error: %v", seed, err)
+		}
+
+		mf, err := ParseMapFile(&buf)
+		if err != nil {
+			t.Fatalf("seed %d: exported map doesn't parse: %v", seed, err)
+		}
+
+		if mf.SpawnX != px || mf.SpawnY != py {
+			t.Errorf("seed %d: expected spawn (%d,%d), got (%d,%d)", seed, px, py, mf.SpawnX, mf.SpawnY)
+		}
+		if !reflect.DeepEqual(mf.Entities, export.Entities) {
+			t.Errorf("seed %d: expected entities %+v, got %+v", seed, export.Entities, mf.Entities)
+		}
+		if len(mf.Map.Doors) != len(m.Doors) {
+			t.Errorf("seed %d: expected %d doors, got %d", seed, len(m.Doors), len(mf.Map.Doors))
+		}
+		if !reflect.DeepEqual(mf.Map.Conduits, m.Conduits) {
+			t.Errorf("seed %d: conduits differ after round trip", seed)
+		}
+
+		for i, want := range m.Tiles {
+			got := mf.Map.Tiles[i]
+			if got.Type != want.Type || got.Walkable != want.Walkable || got.Bitmask != want.Bitmask {
+				t.Fatalf("seed %d: tile %d differs after round trip: got %+v, want %+v", seed, i, got, want)
+			}
+		}
+	}
+}
+
+// A dump of any facility floor has to load straight into the game
+func TestMapExport_FacilityRoundTrip(t *testing.T) {
+	f := NewFacility(12345, 3, 120, 40)
+	for floor := range f.Floors {
+		export := MapExport{
+			Map:      f.Floors[floor],
+			SpawnX:   f.Spawns[floor].X,
+			SpawnY:   f.Spawns[floor].Y,
+			Entities: f.LinkEntities(floor),
+		}
+
+		var buf bytes.Buffer
+		if err := export.WriteText(&buf); err != nil {
+			t.Fatalf("floor %d: unexpected error: %v", floor, err)
+		}
+		mf, err := ParseMapFile(&buf)
+		if err != nil {
+			t.Fatalf("floor %d: exported map doesn't parse: %v", floor, err)
+		}
+
+		for _, ent := range mf.Entities {
+			if !IsMapEntityKind(ent.Kind) {
+				t.Errorf("floor %d: exported %q at (%d,%d), which the game can't spawn", floor, ent.Kind, ent.X, ent.Y)
+			}
+		}
+	}
+}
+
+func TestMapExport_JSON(t *testing.T) {
+	m, px, py := NewFacilityGenerator(5).Generate(40, 20)
+
+	var buf bytes.Buffer
+	if err := (MapExport{Map: m, SpawnX: px, SpawnY: py}).WriteJSON(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var got mapJSON
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if got.Seed != 5 || got.Width != 40 || got.Height != 20 {
+		t.Errorf("Expected seed 5 and 40x20, got seed %d and %dx%d", got.Seed, got.Width, got.Height)
+	}
+	if len(got.Tiles) != 20 || len(got.Tiles[0]) != 40 {
+		t.Errorf("Expected 20 rows of 40 tiles, got %d rows", len(got.Tiles))
+	}
+	if len(got.Rooms) != len(m.Rooms) || got.Rooms[0].Type != "Spawn" {
+		t.Errorf("Expected %d rooms starting with the spawn room, got %+v", len(m.Rooms), got.Rooms)
+	}
+	if len(got.Doors) != len(m.Doors) {
+		t.Errorf("Expected %d doors, got %d", len(m.Doors), len(got.Doors))
+	}
+	if got.Spawn.X != px || got.Spawn.Y != py {
+		t.Errorf("Expected spawn (%d,%d), got %+v", px, py, got.Spawn)
+	}
+}
+
+func TestMapExport_PNG(t *testing.T) {
+	m := newTestMap(`
+####
+#..#
+####`)
+	export := MapExport{Map: m, SpawnX: 1, SpawnY: 1, Entities: []MapEntity{{Kind: "generator", X: 2, Y: 1}}}
+
+	var buf bytes.Buffer
+	if err := export.WritePNG(&buf, TileVariantAlert, 4); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Invalid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 16 || b.Dy() != 12 {
+		t.Fatalf("Expected a 16x12 image, got %v", b)
+	}
+
+	colorAt := func(x, y int) [3]uint32 {
+		r, g, b, _ := img.At(x*4+1, y*4+1).RGBA()
+		return [3]uint32{r >> 8, g >> 8, b >> 8}
+	}
+
+	if c := colorAt(0, 0); c != [3]uint32{255, 0, 0} {
+		t.Errorf("Expected a red wall from the alert theme, got %v", c)
+	}
+	if c := colorAt(1, 1); c != [3]uint32{0, 255, 0} {
+		t.Errorf("Expected the spawn to be green, got %v", c)
+	}
+	if c := colorAt(2, 1); c != [3]uint32{255, 0, 255} {
+		t.Errorf("Expected the entity overlay to be magenta, got %v", c)
+	}
+}
@@ -10,6 +10,8 @@ const (
 	maxRooms    = 50
 	roomMinSize = 4
 	roomMaxSize = 10
+
+	maxGenerateAttempts = 8 // Seeds tried, counting up, before giving up on a connected map
 )
 
 func NewFacilityGenerator(seed uint64) *FacilityGenerator {
@@ -38,15 +40,37 @@ func (f *FacilityGenerator) carveFloor(m *Map, x, y int) {
 	m.SetTile(x, y, Tile{Type: TileTypeFloor, Walkable: true, Variant: variant})
 }
 
+// Generate builds a facility and returns it with the player's spawn. A map the repairs can't fully
+// connect is thrown away and generated again from the next seed. Returns nil if the dimensions are
+// too small to hold a room, or if no seed tried gives a connected map.
 func (f FacilityGenerator) Generate(width, height int) (*Map, int, int) {
+	gen := &f
+	for attempt := uint64(0); attempt < maxGenerateAttempts; attempt++ {
+		if attempt > 0 {
+			gen = NewFacilityGenerator(f.seed + attempt)
+		}
+		m, px, py, report := gen.generate(width, height)
+		if m == nil {
+			return nil, 0, 0
+		}
+		if report.Connected() {
+			return m, px, py
+		}
+	}
+	return nil, 0, 0
+}
+
+// generate is a single attempt at Generate, reporting how well the repairs connected the map.
+func (f *FacilityGenerator) generate(width, height int) (*Map, int, int, ConnectivityReport) {
 	if width < roomMinSize || height < roomMinSize {
-		return nil, 0, 0
+		return nil, 0, 0, ConnectivityReport{}
 	}
 
 	playerX, playerY := width/2, height/2
 
 	// 1. create the empty map, with walls (non walkable tiles)
 	m := NewMap(width, height)
+	m.Seed = f.seed
 	for x := 0; x < width; x++ {
 		for y := 0; y < height; y++ {
 			m.SetTile(x, y, Tile{Type: TileTypeWall, Walkable: false})
@@ -114,19 +138,42 @@ func (f FacilityGenerator) Generate(width, height int) (*Map, int, int) {
 		rooms = append(rooms, newRoom)
 	}
 
+	// Tiny maps can reject every room roll. Fall back to a single room inset from the border,
+	// so callers can always rely on at least one room (the autopilot picks from them).
+	if len(rooms) == 0 {
+		fallback := Rect{X1: 1, Y1: 1, X2: width - 2, Y2: height - 2}
+		for rx := fallback.X1; rx <= fallback.X2; rx++ {
+			for ry := fallback.Y1; ry <= fallback.Y2; ry++ {
+				f.carveFloor(m, rx, ry)
+			}
+		}
+		playerX, playerY = fallback.Center()
+		rooms = append(rooms, fallback)
+	}
+
 	// 2. run the generation algorithm (l-Corridors algorithm, aka Procedural Dungeon Generator)
 	// 2.1 carve the rooms
 	// 2.2 connect the rooms (l-corridors)
+	// 2.3 stitch any region the spawn can't reach back in with extra corridors
+	m.Rooms = rooms
+	report := f.repairConnectivity(m, playerX, playerY)
+
+	// 2.4 wire up the power network, corridors are already wired as they're carved
+	for _, room := range rooms {
+		layRoomConduits(m, room)
+	}
+
 	// 3. run auto-tiling calculation for all walls
-	f.calculateWallBitmasks(m)
+	calculateWallBitmasks(m)
 
-	m.Rooms = rooms
-	m.Doors = f.findDoorways(m)
+	m.Doors = f.findDoorways(m, playerX, playerY)
+	m.Graph = BuildRoomGraph(m, playerX, playerY)
+	m.Breaches = placeBreaches(m)
 
-	return m, playerX, playerY
+	return m, playerX, playerY, report
 }
 
-func (f FacilityGenerator) findDoorways(m *Map) []entity.Point {
+func (f FacilityGenerator) findDoorways(m *Map, spawnX, spawnY int) []entity.Point {
 	var possibleDoors []entity.Point
 	seen := make(map[entity.Point]bool)
 
@@ -172,6 +219,14 @@ func (f FacilityGenerator) findDoorways(m *Map) []entity.Point {
 		}
 	}
 
+	// Never seal the player in by dropping a door on the spawn tile
+	for i, p := range possibleDoors {
+		if p.X == spawnX && p.Y == spawnY {
+			possibleDoors = append(possibleDoors[:i], possibleDoors[i+1:]...)
+			break
+		}
+	}
+
 	// Shuffle the possible doors and pick 2-3 at random
 	f.rng.Shuffle(len(possibleDoors), func(i, j int) {
 		possibleDoors[i], possibleDoors[j] = possibleDoors[j], possibleDoors[i]
@@ -185,7 +240,8 @@ func (f FacilityGenerator) findDoorways(m *Map) []entity.Point {
 	return possibleDoors[:numDoors]
 }
 
-func (f FacilityGenerator) calculateWallBitmasks(m *Map) {
+// calculateWallBitmasks stores which orthogonal neighbours of every wall are also walls, for auto-tiling.
+func calculateWallBitmasks(m *Map) {
 	for y := 0; y < m.Height; y++ {
 		for x := 0; x < m.Width; x++ {
 			tile := m.GetTile(x, y)
@@ -228,6 +284,7 @@ func (f FacilityGenerator) createHorizontalCorridor(m *Map, x1, x2, y int) {
 	}
 	for x := x1; x <= x2; x++ {
 		f.carveFloor(m, x, y)
+		m.LayConduit(x, y) // Power runs along every corridor
 	}
 }
 
@@ -238,5 +295,6 @@ func (f FacilityGenerator) createVerticalCorridor(m *Map, y1, y2, x int) {
 	}
 	for y := y1; y <= y2; y++ {
 		f.carveFloor(m, x, y)
+		m.LayConduit(x, y)
 	}
 }
@@ -0,0 +1,188 @@
+package world
+
+import (
+	"math/rand/v2"
+)
+
+/*
+	Fire burns through fuel tiles. Debris-strewn floors (any floor Variant) carry more fuel than bare
+	deck plating, walls carry none. Every step a burning tile eats one unit of fuel and some of
+	the oxygen on its tile, grows hotter, and may set its open neighbours alight; the hotter it is,
+	the more likely. It dies down once it runs out of fuel or air, so sealing a room behind a
+	closed door starves it. Water puts it out.
+
+	Spreading uses the layer's own RNG seeded from the map seed, so the same map burns the same way.
+*/
+
+const (
+	MaxFireIntensity = 8
+
+	bareFloorFuel   = 6  // Steps a bare floor tile can burn for
+	debrisFloorFuel = 30 // Steps a floor covered in debris can burn for
+
+	fireSpreadChance = 0.03  // Chance per intensity point of lighting each neighbour per step
+	fireO2Burn       = 0.02  // Oxygen consumed per intensity point per step
+	fireSmoke        = 0.005 // Toxins given off per intensity point per step
+	minFireO2        = 0.3   // Below this much oxygen a fire dies down
+)
+
+// FireLayer tracks which tiles are burning, how hot, and how much they have left to burn.
+type FireLayer struct {
+	Intensity []uint8 // 0 is not burning, up to MaxFireIntensity
+	Fuel      []uint8
+	next      []uint8
+	rng       *rand.Rand
+}
+
+// EnableFire gives every floor tile its fuel and seeds the spread RNG.
+func (m *Map) EnableFire(seed uint64) {
+	n := m.Width * m.Height
+	f := &FireLayer{
+		Intensity: make([]uint8, n),
+		Fuel:      make([]uint8, n),
+		next:      make([]uint8, n),
+		rng:       rand.New(rand.NewPCG(seed, seed^0xf12e)),
+	}
+
+	for i, tile := range m.Tiles {
+		if tile.Type != TileTypeFloor || !tile.Walkable {
+			continue
+		}
+		f.Fuel[i] = bareFloorFuel
+		if tile.Variant != 0 {
+			f.Fuel[i] = debrisFloorFuel
+		}
+	}
+
+	m.Fire = f
+}
+
+// Ignite sets a tile alight. Returns false if it's off the map, already burning or has nothing to burn.
+func (f *FireLayer) Ignite(m *Map, x, y int) bool {
+	if m.GetTile(x, y) == nil {
+		return false
+	}
+	i := m.GetIndex(x, y)
+	if f.Intensity[i] > 0 || f.Fuel[i] == 0 {
+		return false
+	}
+	f.Intensity[i] = 1
+	return true
+}
+
+// AddFuel piles more on a tile, capped at what a byte holds.
+func (f *FireLayer) AddFuel(m *Map, x, y int, fuel int) {
+	if m.GetTile(x, y) == nil {
+		return
+	}
+	i := m.GetIndex(x, y)
+	f.Fuel[i] = uint8(min(int(f.Fuel[i])+fuel, 255))
+}
+
+// ExtinguishRect puts out every fire inside r (edges included) and returns how many tiles were burning.
+func (f *FireLayer) ExtinguishRect(m *Map, r Rect) int {
+	put := 0
+	for y := max(r.Y1, 0); y <= min(r.Y2, m.Height-1); y++ {
+		for x := max(r.X1, 0); x <= min(r.X2, m.Width-1); x++ {
+			i := m.GetIndex(x, y)
+			if f.Intensity[i] > 0 {
+				f.Intensity[i] = 0
+				put++
+			}
+		}
+	}
+	return put
+}
+
+// Burning counts the tiles on fire.
+func (f *FireLayer) Burning() int {
+	n := 0
+	for _, v := range f.Intensity {
+		if v > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Step burns one round. blocked marks tiles fire can't enter, like closed doors; it may be nil.
+// A door closing on a burning tile, or water washing over it, puts it out.
+func (f *FireLayer) Step(m *Map, blocked []bool) {
+	copy(f.next, f.Intensity)
+	w, h := m.Width, m.Height
+
+	// Closed doors and flooded floors both stop a fire
+	open := func(i int) bool {
+		if m.Water != nil && m.Water.Depth[i] >= DryDepth {
+			return false
+		}
+		return m.Tiles[i].Walkable && (blocked == nil || !blocked[i])
+	}
+
+	for i, intensity := range f.Intensity {
+		if intensity == 0 {
+			continue
+		}
+		if !open(i) {
+			f.next[i] = 0
+			continue
+		}
+
+		o2 := float32(1)
+		if m.Air != nil {
+			o2 = m.Air.O2[i]
+		}
+
+		if f.Fuel[i] == 0 || o2 < minFireO2 {
+			f.next[i] = intensity - min(intensity, 2) // Dying down
+			continue
+		}
+
+		f.Fuel[i]--
+		f.next[i] = min(intensity+1, MaxFireIntensity)
+		if m.Air != nil {
+			burnt := min(fireO2Burn*float32(intensity), m.Air.O2[i])
+			m.Air.O2[i] -= burnt
+			m.Air.Toxins[i] += fireSmoke * float32(intensity)
+		}
+
+		// Spread to the open neighbours that have something to burn
+		x, y := i%w, i/w
+		chance := fireSpreadChance * float32(intensity)
+		for _, n := range [4]int{i - w, i + w, i - 1, i + 1} {
+			switch {
+			case n == i-w && y == 0, n == i+w && y == h-1, n == i-1 && x == 0, n == i+1 && x == w-1:
+				continue
+			}
+			if f.Intensity[n] > 0 || f.next[n] > 0 || f.Fuel[n] == 0 || !open(n) {
+				continue
+			}
+			if f.rng.Float32() < chance {
+				f.next[n] = 1
+			}
+		}
+	}
+
+	f.Intensity, f.next = f.next, f.Intensity
+}
+
+// Roll draws from the fire RNG, for systems that need to decide whether something catches fire.
+func (f *FireLayer) Roll() float32 {
+	return f.rng.Float32()
+}
+
+// FireAt returns how hot the fire on a tile is, 0 if it isn't burning or fire is disabled.
+func (m *Map) FireAt(x, y int) int {
+	if m.Fire == nil || x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return 0
+	}
+	return int(m.Fire.Intensity[m.GetIndex(x, y)])
+}
+
+// FireCost is the extra path cost of walking through a tile, so routes go around fires when they can.
+func (m *Map) FireCost(x, y int) int {
+	if intensity := m.FireAt(x, y); intensity > 0 {
+		return 10 + 5*intensity
+	}
+	return 0
+}
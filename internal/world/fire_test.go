@@ -0,0 +1,156 @@
+package world
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+const fireRooms = `
+###########
+#....#....#
+#.........#
+#....#....#
+###########`
+
+func TestFire_SameSeedBurnsTheSameWay(t *testing.T) {
+	burn := func(seed uint64) []uint8 {
+		m := newTestMap(fireRooms)
+		m.EnableFire(seed)
+		m.Fire.Ignite(m, 1, 1)
+		for i := 0; i < 8; i++ {
+			m.Fire.Step(m, nil)
+		}
+		return slices.Clone(m.Fire.Intensity)
+	}
+
+	if !slices.Equal(burn(7), burn(7)) {
+		t.Error("Expected the same seed to spread the fire identically")
+	}
+}
+
+func TestFire_ClosedDoorHoldsItBack(t *testing.T) {
+	m := newTestMap(fireRooms)
+	m.EnableAtmosphere()
+	m.EnableFire(1)
+
+	blocked := make([]bool, m.Width*m.Height)
+	blocked[m.GetIndex(5, 2)] = true
+
+	if !m.Fire.Ignite(m, 1, 1) {
+		t.Fatal("Expected a floor tile to catch")
+	}
+	for i := 0; i < 200; i++ {
+		m.Fire.Step(m, blocked)
+	}
+
+	for y := 1; y <= 3; y++ {
+		for x := 6; x <= 9; x++ {
+			if m.Fire.Fuel[m.GetIndex(x, y)] != bareFloorFuel {
+				t.Errorf("Expected (%d,%d) behind the door to be untouched", x, y)
+			}
+		}
+	}
+	if n := m.Fire.Burning(); n != 0 {
+		t.Errorf("Expected the sealed room to burn out, %d tiles still burning", n)
+	}
+}
+
+func TestFire_BurnsAirAndNeedsIt(t *testing.T) {
+	m := newTestMap(fireRooms)
+	m.EnableAtmosphere()
+	m.EnableFire(1)
+	m.Fire.Ignite(m, 2, 2)
+	m.Fire.Step(m, nil)
+
+	gas := m.GasAt(2, 2)
+	if gas.O2 >= 1 || gas.Toxins <= 0 {
+		t.Errorf("Expected burning to eat oxygen and give off smoke, got %+v", gas)
+	}
+
+	// Without air to feed it a fire dies down within a few steps
+	for i := range m.Air.O2 {
+		m.Air.O2[i] = 0.1
+	}
+	for i := 0; i < MaxFireIntensity; i++ {
+		m.Fire.Step(m, nil)
+	}
+	if n := m.Fire.Burning(); n != 0 {
+		t.Errorf("Expected a fire without oxygen to go out, %d tiles still burning", n)
+	}
+}
+
+func TestFire_IgniteAndExtinguish(t *testing.T) {
+	m := newTestMap(fireRooms)
+	m.EnableFire(1)
+
+	if m.Fire.Ignite(m, 0, 0) {
+		t.Error("Expected a wall not to catch")
+	}
+	if m.Fire.Ignite(m, -1, 2) {
+		t.Error("Expected a tile off the map not to catch")
+	}
+
+	m.Fire.Ignite(m, 1, 1)
+	m.Fire.Ignite(m, 7, 2)
+	if m.Fire.Ignite(m, 1, 1) {
+		t.Error("Expected a burning tile not to catch twice")
+	}
+
+	if put := m.Fire.ExtinguishRect(m, Rect{X1: 0, Y1: 0, X2: 4, Y2: 4}); put != 1 {
+		t.Errorf("Expected to put out 1 tile in the left room, got %d", put)
+	}
+	if m.FireAt(1, 1) != 0 || m.FireAt(7, 2) == 0 {
+		t.Error("Expected only the left room's fire to go out")
+	}
+}
+
+func TestFindPathWithOptions_GoesAroundFire(t *testing.T) {
+	m := newTestMap(`
+#######
+#.....#
+#.....#
+#.....#
+#######`)
+	m.EnableFire(1)
+	for y := 1; y <= 2; y++ {
+		m.Fire.Ignite(m, 3, y)
+	}
+
+	pf := NewPathfinder(m.Width, m.Height)
+	start, target := entity.Point{X: 1, Y: 1}, entity.Point{X: 5, Y: 1}
+	path := pf.FindPathWithOptions(m, start, target, m.IsWalkable, PathOptions{Cost: m.FireCost})
+
+	if len(path) == 0 || path[len(path)-1] != target {
+		t.Fatalf("Expected a path to %v, got %v", target, path)
+	}
+	for _, p := range path {
+		if m.FireAt(p.X, p.Y) > 0 {
+			t.Errorf("Expected the path to skirt the fire, it crosses %v", p)
+		}
+	}
+
+	// With the bottom row burning too, walking through is the only way
+	m.Fire.Ignite(m, 3, 3)
+	if path := pf.FindPathWithOptions(m, start, target, m.IsWalkable, PathOptions{Cost: m.FireCost}); len(path) == 0 {
+		t.Error("Expected fire to slow a route down, not block it")
+	}
+}
+
+func TestTileOverlay_At(t *testing.T) {
+	tests := []struct {
+		strength int
+		want     TileAppearance
+	}{
+		{1, TileOverlayFire[0]},
+		{4, TileOverlayFire[1]},
+		{MaxFireIntensity, TileOverlayFire[2]},
+		{MaxFireIntensity + 5, TileOverlayFire[2]},
+	}
+	for _, tt := range tests {
+		if got := TileOverlayFire.At(tt.strength, MaxFireIntensity); got != tt.want {
+			t.Errorf("At(%d) = %v, want %v", tt.strength, got, tt.want)
+		}
+	}
+}
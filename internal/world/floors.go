@@ -0,0 +1,202 @@
+package world
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+// The facility the game generates. Tools dumping a floor use the same, so it matches what the player gets.
+const (
+	DefaultFloorCount  = 3
+	DefaultFloorWidth  = 120
+	DefaultFloorHeight = 40
+)
+
+// LinkKind is how the player travels between two floors.
+type LinkKind uint8
+
+const (
+	LinkKindStairs LinkKind = iota
+	LinkKindElevator
+)
+
+func (k LinkKind) Title() string {
+	switch k {
+	case LinkKindStairs:
+		return "Stairs"
+	case LinkKindElevator:
+		return "Elevator"
+	default:
+		return "Unknown"
+	}
+}
+
+// FloorLink joins the same tile on two neighbouring floors, so stepping onto it on
+// one floor puts the player on exactly the same X, Y on the other.
+type FloorLink struct {
+	X, Y  int
+	Upper int // Index of the upper floor in Facility.Floors
+	Lower int // Index of the floor directly below it
+	Kind  LinkKind
+}
+
+// Facility is a stack of floors, each generated from its own seed offset and connected by stairs and elevators.
+type Facility struct {
+	Seed   uint64
+	Floors []*Map
+	Spawns []entity.Point // Where the player would start on each floor
+	Links  []FloorLink
+}
+
+// NewFacility generates the given number of floors and lines up a stair or elevator between every pair of
+// neighbouring floors. A facility whose link tiles can't be joined up to the rest of their floor is
+// generated again from the next seed. Returns nil if the dimensions are too small to hold a room.
+func NewFacility(seed uint64, floors, width, height int) *Facility {
+	if floors < 1 {
+		return nil
+	}
+	for attempt := uint64(0); attempt < maxGenerateAttempts; attempt++ {
+		if f, ok := buildFacility(seed+attempt, floors, width, height); ok {
+			return f
+		}
+	}
+	return nil
+}
+
+// buildFacility is a single attempt at NewFacility. It fails if a floor can't be generated,
+// or if a link tile carved into the floor below is left cut off from its spawn.
+func buildFacility(seed uint64, floors, width, height int) (*Facility, bool) {
+	f := &Facility{Seed: seed}
+	generators := make([]*FacilityGenerator, floors)
+
+	for i := 0; i < floors; i++ {
+		generators[i] = NewFacilityGenerator(seed + uint64(i))
+		m, px, py := generators[i].Generate(width, height)
+		if m == nil {
+			return nil, false
+		}
+		f.Floors = append(f.Floors, m)
+		f.Spawns = append(f.Spawns, entity.Point{X: px, Y: py})
+	}
+
+	used := make([]map[entity.Point]bool, floors)
+	for i := range used {
+		used[i] = map[entity.Point]bool{f.Spawns[i]: true}
+	}
+
+	for i := 0; i+1 < floors; i++ {
+		upper, lower := f.Floors[i], f.Floors[i+1]
+		p := f.pickLinkTile(i, used)
+
+		// Make sure the tile exists on the floor below too, then let the generator
+		// carve a corridor from it into the rest of that floor.
+		if !lower.IsWalkable(p.X, p.Y) {
+			gen := generators[i+1]
+			gen.carveFloor(lower, p.X, p.Y)
+			if !gen.repairConnectivity(lower, f.Spawns[i+1].X, f.Spawns[i+1].Y).Connected() {
+				return nil, false
+			}
+			calculateWallBitmasks(lower)
+		}
+		lower.Doors = removePoint(lower.Doors, p)
+		upper.Doors = removePoint(upper.Doors, p)
+
+		used[i][p] = true
+		used[i+1][p] = true
+
+		kind := LinkKindStairs
+		if i%2 == 1 {
+			kind = LinkKindElevator
+		}
+		f.Links = append(f.Links, FloorLink{X: p.X, Y: p.Y, Upper: i, Lower: i + 1, Kind: kind})
+	}
+
+	// Corridors were carved and doors taken off the link tiles, so the room graphs need redoing
+	for i, m := range f.Floors {
+		m.Graph = BuildRoomGraph(m, f.Spawns[i].X, f.Spawns[i].Y)
+	}
+
+	return f, true
+}
+
+// pickLinkTile chooses where the link from floor i down to floor i+1 goes.
+// It prefers a room corner that is already walkable on both floors, and otherwise
+// takes the furthest room from the spawn so the player has to explore to find it.
+// Links stay off the room centres, which the autopilot heads for when it wanders.
+func (f *Facility) pickLinkTile(i int, used []map[entity.Point]bool) entity.Point {
+	upper, lower := f.Floors[i], f.Floors[i+1]
+	spawn := f.Spawns[i]
+	centres := make(map[entity.Point]bool)
+	for _, m := range []*Map{upper, lower} {
+		for _, room := range m.Rooms {
+			x, y := room.Center()
+			centres[entity.Point{X: x, Y: y}] = true
+		}
+	}
+	free := func(p entity.Point) bool { return !used[i][p] && !used[i+1][p] && !centres[p] }
+
+	fallback := entity.Point{X: -1}
+	fallbackDist := -1
+	for _, room := range upper.Rooms {
+		if room.Contains(spawn.X, spawn.Y) {
+			continue
+		}
+		for _, p := range roomCorners(room) {
+			if !free(p) {
+				continue
+			}
+			if lower.IsWalkable(p.X, p.Y) {
+				return p
+			}
+			if dist := ManhattanDistance(p, spawn); dist > fallbackDist {
+				fallback, fallbackDist = p, dist
+			}
+			break
+		}
+	}
+
+	if fallbackDist >= 0 {
+		return fallback
+	}
+
+	// Single room floor, put the link in a free corner of the spawn room
+	corners := roomCorners(upper.Rooms[0])
+	for _, p := range corners {
+		if free(p) {
+			return p
+		}
+	}
+	return corners[0]
+}
+
+// roomCorners lists the corner tiles of a room, clockwise from the top left.
+func roomCorners(room Rect) []entity.Point {
+	return []entity.Point{{X: room.X1, Y: room.Y1}, {X: room.X2, Y: room.Y1}, {X: room.X2, Y: room.Y2}, {X: room.X1, Y: room.Y2}}
+}
+
+// LinksOn lists the links that start or end on the given floor.
+func (f *Facility) LinksOn(floor int) []FloorLink {
+	var links []FloorLink
+	for _, l := range f.Links {
+		if l.Upper == floor || l.Lower == floor {
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+// Other returns the floor on the opposite end of the link.
+func (l FloorLink) Other(floor int) int {
+	if l.Upper == floor {
+		return l.Lower
+	}
+	return l.Upper
+}
+
+func removePoint(points []entity.Point, p entity.Point) []entity.Point {
+	for i, existing := range points {
+		if existing == p {
+			return append(points[:i], points[i+1:]...)
+		}
+	}
+	return points
+}
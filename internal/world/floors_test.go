@@ -0,0 +1,98 @@
+package world
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewFacility(t *testing.T) {
+	tests := []struct {
+		name          string
+		floors        int
+		width, height int
+		expectNil     bool
+	}{
+		{"No floors", 0, 120, 40, true},
+		{"Too small", 3, 2, 2, true},
+		{"Single floor", 1, 120, 40, false},
+		{"Three floors", 3, 120, 40, false},
+		{"Tiny floors", 4, roomMinSize, roomMinSize, false},
+		{"Many floors", 10, 80, 30, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewFacility(99, tc.floors, tc.width, tc.height)
+			if tc.expectNil {
+				if f != nil {
+					t.Fatalf("Expected nil facility, got %d floors", len(f.Floors))
+				}
+				return
+			}
+
+			if len(f.Floors) != tc.floors {
+				t.Fatalf("Expected %d floors, got %d", tc.floors, len(f.Floors))
+			}
+			if len(f.Links) != tc.floors-1 {
+				t.Fatalf("Expected %d links, got %d", tc.floors-1, len(f.Links))
+			}
+
+			for _, l := range f.Links {
+				upper, lower := f.Floors[l.Upper], f.Floors[l.Lower]
+				if l.Lower != l.Upper+1 {
+					t.Errorf("Expected link to join neighbouring floors, got %d -> %d", l.Upper, l.Lower)
+				}
+				if !upper.IsWalkable(l.X, l.Y) || !lower.IsWalkable(l.X, l.Y) {
+					t.Errorf("Expected link at (%d,%d) to be walkable on floors %d and %d", l.X, l.Y, l.Upper, l.Lower)
+				}
+				for _, floor := range []int{l.Upper, l.Lower} {
+					if f.Spawns[floor].X == l.X && f.Spawns[floor].Y == l.Y {
+						t.Errorf("Expected link at (%d,%d) not to sit on the spawn of floor %d", l.X, l.Y, floor)
+					}
+					for _, d := range f.Floors[floor].Doors {
+						if d.X == l.X && d.Y == l.Y {
+							t.Errorf("Expected no door on link (%d,%d) on floor %d", l.X, l.Y, floor)
+						}
+					}
+					for _, e := range f.Floors[floor].Graph.Edges {
+						for _, d := range e.Doors {
+							if d.X == l.X && d.Y == l.Y {
+								t.Errorf("Expected the room graph of floor %d to have no door on link (%d,%d)", floor, l.X, l.Y)
+							}
+						}
+					}
+					for _, room := range f.Floors[floor].Rooms {
+						if x, y := room.Center(); x == l.X && y == l.Y {
+							t.Errorf("Expected link at (%d,%d) to stay off the room centres the autopilot heads for", l.X, l.Y)
+						}
+					}
+				}
+			}
+
+			for i, m := range f.Floors {
+				if report := ValidateConnectivity(m, f.Spawns[i].X, f.Spawns[i].Y); !report.Connected() {
+					t.Errorf("Floor %d is not fully connected: %+v", i, report)
+				}
+				if len(f.LinksOn(i)) == 0 && tc.floors > 1 {
+					t.Errorf("Floor %d has no links", i)
+				}
+			}
+		})
+	}
+}
+
+func TestNewFacility_SeedOffsets(t *testing.T) {
+	f := NewFacility(7, 2, 120, 40)
+
+	// Each floor uses its own seed, so it must match a standalone generator with that seed
+	for i, m := range f.Floors {
+		want, _, _ := NewFacilityGenerator(7+uint64(i)).Generate(120, 40)
+		if !slices.Equal(m.Rooms, want.Rooms) {
+			t.Errorf("Floor %d rooms don't match seed %d", i, 7+i)
+		}
+	}
+
+	if slices.Equal(f.Floors[0].Rooms, f.Floors[1].Rooms) {
+		t.Errorf("Expected floors to differ")
+	}
+}
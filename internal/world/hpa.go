@@ -0,0 +1,479 @@
+package world
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/algo"
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+/*
+	The hierarchy is HPA*: the map is cut into square clusters, and wherever open floor runs across
+	the border between two clusters a portal is placed on each side. Walking distances (and the
+	tiles walked) between the portals of a cluster are worked out once and cached, so a route across
+	the whole facility is an A* over a few hundred portals instead of tens of thousands of tiles.
+	Turning that route into tiles only needs the cached paths, plus a small search inside the start
+	and target clusters.
+
+	When a tile changes, say a door closes, only its cluster (and the neighbour whose border it sits
+	on) is marked dirty and rebuilt before the next search.
+*/
+
+// DefaultClusterSize is the width and height of a cluster in tiles.
+const DefaultClusterSize = 10
+
+// longEntrance is the length of border run that gets a portal at each end instead of one in the middle.
+const longEntrance = 6
+
+// portalPair is a step across a border, from a on one side to b on the other.
+type portalPair struct {
+	a, b int
+}
+
+type cluster struct {
+	X1, Y1, X2, Y2 int
+
+	east, south []portalPair // Entrances to the neighbours east and south, a on this side
+	portals     []int        // Tile index of every portal in the cluster
+	across      [][]int      // For each portal, the tiles one step over the border from it
+	dist        [][]int      // Walking distance between two portals inside the cluster, -1 if there's no way
+	paths       [][][]entity.Point
+	dirty       bool
+}
+
+// Hierarchy is an HPA* abstraction of a map that can be kept up to date as tiles open and close.
+type Hierarchy struct {
+	m          *Map
+	isWalkable func(x, y int) bool
+	size       int
+	across     int // Clusters per row
+	down       int // Clusters per column
+	clusters   []cluster
+	dirty      []int
+
+	// Abstract search, over tile indices like the jump search
+	search *algo.NodePool
+
+	// Breadth-first search inside a single cluster
+	bfsSeen   []uint64
+	bfsParent []int32
+	bfsDist   []int
+	bfsQueue  []int
+	bfsGen    uint64
+
+	startDist, targetDist []int
+}
+
+// NewHierarchy cuts the map into clusters of the given size. isWalkable decides which tiles
+// are open, and must be told about changes through Invalidate. Nothing is built until the first search.
+func NewHierarchy(m *Map, clusterSize int, isWalkable func(x, y int) bool) *Hierarchy {
+	area := m.Width * m.Height
+	h := &Hierarchy{
+		m:          m,
+		isWalkable: isWalkable,
+		size:       clusterSize,
+		across:     (m.Width + clusterSize - 1) / clusterSize,
+		down:       (m.Height + clusterSize - 1) / clusterSize,
+		search:     algo.NewNodePool(area),
+		bfsSeen:    make([]uint64, area),
+		bfsParent:  make([]int32, area),
+		bfsDist:    make([]int, area),
+	}
+
+	h.clusters = make([]cluster, h.across*h.down)
+	for cy := 0; cy < h.down; cy++ {
+		for cx := 0; cx < h.across; cx++ {
+			ci := cy*h.across + cx
+			h.clusters[ci] = cluster{
+				X1: cx * clusterSize,
+				Y1: cy * clusterSize,
+				X2: min((cx+1)*clusterSize, m.Width) - 1,
+				Y2: min((cy+1)*clusterSize, m.Height) - 1,
+			}
+			h.markDirty(ci)
+		}
+	}
+	return h
+}
+
+// Invalidate tells the hierarchy that (x, y) may have opened or closed.
+func (h *Hierarchy) Invalidate(x, y int) {
+	if x < 0 || x >= h.m.Width || y < 0 || y >= h.m.Height {
+		return
+	}
+	cx, cy := x/h.size, y/h.size
+	ci := cy*h.across + cx
+	h.markDirty(ci)
+
+	// A tile on the edge of a cluster is part of the border with its neighbour too
+	c := &h.clusters[ci]
+	if x == c.X1 && cx > 0 {
+		h.markDirty(ci - 1)
+	}
+	if x == c.X2 && cx < h.across-1 {
+		h.markDirty(ci + 1)
+	}
+	if y == c.Y1 && cy > 0 {
+		h.markDirty(ci - h.across)
+	}
+	if y == c.Y2 && cy < h.down-1 {
+		h.markDirty(ci + h.across)
+	}
+}
+
+func (h *Hierarchy) markDirty(ci int) {
+	if !h.clusters[ci].dirty {
+		h.clusters[ci].dirty = true
+		h.dirty = append(h.dirty, ci)
+	}
+}
+
+// Refresh rebuilds every dirty cluster and returns how many there were. Searches call it themselves.
+func (h *Hierarchy) Refresh() int {
+	if len(h.dirty) == 0 {
+		return 0
+	}
+
+	// Borders first, a cluster's portals come from the borders of its neighbours as well
+	for _, ci := range h.dirty {
+		h.buildBorders(ci)
+	}
+	for _, ci := range h.dirty {
+		h.buildPortals(ci)
+		h.clusters[ci].dirty = false
+	}
+
+	rebuilt := len(h.dirty)
+	h.dirty = h.dirty[:0]
+	return rebuilt
+}
+
+// buildBorders finds the entrances on all four borders of a cluster.
+func (h *Hierarchy) buildBorders(ci int) {
+	cx, cy := ci%h.across, ci/h.across
+	if cx < h.across-1 {
+		h.buildBorder(ci, true)
+	}
+	if cy < h.down-1 {
+		h.buildBorder(ci, false)
+	}
+	if cx > 0 {
+		h.buildBorder(ci-1, true)
+	}
+	if cy > 0 {
+		h.buildBorder(ci-h.across, false)
+	}
+}
+
+// buildBorder finds the entrances from a cluster into its neighbour to the east, or to the south.
+// Every run of open tiles facing each other across the border gets a portal in the middle,
+// or one at each end when it's long.
+func (h *Hierarchy) buildBorder(ci int, east bool) {
+	c := &h.clusters[ci]
+	m := h.m
+
+	var pairs []portalPair
+	add := func(s int) {
+		if east {
+			pairs = append(pairs, portalPair{a: m.GetIndex(c.X2, s), b: m.GetIndex(c.X2+1, s)})
+		} else {
+			pairs = append(pairs, portalPair{a: m.GetIndex(s, c.Y2), b: m.GetIndex(s, c.Y2+1)})
+		}
+	}
+
+	from, to := c.X1, c.X2
+	if east {
+		from, to = c.Y1, c.Y2
+	}
+
+	runStart := -1
+	for s := from; s <= to+1; s++ {
+		open := false
+		if s <= to {
+			if east {
+				open = h.isWalkable(c.X2, s) && h.isWalkable(c.X2+1, s)
+			} else {
+				open = h.isWalkable(s, c.Y2) && h.isWalkable(s, c.Y2+1)
+			}
+		}
+
+		switch {
+		case open && runStart < 0:
+			runStart = s
+		case !open && runStart >= 0:
+			end := s - 1
+			if end-runStart+1 >= longEntrance {
+				add(runStart)
+				add(end)
+			} else {
+				add((runStart + end) / 2)
+			}
+			runStart = -1
+		}
+	}
+
+	if east {
+		c.east = pairs
+	} else {
+		c.south = pairs
+	}
+}
+
+// buildPortals gathers a cluster's portals from its borders and caches the walks between them.
+func (h *Hierarchy) buildPortals(ci int) {
+	c := &h.clusters[ci]
+	c.portals = c.portals[:0]
+	c.across = c.across[:0]
+
+	add := func(tile, partner int) {
+		for k, p := range c.portals {
+			if p == tile {
+				c.across[k] = append(c.across[k], partner)
+				return
+			}
+		}
+		c.portals = append(c.portals, tile)
+		c.across = append(c.across, []int{partner})
+	}
+
+	for _, p := range c.east {
+		add(p.a, p.b)
+	}
+	for _, p := range c.south {
+		add(p.a, p.b)
+	}
+	if ci%h.across > 0 {
+		for _, p := range h.clusters[ci-1].east {
+			add(p.b, p.a)
+		}
+	}
+	if ci >= h.across {
+		for _, p := range h.clusters[ci-h.across].south {
+			add(p.b, p.a)
+		}
+	}
+
+	n := len(c.portals)
+	c.dist = make([][]int, n)
+	c.paths = make([][][]entity.Point, n)
+	for i, from := range c.portals {
+		h.bfs(from, ci)
+		c.dist[i] = make([]int, n)
+		c.paths[i] = make([][]entity.Point, n)
+		for j, to := range c.portals {
+			c.dist[i][j] = -1
+			if h.bfsSeen[to] == h.bfsGen {
+				c.dist[i][j] = h.bfsDist[to]
+				c.paths[i][j] = h.bfsTrace(to)
+			}
+		}
+	}
+}
+
+// bfs walks out from tile start without leaving cluster ci.
+func (h *Hierarchy) bfs(start, ci int) {
+	c := &h.clusters[ci]
+	w := h.m.Width
+
+	h.bfsGen++
+	h.bfsQueue = append(h.bfsQueue[:0], start)
+	h.bfsSeen[start], h.bfsDist[start], h.bfsParent[start] = h.bfsGen, 0, -1
+
+	for k := 0; k < len(h.bfsQueue); k++ {
+		i := h.bfsQueue[k]
+		x, y := i%w, i/w
+		for d := 0; d < 4; d++ {
+			nx, ny := x+stepDX[d], y+stepDY[d]
+			if nx < c.X1 || nx > c.X2 || ny < c.Y1 || ny > c.Y2 {
+				continue
+			}
+			n := ny*w + nx
+			if h.bfsSeen[n] == h.bfsGen || !h.isWalkable(nx, ny) {
+				continue
+			}
+			h.bfsSeen[n], h.bfsDist[n], h.bfsParent[n] = h.bfsGen, h.bfsDist[i]+1, int32(i)
+			h.bfsQueue = append(h.bfsQueue, n)
+		}
+	}
+}
+
+// bfsTrace is the walk the last bfs found from its start to tile to, both ends included.
+func (h *Hierarchy) bfsTrace(to int) []entity.Point {
+	w := h.m.Width
+	path := make([]entity.Point, h.bfsDist[to]+1)
+	for k, i := len(path)-1, to; k >= 0; k, i = k-1, int(h.bfsParent[i]) {
+		path[k] = entity.Point{X: i % w, Y: i / w}
+	}
+	return path
+}
+
+func (h *Hierarchy) clusterAt(i int) int {
+	w := h.m.Width
+	return (i/w/h.size)*h.across + (i%w)/h.size
+}
+
+func (c *cluster) portalIndex(tile int) int {
+	for k, p := range c.portals {
+		if p == tile {
+			return k
+		}
+	}
+	return -1
+}
+
+// FindWaypoints searches the abstract graph for a route from start to target. It returns the
+// portals on the way, with start and target at either end; Refine turns each leg into tiles.
+// Returns nil if the target can't be reached.
+func (h *Hierarchy) FindWaypoints(start, target entity.Point) []entity.Point {
+	m := h.m
+	if m.GetTile(start.X, start.Y) == nil || !h.isWalkable(target.X, target.Y) {
+		return nil
+	}
+	if start == target {
+		return []entity.Point{start}
+	}
+	h.Refresh()
+
+	s, t := m.GetIndexFromPoint(start), m.GetIndexFromPoint(target)
+	sc, tc := h.clusterAt(s), h.clusterAt(t)
+
+	// How far the ends are from the portals of their own clusters
+	h.bfs(s, sc)
+	direct := -1
+	if sc == tc && h.bfsSeen[t] == h.bfsGen {
+		direct = h.bfsDist[t]
+	}
+	h.startDist = h.portalDistances(h.startDist[:0], sc)
+	h.bfs(t, tc)
+	h.targetDist = h.portalDistances(h.targetDist[:0], tc)
+
+	nodes := h.search
+	nodes.Reset()
+	nodes.Push(s, 0, ManhattanDistance(start, target), -1)
+
+	relax := func(from, to, cost int) {
+		if nodes.Closed(to) {
+			return
+		}
+		ng := nodes.Nodes[from].GCost + cost
+		if !nodes.Seen(to) {
+			est := ManhattanDistance(entity.Point{X: to % m.Width, Y: to / m.Width}, target)
+			nodes.Push(to, ng, est, int32(from))
+		} else if ng < nodes.Nodes[to].GCost {
+			nodes.Improve(to, ng, int32(from))
+		}
+	}
+
+	for nodes.Len() > 0 {
+		i := nodes.Pop()
+		if i == t {
+			return h.traceWaypoints(t)
+		}
+
+		ci := h.clusterAt(i)
+		c := &h.clusters[ci]
+		k := c.portalIndex(i)
+
+		if i == s {
+			for q, d := range h.startDist {
+				if d >= 0 {
+					relax(i, c.portals[q], d)
+				}
+			}
+			if direct >= 0 {
+				relax(i, t, direct)
+			}
+		} else if k >= 0 {
+			for q, d := range c.dist[k] {
+				if q != k && d >= 0 {
+					relax(i, c.portals[q], d)
+				}
+			}
+		}
+
+		if k >= 0 {
+			for _, partner := range c.across[k] {
+				relax(i, partner, 1)
+			}
+			if ci == tc && h.targetDist[k] >= 0 {
+				relax(i, t, h.targetDist[k])
+			}
+		}
+	}
+
+	return nil
+}
+
+// portalDistances appends how far the last bfs got to each portal of cluster ci, -1 where it didn't.
+func (h *Hierarchy) portalDistances(dst []int, ci int) []int {
+	for _, p := range h.clusters[ci].portals {
+		d := -1
+		if h.bfsSeen[p] == h.bfsGen {
+			d = h.bfsDist[p]
+		}
+		dst = append(dst, d)
+	}
+	return dst
+}
+
+func (h *Hierarchy) traceWaypoints(t int) []entity.Point {
+	w := h.m.Width
+	var waypoints []entity.Point
+	for i := t; i >= 0; i = int(h.search.Nodes[i].Parent) {
+		waypoints = append(waypoints, entity.Point{X: i % w, Y: i / w})
+	}
+	for a, b := 0, len(waypoints)-1; a < b; a, b = a+1, b-1 {
+		waypoints[a], waypoints[b] = waypoints[b], waypoints[a]
+	}
+	return waypoints
+}
+
+// Refine turns one leg of a FindWaypoints route into tiles, both ends included. Legs between two
+// portals come straight from the cache; legs to or from the ends take a search inside their cluster.
+// Returns nil if a and b aren't the two ends of a leg.
+func (h *Hierarchy) Refine(a, b entity.Point) []entity.Point {
+	if a == b {
+		return []entity.Point{a}
+	}
+	if ManhattanDistance(a, b) == 1 {
+		return []entity.Point{a, b} // A step across a border
+	}
+	h.Refresh()
+
+	ai, bi := h.m.GetIndexFromPoint(a), h.m.GetIndexFromPoint(b)
+	ci := h.clusterAt(ai)
+	if h.clusterAt(bi) != ci {
+		return nil
+	}
+
+	c := &h.clusters[ci]
+	if ka, kb := c.portalIndex(ai), c.portalIndex(bi); ka >= 0 && kb >= 0 {
+		if path := c.paths[ka][kb]; path != nil {
+			return append([]entity.Point(nil), path...) // Callers eat into their paths, keep the cache whole
+		}
+		return nil
+	}
+
+	h.bfs(ai, ci)
+	if h.bfsSeen[bi] != h.bfsGen {
+		return nil
+	}
+	return h.bfsTrace(bi)
+}
+
+// FindPath finds a route with FindWaypoints and refines all of it. The path is close to the
+// shortest but not always exactly it, routes go through the portals.
+func (h *Hierarchy) FindPath(start, target entity.Point) []entity.Point {
+	waypoints := h.FindWaypoints(start, target)
+	if waypoints == nil {
+		return nil
+	}
+
+	path := []entity.Point{start}
+	for i := 1; i < len(waypoints); i++ {
+		leg := h.Refine(waypoints[i-1], waypoints[i])
+		if leg == nil {
+			return nil
+		}
+		path = append(path, leg[1:]...)
+	}
+	return path
+}
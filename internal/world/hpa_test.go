@@ -0,0 +1,118 @@
+package world
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+func TestHierarchy_FindPathMatchesAStarReachability(t *testing.T) {
+	rng := rand.New(rand.NewPCG(41, 41))
+	pf := NewPathfinder(40, 30)
+
+	for trial := 0; trial < 200; trial++ {
+		m := randomCaveMap(rng, 40, 30, []float64{0.1, 0.3}[trial%2])
+		h := NewHierarchy(m, 8, m.IsWalkable)
+		start := entity.Point{X: rng.IntN(m.Width), Y: rng.IntN(m.Height)}
+		target := entity.Point{X: rng.IntN(m.Width), Y: rng.IntN(m.Height)}
+		if !m.IsWalkable(start.X, start.Y) {
+			continue
+		}
+
+		want := pf.FindPath(m, start, target, m.IsWalkable)
+		got := h.FindPath(start, target)
+
+		if (want == nil) != (got == nil) {
+			t.Fatalf("Trial %d, %v to %v: A* found %d tiles, the hierarchy found %d", trial, start, target, len(want), len(got))
+		}
+		if got == nil {
+			continue
+		}
+		checkPath(t, m, got, start, target)
+		if len(got) < len(want) {
+			t.Fatalf("Trial %d: the hierarchy found a path shorter than the shortest, %d < %d", trial, len(got), len(want))
+		}
+	}
+}
+
+func TestHierarchy_CrossFacilityRoute(t *testing.T) {
+	m := newTestFacility()
+	h := NewHierarchy(m, 5, m.IsWalkable)
+	start, target := entity.Point{X: 1, Y: 1}, entity.Point{X: 8, Y: 9}
+
+	waypoints := h.FindWaypoints(start, target)
+	if len(waypoints) < 3 || waypoints[0] != start || waypoints[len(waypoints)-1] != target {
+		t.Fatalf("Expected waypoints through the portals from %v to %v, got %v", start, target, waypoints)
+	}
+
+	path := h.FindPath(start, target)
+	checkPath(t, m, path, start, target)
+
+	shortest := NewPathfinder(m.Width, m.Height).FindPath(m, start, target, m.IsWalkable)
+	if len(path) > len(shortest)*5/4 {
+		t.Errorf("Expected a route close to the shortest %d tiles, got %d", len(shortest), len(path))
+	}
+}
+
+func TestHierarchy_DoorsRebuildOnlyTheirClusters(t *testing.T) {
+	m := newTestFacility()
+	closed := make([]bool, m.Width*m.Height)
+	h := NewHierarchy(m, 5, func(x, y int) bool {
+		return m.IsWalkable(x, y) && !closed[m.GetIndex(x, y)]
+	})
+	start, target := entity.Point{X: 1, Y: 2}, entity.Point{X: 16, Y: 2}
+
+	if built := h.Refresh(); built != 12 {
+		t.Fatalf("Expected the first refresh to build all 12 clusters, built %d", built)
+	}
+	if h.FindPath(start, target) == nil {
+		t.Fatal("Expected a route while the doors are open")
+	}
+
+	// (13, 2) sits inside its cluster, (5, 2) on the border between two
+	cases := []struct {
+		door    entity.Point
+		rebuilt int
+	}{
+		{entity.Point{X: 13, Y: 2}, 1},
+		{entity.Point{X: 5, Y: 2}, 2},
+	}
+	for _, tc := range cases {
+		closed[m.GetIndexFromPoint(tc.door)] = true
+		h.Invalidate(tc.door.X, tc.door.Y)
+		if built := h.Refresh(); built != tc.rebuilt {
+			t.Errorf("Closing %v: expected %d clusters rebuilt, got %d", tc.door, tc.rebuilt, built)
+		}
+		if path := h.FindPath(start, target); path != nil {
+			t.Errorf("Closing %v: expected no route, got %v", tc.door, path)
+		}
+
+		closed[m.GetIndexFromPoint(tc.door)] = false
+		h.Invalidate(tc.door.X, tc.door.Y)
+		h.Refresh()
+	}
+
+	path := h.FindPath(start, target)
+	if path == nil {
+		t.Fatal("Expected the route back once the doors reopen")
+	}
+	checkPath(t, m, path, start, target)
+}
+
+func TestHierarchy_RefineKeepsTheCache(t *testing.T) {
+	m := newTestFacility()
+	h := NewHierarchy(m, 5, m.IsWalkable)
+	waypoints := h.FindWaypoints(entity.Point{X: 1, Y: 2}, entity.Point{X: 16, Y: 2})
+
+	for i := 1; i < len(waypoints); i++ {
+		leg := h.Refine(waypoints[i-1], waypoints[i])
+		if len(leg) == 0 {
+			t.Fatalf("Expected a leg from %v to %v", waypoints[i-1], waypoints[i])
+		}
+		leg[0] = entity.Point{X: -1, Y: -1}
+		if again := h.Refine(waypoints[i-1], waypoints[i]); again[0] != waypoints[i-1] {
+			t.Fatalf("Changing a refined leg changed the cache, got %v", again[0])
+		}
+	}
+}
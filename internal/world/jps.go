@@ -0,0 +1,193 @@
+package world
+
+import (
+	"slices"
+
+	"github.com/vikash-paf/derelict-facility/internal/algo"
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+	"github.com/vikash-paf/derelict-facility/internal/math"
+)
+
+/*
+	Jump Point Search is A* for grids where every step costs the same. Most of the shortest paths
+	across an open floor are the same path drawn in a different order, so instead of pushing every
+	tile onto the open set it runs in a straight line until something interesting happens: the
+	target, or a wall ending beside it that opens a way round the corner that couldn't have been
+	taken sooner. Only those jump points go on the open set.
+
+	This is the 4-way variant. Horizontal runs stop at forced neighbours; vertical runs also stop
+	wherever a horizontal run from them would find something, because a 4-way path has to turn
+	somewhere and this keeps the turns on the vertical legs.
+
+	Left alone, those horizontal looks sweep every row a vertical run crosses, far more tiles than A*
+	visits on an open floor. So no run goes further than maxJump, and no look further than maxProbe:
+	a look that gets that far without reaching a wall stops the vertical run as if it had found
+	something. Runs also stop level with the target. Stopping somewhere extra costs a node but never
+	loses a path. A run lined up with the target and heading for it checks first whether it can
+	simply go straight there.
+*/
+
+const (
+	maxJump  = 16 // Longest run before it stops and goes on the open set anyway
+	maxProbe = 2  // How far a vertical run looks to either side before stopping to turn
+)
+
+// findJumpPath is FindPathInto for 4-way movement with no cost callback.
+// Only jump points go into the node pool, the tiles between them are filled in by trace.
+func (pf *Pathfinder) findJumpPath(dst []entity.Point, m *Map, start, target entity.Point, isWalkable func(x, y int) bool, opts PathOptions) []entity.Point {
+	nodes := pf.nodes
+	nodes.Reset()
+
+	w := m.Width
+	s, t := m.GetIndexFromPoint(start), m.GetIndexFromPoint(target)
+	nodes.Push(s, 0, opts.estimate(start, target), -1)
+
+	for nodes.Len() > 0 {
+		i := nodes.Pop()
+		if i == t {
+			return jumpTrace(dst, nodes, w, t)
+		}
+
+		x, y := i%w, i/w
+		dirs, n := jumpDirections(nodes, i, w)
+		for _, d := range dirs[:n] {
+			jx, jy, ok := jump(x+d.X, y+d.Y, d.X, d.Y, target, isWalkable)
+			if !ok {
+				continue
+			}
+			ji := jy*w + jx
+			if nodes.Closed(ji) {
+				continue
+			}
+
+			ng := nodes.Nodes[i].GCost + StraightCost*(math.Abs(jx-x)+math.Abs(jy-y))
+			if !nodes.Seen(ji) {
+				nodes.Push(ji, ng, opts.estimate(entity.Point{X: jx, Y: jy}, target), int32(i))
+			} else if ng < nodes.Nodes[ji].GCost {
+				nodes.Improve(ji, ng, int32(i))
+			}
+		}
+	}
+
+	return nil
+}
+
+// jumpDirections are the ways worth looking from jump point i, given the way it was reached, and how
+// many of them there are. Going back is never worth it, nor is carrying on sideways the way we came.
+func jumpDirections(nodes *algo.NodePool, i, w int) ([4]entity.Point, int) {
+	p := nodes.Nodes[i].Parent
+	if p < 0 {
+		return [4]entity.Point{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}}, 4
+	}
+
+	dx := sign(i%w - int(p)%w)
+	dy := sign(i/w - int(p)/w)
+	if dx != 0 {
+		return [4]entity.Point{{X: dx, Y: 0}, {X: 0, Y: -1}, {X: 0, Y: 1}}, 3
+	}
+	return [4]entity.Point{{X: 0, Y: dy}, {X: -1, Y: 0}, {X: 1, Y: 0}}, 3
+}
+
+// jump runs from (x, y) in direction (dx, dy) and returns the first jump point it finds.
+func jump(x, y, dx, dy int, target entity.Point, isWalkable func(x, y int) bool) (int, int, bool) {
+	// Lined up with the target and heading for it, a clear run there is as short as any path can be
+	if straightTo(x, y, dx, dy, target, isWalkable) {
+		return target.X, target.Y, true
+	}
+
+	if dx != 0 {
+		x, ok := jumpHorizontal(x, y, dx, maxJump, target, isWalkable)
+		return x, y, ok
+	}
+
+	for steps := 1; ; steps++ {
+		if !isWalkable(x, y) {
+			return 0, 0, false
+		}
+		if y == target.Y || steps == maxJump {
+			return x, y, true
+		}
+
+		// A wall behind us ending beside us opens a way round that couldn't be taken sooner
+		if (isWalkable(x-1, y) && !isWalkable(x-1, y-dy)) || (isWalkable(x+1, y) && !isWalkable(x+1, y-dy)) {
+			return x, y, true
+		}
+		// So does anything a turn left or right from here would run into, as far as we look
+		if _, ok := jumpHorizontal(x+1, y, 1, maxProbe, target, isWalkable); ok {
+			return x, y, true
+		}
+		if _, ok := jumpHorizontal(x-1, y, -1, maxProbe, target, isWalkable); ok {
+			return x, y, true
+		}
+
+		y += dy
+	}
+}
+
+// jumpHorizontal runs along row y from x in direction dx, returning the column of the first jump point.
+// A run that goes limit tiles without finding one stops there anyway, as if it had.
+func jumpHorizontal(x, y, dx, limit int, target entity.Point, isWalkable func(x, y int) bool) (int, bool) {
+	for steps := 1; ; steps++ {
+		if !isWalkable(x, y) {
+			return 0, false
+		}
+		if x == target.X || steps == limit {
+			return x, true
+		}
+		if (isWalkable(x, y-1) && !isWalkable(x-dx, y-1)) || (isWalkable(x, y+1) && !isWalkable(x-dx, y+1)) {
+			return x, true
+		}
+		x += dx
+	}
+}
+
+// straightTo reports whether a run from (x, y) in direction (dx, dy) reaches the target without a wall in the way.
+// It's only worth looking when the run is lined up with the target and heading towards it.
+func straightTo(x, y, dx, dy int, target entity.Point, isWalkable func(x, y int) bool) bool {
+	if (dx == 0 && (x != target.X || sign(target.Y-y+dy) != dy)) || (dy == 0 && (y != target.Y || sign(target.X-x+dx) != dx)) {
+		return false
+	}
+	for ; x != target.X || y != target.Y; x, y = x+dx, y+dy {
+		if !isWalkable(x, y) {
+			return false
+		}
+	}
+	return isWalkable(x, y)
+}
+
+// jumpTrace walks the parents back from t and fills in the straight runs between jump points,
+// writing the path over dst.
+func jumpTrace(dst []entity.Point, nodes *algo.NodePool, w, t int) []entity.Point {
+	n := nodes.Nodes[t].GCost/StraightCost + 1
+	path := slices.Grow(dst[:0], n)[:n]
+
+	k := len(path) - 1
+	for i := t; ; {
+		x, y := i%w, i/w
+		path[k] = entity.Point{X: x, Y: y}
+		p := nodes.Nodes[i].Parent
+		if p < 0 {
+			return path
+		}
+
+		px, py := int(p)%w, int(p)/w
+		dx, dy := sign(px-x), sign(py-y)
+		for x != px || y != py {
+			x, y = x+dx, y+dy
+			k--
+			path[k] = entity.Point{X: x, Y: y}
+		}
+		i = int(p)
+	}
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,69 @@
+package world
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+// randomCaveMap scatters walls over an open floor.
+func randomCaveMap(rng *rand.Rand, w, h int, density float64) *Map {
+	m := setupTestMap(w, h, nil)
+	for i := range m.Tiles {
+		if rng.Float64() < density {
+			m.Tiles[i] = Tile{Type: TileTypeWall, Walkable: false}
+		}
+	}
+	return m
+}
+
+// checkPath fails the test unless path is a 4-way walk over open tiles from start to target.
+func checkPath(t *testing.T, m *Map, path []entity.Point, start, target entity.Point) {
+	t.Helper()
+	if path[0] != start || path[len(path)-1] != target {
+		t.Fatalf("Expected the path to run from %v to %v, got %v to %v", start, target, path[0], path[len(path)-1])
+	}
+	for i, p := range path {
+		if !m.IsWalkable(p.X, p.Y) {
+			t.Fatalf("Path walks through a wall at %v", p)
+		}
+		if i > 0 && ManhattanDistance(p, path[i-1]) != 1 {
+			t.Fatalf("Path jumps from %v to %v", path[i-1], p)
+		}
+	}
+}
+
+func TestFindPath_JumpPointSearchMatchesAStar(t *testing.T) {
+	rng := rand.New(rand.NewPCG(40, 40))
+	pf := NewPathfinder(40, 30)
+
+	for trial := 0; trial < 300; trial++ {
+		m := randomCaveMap(rng, 40, 30, []float64{0.02, 0.1, 0.3}[trial%3])
+		start := entity.Point{X: rng.IntN(m.Width), Y: rng.IntN(m.Height)}
+		target := entity.Point{X: rng.IntN(m.Width), Y: rng.IntN(m.Height)}
+		if !m.IsWalkable(start.X, start.Y) {
+			continue
+		}
+
+		want := pf.findAStarPath(nil, m, start, target, m.IsWalkable, PathOptions{})
+		got := pf.FindPathWithOptions(m, start, target, m.IsWalkable, PathOptions{})
+
+		if len(got) != len(want) {
+			t.Fatalf("Trial %d, %v to %v: jump search found %d tiles, A* found %d", trial, start, target, len(got), len(want))
+		}
+		if len(got) > 0 {
+			checkPath(t, m, got, start, target)
+		}
+	}
+}
+
+func TestFindPath_JumpPointSearchStandingStill(t *testing.T) {
+	m := setupTestMap(5, 5, nil)
+	pf := NewPathfinder(m.Width, m.Height)
+	p := entity.Point{X: 2, Y: 2}
+
+	if path := pf.FindPathWithOptions(m, p, p, m.IsWalkable, PathOptions{}); len(path) != 1 || path[0] != p {
+		t.Errorf("Expected a path to where we stand to be just that tile, got %v", path)
+	}
+}
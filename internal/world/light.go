@@ -0,0 +1,123 @@
+package world
+
+import (
+	"math"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+)
+
+// MinVisibleLight is how bright a tile has to be before the player can make it out.
+const MinVisibleLight = 0.05
+
+// TileLight is the light that reached a tile, one channel per colour.
+// 1.0 is full brightness; overlapping lights add up past that and get clamped when drawn.
+type TileLight struct {
+	R, G, B float32
+}
+
+// Brightness is the strongest channel, so a pure red lamp counts as bright.
+func (l TileLight) Brightness() float32 {
+	return max(l.R, l.G, l.B)
+}
+
+// LightSource is one light being cast onto the map this frame.
+type LightSource struct {
+	X, Y      int
+	Radius    int
+	Color     core.Color
+	Intensity float32 // 1.0 is full brightness at the source
+}
+
+// EnableLighting allocates the per-tile light buffer. From then on ComputeFOV only reveals tiles
+// that are both in line of sight and lit.
+func (m *Map) EnableLighting() {
+	m.Light = make([]TileLight, m.Width*m.Height)
+	m.lightSeen = make([]uint32, m.Width*m.Height)
+}
+
+// ClearLight puts every tile back into darkness, ready for the next frame's lights.
+func (m *Map) ClearLight() {
+	clear(m.Light)
+}
+
+// AddLight casts a light from its source using the same shadowcasting as the FOV, so walls and
+// closed doors throw shadows. Brightness falls off linearly to zero just past the radius.
+func (m *Map) AddLight(src LightSource, blocksLight func(x, y int) bool) {
+	if m.Light == nil || m.GetTile(src.X, src.Y) == nil || src.Radius <= 0 || src.Intensity <= 0 {
+		return
+	}
+
+	r := float32(src.Color.R) / 255 * src.Intensity
+	g := float32(src.Color.G) / 255 * src.Intensity
+	b := float32(src.Color.B) / 255 * src.Intensity
+	falloff := float32(src.Radius + 1)
+
+	// The shadowcaster reaches the diagonals from two quadrants, only light them once
+	m.lightGen++
+	add := func(x, y, dist int) {
+		idx := x + y*m.Width
+		if m.lightSeen[idx] == m.lightGen {
+			return
+		}
+		m.lightSeen[idx] = m.lightGen
+
+		f := 1 - float32(dist)/falloff
+		tile := &m.Light[idx]
+		tile.R += r * f
+		tile.G += g * f
+		tile.B += b * f
+	}
+
+	add(src.X, src.Y, 0)
+	castShadows(m, src.X, src.Y, src.Radius, blocksLight, add)
+}
+
+// LightAt returns the light on a tile, or darkness if it is off the map or lighting is disabled.
+func (m *Map) LightAt(x, y int) TileLight {
+	if m.Light == nil || x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return TileLight{}
+	}
+	return m.Light[x+y*m.Width]
+}
+
+// IsLit reports whether a tile is bright enough to be seen. Without a light buffer everything is lit.
+func (m *Map) IsLit(x, y int) bool {
+	if m.Light == nil {
+		return true
+	}
+	return m.LightAt(x, y).Brightness() >= MinVisibleLight
+}
+
+// TintColor shades a theme colour by the light on its tile. Half of the light is treated as white,
+// so a red lamp makes a green wall dim instead of black.
+func TintColor(c core.Color, light TileLight) core.Color {
+	white := light.Brightness() / 2
+	return core.Color{
+		R: scaleChannel(c.R, white+light.R/2),
+		G: scaleChannel(c.G, white+light.G/2),
+		B: scaleChannel(c.B, white+light.B/2),
+		A: c.A,
+	}
+}
+
+func scaleChannel(c uint8, f float32) uint8 {
+	return uint8(float32(c) * min(f, 1))
+}
+
+// SectorRadius is the light radius needed to reach every corner of the room containing (x, y),
+// so a light placed there fills its own room. Returns fallback outside of a room.
+func (m *Map) SectorRadius(x, y, fallback int) int {
+	if m.Graph == nil {
+		return fallback
+	}
+	room := m.Graph.RoomAt(x, y)
+	if room < 0 {
+		return fallback
+	}
+
+	// Light is cast in a circle, so reach for the furthest corner
+	r := m.Rooms[room]
+	dx := max(x-r.X1, r.X2-x)
+	dy := max(y-r.Y1, r.Y2-y)
+	return int(math.Ceil(math.Sqrt(float64(dx*dx + dy*dy))))
+}
@@ -0,0 +1,124 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/core"
+)
+
+func TestMap_AddLight(t *testing.T) {
+	m := newTestMap(`
+###########
+#.........#
+#...#.....#
+#.........#
+###########`)
+	m.EnableLighting()
+	blocksLight := func(x, y int) bool {
+		return !m.IsWalkable(x, y)
+	}
+
+	m.AddLight(LightSource{X: 6, Y: 2, Radius: 4, Color: core.White, Intensity: 1}, blocksLight)
+
+	if got := m.LightAt(6, 2).Brightness(); got != 1 {
+		t.Errorf("Expected full brightness at the source, got %v", got)
+	}
+	if near, far := m.LightAt(7, 1).Brightness(), m.LightAt(9, 3).Brightness(); near <= far || far <= 0 {
+		t.Errorf("Expected light to fall off with distance, got %v near and %v far", near, far)
+	}
+	if got := m.LightAt(3, 2).Brightness(); got != 0 {
+		t.Errorf("Expected (3,2) to be in the pillar's shadow, got %v", got)
+	}
+	if got := m.LightAt(1, 1).Brightness(); got != 0 {
+		t.Errorf("Expected (1,1) to be outside the radius, got %v", got)
+	}
+
+	// A second, red light adds on top of the first
+	before := m.LightAt(7, 2)
+	m.AddLight(LightSource{X: 7, Y: 3, Radius: 3, Color: core.Red, Intensity: 1}, blocksLight)
+	after := m.LightAt(7, 2)
+	if after.R <= before.R || after.G != before.G {
+		t.Errorf("Expected only the red channel to grow, went from %+v to %+v", before, after)
+	}
+
+	m.ClearLight()
+	if got := m.LightAt(6, 2).Brightness(); got != 0 {
+		t.Errorf("Expected darkness after ClearLight, got %v", got)
+	}
+}
+
+func TestComputeFOV_OnlyRevealsLitTiles(t *testing.T) {
+	m := newTestMap(`
+...........
+...........
+...........`)
+	m.FOV = FOVShadowcast
+	m.EnableLighting()
+	blocksLight := func(x, y int) bool {
+		return !m.IsWalkable(x, y)
+	}
+
+	// A torch around the player and a lamp at the far end, with darkness in between
+	m.AddLight(LightSource{X: 1, Y: 1, Radius: 2, Color: core.White, Intensity: 1}, blocksLight)
+	m.AddLight(LightSource{X: 9, Y: 1, Radius: 1, Color: core.Red, Intensity: 1}, blocksLight)
+	m.ComputeFOV(1, 1, 20, blocksLight)
+
+	t.Logf("\n%s", InspectVisibility(m, 1, 1))
+
+	for _, x := range []int{0, 2, 3, 8, 9, 10} {
+		if !m.GetTile(x, 1).Visible {
+			t.Errorf("Expected lit tile (%d,1) to be visible", x)
+		}
+	}
+	for _, x := range []int{5, 6} {
+		if m.GetTile(x, 1).Visible {
+			t.Errorf("Expected dark tile (%d,1) to stay hidden", x)
+		}
+	}
+}
+
+func TestTintColor(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  core.Color
+		light TileLight
+		want  core.Color
+	}{
+		{"darkness", core.White, TileLight{}, core.Color{R: 0, G: 0, B: 0, A: 255}},
+		{"full-white", core.Green, TileLight{R: 1, G: 1, B: 1}, core.Green},
+		{"overlit-clamps", core.Gray, TileLight{R: 3, G: 3, B: 3}, core.Gray},
+		{"red-on-green-dims", core.Green, TileLight{R: 1}, core.Color{R: 0, G: 127, B: 0, A: 255}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TintColor(tt.base, tt.light); got != tt.want {
+				t.Errorf("TintColor(%v, %+v) = %v, want %v", tt.base, tt.light, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMap_SectorRadius(t *testing.T) {
+	m := newTestFacility()
+	m.Graph = BuildRoomGraph(m, 2, 2)
+	px, py := 8, 2 // Off-centre in the big room
+
+	room := m.Graph.RoomAt(px, py)
+	if room < 0 {
+		t.Fatalf("Expected the spawn to be in a room")
+	}
+	radius := m.SectorRadius(px, py, 3)
+
+	r := m.Rooms[room]
+	for _, c := range [][2]int{{r.X1, r.Y1}, {r.X2, r.Y1}, {r.X1, r.Y2}, {r.X2, r.Y2}} {
+		dx, dy := c[0]-px, c[1]-py
+		if dx*dx+dy*dy > radius*radius {
+			t.Errorf("Radius %d doesn't reach corner %v of room %+v", radius, c, r)
+		}
+	}
+
+	if got := m.SectorRadius(-1, -1, 3); got != 3 {
+		t.Errorf("Expected the fallback outside of a room, got %d", got)
+	}
+}
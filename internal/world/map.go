@@ -5,11 +5,25 @@ import (
 )
 
 type Map struct {
-	Tiles  []Tile
-	Rooms  []Rect
-	Doors  []entity.Point
-	Width  int
-	Height int
+	Tiles    []Tile
+	Rooms    []Rect
+	Doors    []entity.Point
+	Graph    *RoomGraph     // How the rooms connect and what they are for
+	Conduits []bool         // Tiles wired into the power network, nil if nothing is wired
+	Breaches []entity.Point // Holes in the hull, venting their tile into space
+	Air      *Atmosphere    // Gas on every tile, nil until EnableAtmosphere
+	Fire     *FireLayer     // Fires burning on every tile, nil until EnableFire
+	Water    *Water         // Standing water on every tile, nil until EnableWater
+	Seed     uint64         // The generator seed, 0 for hand-authored maps
+	Revision uint64         // Bumped whenever what can be walked through changes, see BumpRevision
+	Width    int
+	Height   int
+
+	FOV        FOVAlgorithm // Which algorithm ComputeFOV uses
+	Light      []TileLight  // Light reaching each tile this frame, nil when lighting is disabled
+	lightSeen  []uint32     // Which AddLight call last lit each tile, compared against lightGen
+	lightGen   uint32       // Bumped by every AddLight call
+	visibleSet []int        // Indices of every tile currently marked Visible
 }
 
 func NewMap(width, height int) *Map {
@@ -40,6 +54,13 @@ func (m *Map) GetIndexFromPoint(p entity.Point) int {
 
 func (m *Map) SetTile(x, y int, tile Tile) {
 	m.Tiles[x+y*m.Width] = tile
+	m.Revision++
+}
+
+// BumpRevision marks the map's passability as changed. SetTile does it for the tiles, whoever
+// moves Solid entities around (doors, as they open and close) calls it for those.
+func (m *Map) BumpRevision() {
+	m.Revision++
 }
 
 func (m *Map) GetTile(x, y int) *Tile {
@@ -51,41 +72,77 @@ func (m *Map) GetTile(x, y int) *Tile {
 	return &m.Tiles[x+y*m.Width]
 }
 
-func (m *Map) ComputeFOV(playerX, playerY int, radius int, blocksLight func(x, y int) bool, powerOn bool) {
-	for i := range m.Tiles {
-		if powerOn {
-			m.Tiles[i].Visible = true
-			m.Tiles[i].Explored = true
-			m.Tiles[i].Distance = 0
-		} else {
-			m.Tiles[i].Visible = false
+// RoomArea is the room containing (x, y), or the square reaching that far around it outside of a room.
+// Room-wide devices like suppressors and pumps work on this area.
+func (m *Map) RoomArea(x, y, reach int) Rect {
+	for _, room := range m.Rooms {
+		if room.Contains(x, y) {
+			return room
 		}
 	}
+	return Rect{X1: x - reach, Y1: y - reach, X2: x + reach, Y2: y + reach}
+}
 
-	if powerOn {
-		return // The whole map is lit, no need to cast rays!
+// ComputeFOV marks the tiles the player can see from (playerX, playerY) as Visible (and Explored).
+// With lighting enabled a tile also has to be lit to be seen, so radius is how far the player can
+// see into lit areas rather than how far their torch reaches. Only the tiles that were visible
+// after the previous call are cleared, so the cost scales with the radius instead of the map size.
+func (m *Map) ComputeFOV(playerX, playerY int, radius int, blocksLight func(x, y int) bool) {
+	m.clearVisible()
+
+	switch m.FOV {
+	case FOVShadowcast:
+		castShadows(m, playerX, playerY, radius, blocksLight, func(x, y, dist int) {
+			m.revealIfLit(x+y*m.Width, dist)
+		})
+	default:
+		// clamp the bounding box so we stay inside the map
+		minX := max(0, playerX-radius)
+		maxX := min(m.Width-1, playerX+radius)
+		minY := max(0, playerY-radius)
+		maxY := min(m.Height-1, playerY+radius)
+
+		// cast rays only to the parts of the perimeter that exist
+		for x := minX; x <= maxX; x++ {
+			m.castRay(playerX, playerY, x, minY, blocksLight) // Top edge
+			m.castRay(playerX, playerY, x, maxY, blocksLight) // Bottom edge
+		}
+
+		for y := minY; y <= maxY; y++ {
+			m.castRay(playerX, playerY, minX, y, blocksLight) // Left edge
+			m.castRay(playerX, playerY, maxX, y, blocksLight) // Right edge
+		}
 	}
 
-	// clamp the bounding box so we stay inside the map
-	minX := max(0, playerX-radius)
-	maxX := min(m.Width-1, playerX+radius)
-	minY := max(0, playerY-radius)
-	maxY := min(m.Height-1, playerY+radius)
+	// the player can always see their own tile
+	m.reveal(playerX+playerY*m.Width, 0)
+}
 
-	// cast rays only to the parts of the perimeter that exist
-	for x := minX; x <= maxX; x++ {
-		m.castRay(playerX, playerY, x, minY, blocksLight) // Top edge
-		m.castRay(playerX, playerY, x, maxY, blocksLight) // Bottom edge
+// clearVisible hides every tile revealed by the previous ComputeFOV.
+func (m *Map) clearVisible() {
+	for _, idx := range m.visibleSet {
+		m.Tiles[idx].Visible = false
 	}
+	m.visibleSet = m.visibleSet[:0]
+}
 
-	for y := minY; y <= maxY; y++ {
-		m.castRay(playerX, playerY, minX, y, blocksLight) // Left edge
-		m.castRay(playerX, playerY, maxX, y, blocksLight) // Right edge
+// reveal marks a tile as Visible and Explored, remembering it so clearVisible can undo it.
+func (m *Map) reveal(idx, dist int) {
+	tile := &m.Tiles[idx]
+	if !tile.Visible {
+		tile.Visible = true
+		m.visibleSet = append(m.visibleSet, idx)
 	}
+	tile.Explored = true
+	tile.Distance = dist
+}
 
-	// the player can always see their own tile
-	m.Tiles[playerX+playerY*m.Width].Visible = true
-	m.Tiles[playerX+playerY*m.Width].Distance = 0
+// revealIfLit reveals a tile in line of sight, unless lighting is enabled and it's too dark to make out.
+func (m *Map) revealIfLit(idx, dist int) {
+	if m.Light != nil && m.Light[idx].Brightness() < MinVisibleLight {
+		return
+	}
+	m.reveal(idx, dist)
 }
 
 func (m *Map) castRay(x1, y1, x2, y2 int, blocksLight func(x, y int) bool) {
@@ -97,11 +154,6 @@ func (m *Map) castRay(x1, y1, x2, y2 int, blocksLight func(x, y int) bool) {
 			return false
 		}
 
-		idx := x + y*m.Width
-
-		m.Tiles[idx].Visible = true
-		m.Tiles[idx].Explored = true
-
 		// Approximate distance using chebyshev/manhattan or simple max component
 		dx := x - x1
 		if dx < 0 {
@@ -115,7 +167,7 @@ func (m *Map) castRay(x1, y1, x2, y2 int, blocksLight func(x, y int) bool) {
 		if dy > dx {
 			dist = dy
 		}
-		m.Tiles[idx].Distance = dist
+		m.revealIfLit(x+y*m.Width, dist)
 
 		// Use the callback to decide if light passes through
 		if blocksLight(x, y) {
@@ -3,6 +3,8 @@ package world
 import (
 	"strings"
 	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
 )
 
 func BenchmarkGetLine_Diagonal(b *testing.B) {
@@ -22,7 +24,7 @@ func BenchmarkComputeFOV_SmallRadius(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		m.ComputeFOV(40, 20, 5, func(x, y int) bool {
 			return !m.IsWalkable(x, y)
-		}, false)
+		})
 	}
 }
 
@@ -35,7 +37,7 @@ func BenchmarkComputeFOV_LargeRadius(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		m.ComputeFOV(50, 50, 20, func(x, y int) bool {
 			return !m.IsWalkable(x, y)
-		}, false)
+		})
 	}
 }
 
@@ -51,3 +53,229 @@ func BenchmarkMapReset_Large(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkComputeFOV_Shadowcast_SmallRadius(b *testing.B) {
+	m := newTestMap(strings.Repeat(".", 80*40))
+	m.Width = 80
+	m.Height = 40
+	m.FOV = FOVShadowcast
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ComputeFOV(40, 20, 5, func(x, y int) bool {
+			return !m.IsWalkable(x, y)
+		})
+	}
+}
+
+func BenchmarkComputeFOV_Shadowcast_LargeRadius(b *testing.B) {
+	m := newTestMap(strings.Repeat(".", 100*100))
+	m.Width = 100
+	m.Height = 100
+	m.FOV = FOVShadowcast
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ComputeFOV(50, 50, 20, func(x, y int) bool {
+			return !m.IsWalkable(x, y)
+		})
+	}
+}
+
+// Pillars every few tiles give the shadowcaster lots of rows to split, which is its worst case
+func BenchmarkComputeFOV_Shadowcast_Pillars(b *testing.B) {
+	m := pillarBenchMap(100, 100)
+	m.FOV = FOVShadowcast
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ComputeFOV(50, 50, 20, func(x, y int) bool {
+			return !m.IsWalkable(x, y)
+		})
+	}
+}
+
+func BenchmarkComputeFOV_Raycast_Pillars(b *testing.B) {
+	m := pillarBenchMap(100, 100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.ComputeFOV(50, 50, 20, func(x, y int) bool {
+			return !m.IsWalkable(x, y)
+		})
+	}
+}
+
+func pillarBenchMap(width, height int) *Map {
+	var sb strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x%4 == 1 && y%4 == 1 {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		if y < height-1 {
+			sb.WriteByte('\n')
+		}
+	}
+	return newTestMap(sb.String())
+}
+
+func BenchmarkAtmosphere_Step_400x200(b *testing.B) {
+	m, px, py := NewFacilityGenerator(12345).Generate(400, 200)
+	m.Breaches = append(m.Breaches, entity.Point{X: px, Y: py})
+	m.EnableAtmosphere()
+	blocked := make([]bool, m.Width*m.Height)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Air.Step(m, blocked)
+	}
+}
+
+func BenchmarkNoise_Emit_400x200(b *testing.B) {
+	m, px, py := NewFacilityGenerator(12345).Generate(400, 200)
+	nm := NewNoiseMap(m.Width, m.Height)
+	blocked := make([]bool, m.Width*m.Height)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nm.Clear()
+		nm.Emit(m, px, py, 24, blocked)
+	}
+}
+
+// swarmBenchTargets spreads agents over the rooms of a map, all heading for the same goal.
+func swarmBenchTargets(m *Map, agents int) []entity.Point {
+	starts := make([]entity.Point, 0, agents)
+	for i := 0; len(starts) < agents; i++ {
+		x, y := m.Rooms[i%len(m.Rooms)].Center()
+		starts = append(starts, entity.Point{X: x, Y: y})
+	}
+	return starts
+}
+
+func BenchmarkSwarm_AStar_100Agents(b *testing.B) {
+	m, px, py := NewFacilityGenerator(12345).Generate(400, 200)
+	pf := NewPathfinder(m.Width, m.Height)
+	starts := swarmBenchTargets(m, 100)
+	goal := entity.Point{X: px, Y: py}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, s := range starts {
+			pf.FindPath(m, s, goal, m.IsWalkable)
+		}
+	}
+}
+
+func BenchmarkSwarm_FlowField_100Agents(b *testing.B) {
+	m, px, py := NewFacilityGenerator(12345).Generate(400, 200)
+	f := NewFlowField(m.Width, m.Height)
+	starts := swarmBenchTargets(m, 100)
+	goals := []entity.Point{{X: px, Y: py}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Compute(goals, m.IsWalkable, nil)
+		for _, s := range starts {
+			f.Next(s.X, s.Y)
+		}
+	}
+}
+
+// BenchmarkFindPath_400x200 runs the astar_test.go layouts scaled up to 400x200, once with plain A*
+// and once with Jump Point Search, which is what FindPath uses for them.
+func BenchmarkFindPath_400x200(b *testing.B) {
+	open := setupTestMap(400, 200, nil)
+
+	// A wall down the middle with a single gap, like TestVisual_PathfindingCorridor
+	var walls []entity.Point
+	for y := 0; y < 200; y++ {
+		if y != 100 {
+			walls = append(walls, entity.Point{X: 200, Y: y})
+		}
+	}
+	corridor := setupTestMap(400, 200, walls)
+
+	facility, px, py := NewFacilityGenerator(12345).Generate(400, 200)
+	fx, fy := facility.Rooms[len(facility.Rooms)-1].Center()
+
+	cases := []struct {
+		name          string
+		m             *Map
+		start, target entity.Point
+	}{
+		{"Open", open, entity.Point{X: 1, Y: 1}, entity.Point{X: 398, Y: 198}},
+		{"Corridor", corridor, entity.Point{X: 20, Y: 150}, entity.Point{X: 380, Y: 10}},
+		{"Facility", facility, entity.Point{X: px, Y: py}, entity.Point{X: fx, Y: fy}},
+	}
+
+	for _, c := range cases {
+		pf := NewPathfinder(c.m.Width, c.m.Height)
+		for _, search := range []struct {
+			name string
+			find func(pf *Pathfinder, dst []entity.Point, m *Map, start, target entity.Point, isWalkable func(x, y int) bool, opts PathOptions) []entity.Point
+		}{
+			{"AStar", (*Pathfinder).findAStarPath},
+			{"JPS", (*Pathfinder).findJumpPath},
+		} {
+			b.Run(c.name+"/"+search.name, func(b *testing.B) {
+				isWalkable := c.m.IsWalkable
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if search.find(pf, nil, c.m, c.start, c.target, isWalkable, PathOptions{}) == nil {
+						b.Fatal("no path")
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkHierarchy_CrossFacility_400x200 walks from the spawn to the room farthest from it.
+// Waypoints is the abstract search alone, what the autopilot pays up front; Refined turns the
+// whole route into tiles as well.
+func BenchmarkHierarchy_CrossFacility_400x200(b *testing.B) {
+	m, px, py := NewFacilityGenerator(12345).Generate(400, 200)
+	start := entity.Point{X: px, Y: py}
+
+	target := start
+	for _, r := range m.Rooms {
+		x, y := r.Center()
+		if p := (entity.Point{X: x, Y: y}); ManhattanDistance(start, p) > ManhattanDistance(start, target) {
+			target = p
+		}
+	}
+
+	pf := NewPathfinder(m.Width, m.Height)
+	h := NewHierarchy(m, DefaultClusterSize, m.IsWalkable)
+	h.Refresh()
+
+	searches := []struct {
+		name string
+		find func() []entity.Point
+	}{
+		{"AStar", func() []entity.Point {
+			return pf.findAStarPath(nil, m, start, target, m.IsWalkable, PathOptions{})
+		}},
+		{"JPS", func() []entity.Point {
+			return pf.findJumpPath(nil, m, start, target, m.IsWalkable, PathOptions{})
+		}},
+		{"Waypoints", func() []entity.Point { return h.FindWaypoints(start, target) }},
+		{"Refined", func() []entity.Point { return h.FindPath(start, target) }},
+	}
+
+	for _, s := range searches {
+		b.Run(s.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if s.find() == nil {
+					b.Fatal("no path")
+				}
+			}
+		})
+	}
+}
@@ -185,29 +185,37 @@ func TestComputeFOV(t *testing.T) {
 		},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			m := newTestMap(layouts[tt.layoutName])
-
-			m.ComputeFOV(tt.playerX, tt.playerY, tt.radius, func(x, y int) bool {
-				return !m.IsWalkable(x, y)
-			}, false)
-
-			// Visual Debug Output
-			t.Logf("\nTest: %s\nPlayer at (%d,%d), Radius: %d\n%s",
-				tt.name, tt.playerX, tt.playerY, tt.radius, InspectVisibility(m, tt.playerX, tt.playerY))
+	algorithms := map[string]FOVAlgorithm{
+		"raycast":    FOVRaycast,
+		"shadowcast": FOVShadowcast,
+	}
 
-			// Assertions
-			for _, p := range tt.mustSee {
-				if !m.Tiles[p.X+p.Y*m.Width].Visible {
-					t.Errorf("Expected point {%d, %d} to be visible", p.X, p.Y)
+	for algoName, algo := range algorithms {
+		for _, tt := range tests {
+			t.Run(algoName+"/"+tt.name, func(t *testing.T) {
+				m := newTestMap(layouts[tt.layoutName])
+				m.FOV = algo
+
+				m.ComputeFOV(tt.playerX, tt.playerY, tt.radius, func(x, y int) bool {
+					return !m.IsWalkable(x, y)
+				})
+
+				// Visual Debug Output
+				t.Logf("\nTest: %s\nPlayer at (%d,%d), Radius: %d\n%s",
+					tt.name, tt.playerX, tt.playerY, tt.radius, InspectVisibility(m, tt.playerX, tt.playerY))
+
+				// Assertions
+				for _, p := range tt.mustSee {
+					if !m.Tiles[p.X+p.Y*m.Width].Visible {
+						t.Errorf("Expected point {%d, %d} to be visible", p.X, p.Y)
+					}
 				}
-			}
-			for _, p := range tt.mustNotSee {
-				if m.Tiles[p.X+p.Y*m.Width].Visible {
-					t.Errorf("Expected point {%d, %d} to be hidden", p.X, p.Y)
+				for _, p := range tt.mustNotSee {
+					if m.Tiles[p.X+p.Y*m.Width].Visible {
+						t.Errorf("Expected point {%d, %d} to be hidden", p.X, p.Y)
+					}
 				}
-			}
-		})
+			})
+		}
 	}
 }
@@ -0,0 +1,360 @@
+package world
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+/*
+	Map files describe a hand-authored level in up to three sections:
+
+	; Lines starting with ';' outside the [map] section are comments.
+	[legend]
+	G = floor generator     <char> = <tile> [entity kind]
+	T = floor terminal
+	[map]
+	#########
+	#@..G...#
+	####+####
+	#.......#
+	#########
+	[entities]
+	terminal 5 3            <kind> <x> <y> [args...]
+
+	The legend always knows '#' (wall), '.' (floor), ' ' (empty), '+' (floor with a door)
+	and '@' (floor with the player spawn); a map file can override any of them.
+	Rows shorter than the widest one are padded with empty space.
+
+	The "conduit" entity wires a tile into the power network. A map without any conduits
+	has every walkable tile wired, so it forms a single grid. A "breach" is a hole in the
+	hull that vents the air on its tile into space.
+*/
+
+const (
+	mapFileEntityDoor    = "door"
+	mapFileEntitySpawn   = "spawn"
+	mapFileEntityConduit = "conduit"
+	mapFileEntityBreach  = "breach"
+)
+
+// MapEntityKinds lists the entity kinds the game knows how to spawn from a map file.
+var MapEntityKinds = []string{
+	"generator", "terminal", "lamp", "light", "breaker", "lifesupport",
+	"leak", "suppressor", "fuel", "main", "pump",
+}
+
+// IsMapEntityKind reports whether the game can spawn an entity of the given kind from a map file.
+func IsMapEntityKind(kind string) bool {
+	return slices.Contains(MapEntityKinds, kind)
+}
+
+// MapEntity is an entity placed by a map file, either through the legend or the [entities] section.
+type MapEntity struct {
+	Kind string
+	X, Y int
+	Args []string
+}
+
+// MapFile is a level loaded from a map file, ready to be handed to the engine.
+type MapFile struct {
+	Map            *Map
+	SpawnX, SpawnY int
+	Entities       []MapEntity // Everything except doors (see Map.Doors) and the spawn
+}
+
+type legendEntry struct {
+	tile   Tile
+	entity string
+}
+
+func defaultLegend() map[rune]legendEntry {
+	return map[rune]legendEntry{
+		'#': {tile: Tile{Type: TileTypeWall}},
+		'.': {tile: Tile{Type: TileTypeFloor, Walkable: true}},
+		' ': {tile: Tile{Type: TileTypeEmpty}},
+		'+': {tile: Tile{Type: TileTypeFloor, Walkable: true}, entity: mapFileEntityDoor},
+		'@': {tile: Tile{Type: TileTypeFloor, Walkable: true}, entity: mapFileEntitySpawn},
+	}
+}
+
+// LoadMapFile reads a hand-authored map from disk.
+func LoadMapFile(path string) (*MapFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	mf, err := ParseMapFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mf, nil
+}
+
+// ParseMapFile builds a Map from the map file format, computing wall bitmasks, rooms and the room graph.
+func ParseMapFile(r io.Reader) (*MapFile, error) {
+	legend := defaultLegend()
+	var rows []string
+	var entityLines []string
+	var entityLineNumbers []int
+
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.ToLower(trimmed[1 : len(trimmed)-1])
+			if section != "legend" && section != "map" && section != "entities" {
+				return nil, fmt.Errorf("line %d: unknown section %q", lineNumber, trimmed)
+			}
+			continue
+		}
+
+		switch section {
+		case "map":
+			rows = append(rows, line)
+		case "legend":
+			if trimmed == "" || strings.HasPrefix(trimmed, ";") {
+				continue
+			}
+			char, entry, err := parseLegendLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			legend[char] = entry
+		case "entities":
+			if trimmed == "" || strings.HasPrefix(trimmed, ";") {
+				continue
+			}
+			entityLines = append(entityLines, trimmed)
+			entityLineNumbers = append(entityLineNumbers, lineNumber)
+		default:
+			if trimmed != "" && !strings.HasPrefix(trimmed, ";") {
+				return nil, fmt.Errorf("line %d: content outside of a section", lineNumber)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Blank lines around the grid are just formatting, not empty rows of the map
+	for len(rows) > 0 && rows[0] == "" {
+		rows = rows[1:]
+	}
+	for len(rows) > 0 && rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("map file has no [map] section")
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, utf8.RuneCountInString(row))
+	}
+
+	m := NewMap(width, len(rows))
+	mf := &MapFile{Map: m, SpawnX: -1, SpawnY: -1}
+
+	for y, row := range rows {
+		x := 0
+		for _, char := range row {
+			entry, ok := legend[char]
+			if !ok {
+				return nil, fmt.Errorf("map row %d: no legend entry for %q", y+1, char)
+			}
+			m.SetTile(x, y, entry.tile)
+
+			switch entry.entity {
+			case "":
+			case mapFileEntityDoor:
+				m.Doors = append(m.Doors, entity.Point{X: x, Y: y})
+			case mapFileEntitySpawn:
+				mf.SpawnX, mf.SpawnY = x, y
+			case mapFileEntityConduit:
+				m.LayConduit(x, y)
+			case mapFileEntityBreach:
+				m.Breaches = append(m.Breaches, entity.Point{X: x, Y: y})
+			default:
+				mf.Entities = append(mf.Entities, MapEntity{Kind: entry.entity, X: x, Y: y})
+			}
+			x++
+		}
+	}
+
+	for i, line := range entityLines {
+		ent, err := parseEntityLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", entityLineNumbers[i], err)
+		}
+		if m.GetTile(ent.X, ent.Y) == nil {
+			return nil, fmt.Errorf("line %d: %s at (%d,%d) is outside the map", entityLineNumbers[i], ent.Kind, ent.X, ent.Y)
+		}
+
+		switch ent.Kind {
+		case mapFileEntityDoor:
+			m.Doors = append(m.Doors, entity.Point{X: ent.X, Y: ent.Y})
+		case mapFileEntitySpawn:
+			mf.SpawnX, mf.SpawnY = ent.X, ent.Y
+		case mapFileEntityConduit:
+			m.LayConduit(ent.X, ent.Y)
+		case mapFileEntityBreach:
+			m.Breaches = append(m.Breaches, entity.Point{X: ent.X, Y: ent.Y})
+		default:
+			mf.Entities = append(mf.Entities, ent)
+		}
+	}
+
+	if mf.SpawnX < 0 {
+		return nil, fmt.Errorf("map has no spawn point")
+	}
+	if !m.IsWalkable(mf.SpawnX, mf.SpawnY) {
+		return nil, fmt.Errorf("spawn point (%d,%d) is not walkable", mf.SpawnX, mf.SpawnY)
+	}
+
+	if m.Conduits == nil {
+		for i, tile := range m.Tiles {
+			if tile.Walkable {
+				m.LayConduit(i%m.Width, i/m.Width)
+			}
+		}
+	}
+
+	calculateWallBitmasks(m)
+	m.Rooms = detectRooms(m)
+	m.Graph = BuildRoomGraph(m, mf.SpawnX, mf.SpawnY)
+
+	return mf, nil
+}
+
+// Clone copies the level so it can be played without changing mf, for starting it over.
+// Only what ParseMapFile fills in is copied; the room graph is shared since play never changes it.
+func (mf *MapFile) Clone() *MapFile {
+	m := mf.Map
+	clone := *mf
+	clone.Map = &Map{
+		Tiles:    slices.Clone(m.Tiles),
+		Rooms:    slices.Clone(m.Rooms),
+		Doors:    slices.Clone(m.Doors),
+		Graph:    m.Graph,
+		Conduits: slices.Clone(m.Conduits),
+		Breaches: slices.Clone(m.Breaches),
+		Seed:     m.Seed,
+		Revision: m.Revision,
+		Width:    m.Width,
+		Height:   m.Height,
+	}
+	clone.Entities = slices.Clone(mf.Entities)
+	return &clone
+}
+
+// parseLegendLine reads "<char> = <tile> [entity]". The key is the very first character
+// of the line, so even a space can be given a meaning.
+func parseLegendLine(line string) (rune, legendEntry, error) {
+	char, size := utf8.DecodeRuneInString(line)
+	value, ok := strings.CutPrefix(strings.TrimSpace(line[size:]), "=")
+	if !ok {
+		return 0, legendEntry{}, fmt.Errorf("legend entry %q must be '<char> = <tile> [entity]'", line)
+	}
+	key := string(char)
+
+	fields := strings.Fields(value)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, legendEntry{}, fmt.Errorf("legend entry for %q must be '<tile> [entity]'", key)
+	}
+
+	var entry legendEntry
+	switch strings.ToLower(fields[0]) {
+	case "wall":
+		entry.tile = Tile{Type: TileTypeWall}
+	case "floor":
+		entry.tile = Tile{Type: TileTypeFloor, Walkable: true}
+	case "empty":
+		entry.tile = Tile{Type: TileTypeEmpty}
+	default:
+		return 0, legendEntry{}, fmt.Errorf("unknown tile %q in legend entry for %q", fields[0], key)
+	}
+	if len(fields) == 2 {
+		entry.entity = strings.ToLower(fields[1])
+	}
+
+	return char, entry, nil
+}
+
+// parseEntityLine reads "<kind> <x> <y> [args...]".
+func parseEntityLine(line string) (MapEntity, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return MapEntity{}, fmt.Errorf("entity %q must be '<kind> <x> <y> [args...]'", line)
+	}
+
+	x, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return MapEntity{}, fmt.Errorf("entity %q has an invalid x: %w", line, err)
+	}
+	y, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return MapEntity{}, fmt.Errorf("entity %q has an invalid y: %w", line, err)
+	}
+
+	return MapEntity{Kind: strings.ToLower(fields[0]), X: x, Y: y, Args: fields[3:]}, nil
+}
+
+// detectRooms finds the rooms of a hand-built map. A floor tile belongs to a room if it is part
+// of a 2x2 block of floor, which keeps 1-wide corridors and doorways out. Each connected group
+// of room tiles becomes one room, sized to its bounding box.
+func detectRooms(m *Map) []Rect {
+	inRoom := make([]bool, m.Width*m.Height)
+	for y := 0; y+1 < m.Height; y++ {
+		for x := 0; x+1 < m.Width; x++ {
+			if m.IsWalkable(x, y) && m.IsWalkable(x+1, y) && m.IsWalkable(x, y+1) && m.IsWalkable(x+1, y+1) {
+				inRoom[m.GetIndex(x, y)] = true
+				inRoom[m.GetIndex(x+1, y)] = true
+				inRoom[m.GetIndex(x, y+1)] = true
+				inRoom[m.GetIndex(x+1, y+1)] = true
+			}
+		}
+	}
+
+	var rooms []Rect
+	reached := make([]bool, m.Width*m.Height)
+	group := make([]bool, m.Width*m.Height)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			idx := m.GetIndex(x, y)
+			if !inRoom[idx] || reached[idx] {
+				continue
+			}
+
+			room := Rect{X1: x, Y1: y, X2: x, Y2: y}
+			FloodFill(m, x, y, func(px, py int) bool {
+				return inRoom[m.GetIndex(px, py)]
+			}, group)
+
+			for i, ok := range group {
+				if !ok {
+					continue
+				}
+				reached[i] = true
+				px, py := i%m.Width, i/m.Width
+				room.X1, room.Y1 = min(room.X1, px), min(room.Y1, py)
+				room.X2, room.Y2 = max(room.X2, px), max(room.Y2, py)
+			}
+			rooms = append(rooms, room)
+		}
+	}
+
+	return rooms
+}
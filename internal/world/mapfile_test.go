@@ -0,0 +1,167 @@
+package world
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+const testMapFile = `
+; Two rooms joined by a doorway
+[legend]
+G = floor generator
+~ = empty
+[map]
+##########
+#@...#...#
+#....+.G.#
+#....#...#
+##########~~
+[entities]
+terminal 2 3
+lamp 6 1 red 0.5
+`
+
+func TestParseMapFile(t *testing.T) {
+	mf, err := ParseMapFile(strings.NewReader(testMapFile))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	m := mf.Map
+
+	if m.Width != 12 || m.Height != 5 {
+		t.Fatalf("Expected a 12x5 map (padded to the widest row), got %dx%d", m.Width, m.Height)
+	}
+	if mf.SpawnX != 1 || mf.SpawnY != 1 {
+		t.Errorf("Expected spawn at (1,1), got (%d,%d)", mf.SpawnX, mf.SpawnY)
+	}
+	if !reflect.DeepEqual(m.Doors, []entity.Point{{X: 5, Y: 2}}) {
+		t.Errorf("Expected a door at (5,2), got %v", m.Doors)
+	}
+
+	wantEntities := []MapEntity{
+		{Kind: "generator", X: 7, Y: 2},
+		{Kind: "terminal", X: 2, Y: 3, Args: []string{}},
+		{Kind: "lamp", X: 6, Y: 1, Args: []string{"red", "0.5"}},
+	}
+	if !reflect.DeepEqual(mf.Entities, wantEntities) {
+		t.Errorf("Expected entities %+v, got %+v", wantEntities, mf.Entities)
+	}
+
+	if tile := m.GetTile(5, 2); tile.Type != TileTypeFloor || !tile.Walkable {
+		t.Errorf("Expected the doorway to be a walkable floor, got %+v", tile)
+	}
+	if tile := m.GetTile(11, 0); tile.Type != TileTypeEmpty || tile.Walkable {
+		t.Errorf("Expected padding to be empty space, got %+v", tile)
+	}
+	if tile := m.GetTile(10, 4); tile.Type != TileTypeEmpty {
+		t.Errorf("Expected '~' to be overridden as empty, got %+v", tile)
+	}
+
+	// Top-left corner only has walls to the east and south
+	if mask := m.GetTile(0, 0).Bitmask; mask != 6 {
+		t.Errorf("Expected corner bitmask 6, got %d", mask)
+	}
+
+	wantRooms := []Rect{{X1: 1, Y1: 1, X2: 4, Y2: 3}, {X1: 6, Y1: 1, X2: 8, Y2: 3}}
+	if !reflect.DeepEqual(m.Rooms, wantRooms) {
+		t.Errorf("Expected rooms %v, got %v", wantRooms, m.Rooms)
+	}
+	if m.Graph == nil || len(m.Graph.Edges) != 1 || m.Graph.Types[0] != RoomTypeSpawn {
+		t.Errorf("Expected a room graph with the spawn room linked to its neighbour, got %+v", m.Graph)
+	}
+}
+
+func TestParseMapFile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"no map", "[legend]\nG = floor generator\n", "no [map] section"},
+		{"no spawn", "[map]\n###\n#.#\n###\n", "no spawn point"},
+		{"unknown character", "[map]\n#@X#\n", "no legend entry for 'X'"},
+		{"unknown section", "[tiles]\n", "unknown section"},
+		{"unknown tile", "[legend]\nX = lava\n[map]\n@X\n", "unknown tile"},
+		{"bad legend", "[legend]\nX floor\n[map]\n@X\n", "must be '<char> = <tile> [entity]'"},
+		{"entity off the map", "[map]\n@.\n[entities]\nterminal 5 5\n", "outside the map"},
+		{"entity without coordinates", "[map]\n@.\n[entities]\nterminal 1\n", "must be '<kind> <x> <y>"},
+		{"stray content", "hello\n[map]\n@\n", "outside of a section"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMapFile(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadMapFile_Tutorial(t *testing.T) {
+	mf, err := LoadMapFile("../../assets/maps/tutorial.txt")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if report := ValidateConnectivity(mf.Map, mf.SpawnX, mf.SpawnY); !report.Connected() {
+		t.Errorf("Expected the tutorial to be fully connected, got %+v", report)
+	}
+}
+
+func TestParseMapFile_Conduits(t *testing.T) {
+	unwired := "[map]\n#####\n#@..#\n#####\n"
+	mf, err := ParseMapFile(strings.NewReader(unwired))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !mf.Map.HasConduit(1, 1) || !mf.Map.HasConduit(3, 1) || mf.Map.HasConduit(0, 0) {
+		t.Errorf("Expected a map without conduits to wire every walkable tile")
+	}
+
+	wired := "[legend]\n= = floor conduit\n[map]\n#####\n#@.=#\n#####\n[entities]\nconduit 2 1\n"
+	mf, err = ParseMapFile(strings.NewReader(wired))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mf.Map.HasConduit(1, 1) || !mf.Map.HasConduit(2, 1) || !mf.Map.HasConduit(3, 1) {
+		t.Errorf("Expected only the listed tiles to be wired")
+	}
+	if len(mf.Entities) != 0 {
+		t.Errorf("Expected conduits not to be reported as entities, got %+v", mf.Entities)
+	}
+}
+
+func TestMapFile_Clone(t *testing.T) {
+	mf, err := ParseMapFile(strings.NewReader(testMapFile))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clone := mf.Clone()
+	if !reflect.DeepEqual(clone, mf) {
+		t.Fatalf("Expected the clone to match the original")
+	}
+
+	// Playing the clone mustn't reach back into the original
+	clone.Map.SetTile(2, 2, Tile{Type: TileTypeWall})
+	clone.Map.Doors[0] = entity.Point{}
+	clone.Map.Breaches = append(clone.Map.Breaches, entity.Point{X: 3, Y: 3})
+	clone.Map.Conduits[clone.Map.GetIndex(1, 1)] = false
+	clone.Entities[0].Kind = "pump"
+
+	if !mf.Map.IsWalkable(2, 2) {
+		t.Errorf("Expected the original's tiles to be untouched")
+	}
+	if mf.Map.Doors[0] != (entity.Point{X: 5, Y: 2}) || len(mf.Map.Breaches) != 0 {
+		t.Errorf("Expected the original's doors and breaches to be untouched, got %v and %v", mf.Map.Doors, mf.Map.Breaches)
+	}
+	if !mf.Map.HasConduit(1, 1) {
+		t.Errorf("Expected the original's conduits to be untouched")
+	}
+	if mf.Entities[0].Kind == "pump" {
+		t.Errorf("Expected the original's entities to be untouched")
+	}
+}
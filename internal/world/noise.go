@@ -0,0 +1,170 @@
+package world
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+/*
+	Noise is flooded out from every sound with Dijkstra's algorithm, the loudness dropping by the
+	cost of each tile it passes through. Open floor costs 1, a closed door muffles it a lot more and
+	a wall more still, so a shout carries down a corridor but only a bang makes it through a bulkhead.
+	Empty tiles are vacuum and carry nothing.
+
+	Costs are small integers, so the open set is a bucket queue indexed by distance rather than a
+	heap: pushing and popping are O(1) and the buckets are reused between sounds.
+*/
+
+const (
+	floorNoiseCost = 1
+	doorNoiseCost  = 6  // Closed doors, and anything else blocking a walkable tile
+	wallNoiseCost  = 10 // Sound through a bulkhead
+)
+
+// NoiseMap is how loud every tile is this tick. Levels from different sounds don't add up,
+// a tile hears whichever sound reaches it loudest.
+type NoiseMap struct {
+	Level []int
+
+	width   int
+	dist    []int
+	seen    []uint32 // Which Emit last reached each tile, compared against gen
+	gen     uint32
+	buckets [][]int
+	heard   []int // Tiles with a level above zero, so Clear doesn't have to sweep the map
+}
+
+// NewNoiseMap makes a silent noise map for a map of the given size.
+func NewNoiseMap(width, height int) *NoiseMap {
+	n := width * height
+	return &NoiseMap{
+		Level: make([]int, n),
+		width: width,
+		dist:  make([]int, n),
+		seen:  make([]uint32, n),
+	}
+}
+
+// Clear silences the map for the next tick.
+func (nm *NoiseMap) Clear() {
+	for _, i := range nm.heard {
+		nm.Level[i] = 0
+	}
+	nm.heard = nm.heard[:0]
+}
+
+// Emit makes a sound at (x, y) and spreads it over the map. blocked marks walkable tiles that
+// muffle sound like a closed door; it may be nil.
+func (nm *NoiseMap) Emit(m *Map, x, y, loudness int, blocked []bool) {
+	if loudness <= 0 || m.GetTile(x, y) == nil {
+		return
+	}
+
+	nm.gen++
+	if len(nm.buckets) < loudness {
+		nm.buckets = append(nm.buckets, make([][]int, loudness-len(nm.buckets))...)
+	}
+	for d := range nm.buckets {
+		nm.buckets[d] = nm.buckets[d][:0]
+	}
+
+	w, h := m.Width, m.Height
+	start := m.GetIndex(x, y)
+	nm.seen[start] = nm.gen
+	nm.dist[start] = 0
+	nm.buckets[0] = append(nm.buckets[0], start)
+
+	for d := 0; d < loudness; d++ {
+		for _, i := range nm.buckets[d] {
+			if nm.dist[i] != d {
+				continue // Stale, a cheaper route got here first
+			}
+			nm.hear(i, loudness-d)
+
+			cx, cy := i%w, i/w
+			for _, n := range [4]int{i - w, i + w, i - 1, i + 1} {
+				switch {
+				case n == i-w && cy == 0, n == i+w && cy == h-1, n == i-1 && cx == 0, n == i+1 && cx == w-1:
+					continue
+				}
+
+				cost := nm.cost(m, n, blocked)
+				if cost == 0 {
+					continue
+				}
+				nd := d + cost
+				if nd >= loudness || (nm.seen[n] == nm.gen && nm.dist[n] <= nd) {
+					continue
+				}
+				nm.seen[n] = nm.gen
+				nm.dist[n] = nd
+				nm.buckets[nd] = append(nm.buckets[nd], n)
+			}
+		}
+	}
+}
+
+// cost is how much sound is lost getting into a tile, 0 if it can't get in at all.
+func (nm *NoiseMap) cost(m *Map, i int, blocked []bool) int {
+	tile := &m.Tiles[i]
+	switch {
+	case tile.Walkable && blocked != nil && blocked[i]:
+		return doorNoiseCost
+	case tile.Walkable:
+		return floorNoiseCost
+	case tile.Type == TileTypeWall:
+		return wallNoiseCost
+	default:
+		return 0 // Vacuum
+	}
+}
+
+// hear raises a tile to level if that's louder than what it already hears.
+func (nm *NoiseMap) hear(i, level int) {
+	if level <= nm.Level[i] {
+		return
+	}
+	if nm.Level[i] == 0 {
+		nm.heard = append(nm.heard, i)
+	}
+	nm.Level[i] = level
+}
+
+// At is how loud it is on a tile, 0 off the map.
+func (nm *NoiseMap) At(x, y int) int {
+	if x < 0 || x >= nm.width || y < 0 || y >= len(nm.Level)/nm.width {
+		return 0
+	}
+	return nm.Level[y*nm.width+x]
+}
+
+// Loudest returns the loudest tile on the map, or false if everything is quiet.
+func (nm *NoiseMap) Loudest() (entity.Point, int, bool) {
+	best, level := -1, 0
+	for _, i := range nm.heard {
+		if nm.Level[i] > level || (nm.Level[i] == level && i < best) {
+			best, level = i, nm.Level[i]
+		}
+	}
+	if best < 0 {
+		return entity.Point{}, 0, false
+	}
+	return entity.Point{X: best % nm.width, Y: best / nm.width}, level, true
+}
+
+// Toward is the neighbouring tile that is louder than (x, y), for something that heard a noise and
+// wants to go and look. Following it step after step leads to the source. Returns false once
+// there is nothing louder nearby, which is where the sound came from.
+func (nm *NoiseMap) Toward(m *Map, x, y int) (entity.Point, bool) {
+	best, level := entity.Point{}, nm.At(x, y)
+	found := false
+	for _, d := range [4]entity.Point{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}} {
+		nx, ny := x+d.X, y+d.Y
+		if !m.IsWalkable(nx, ny) {
+			continue
+		}
+		if l := nm.At(nx, ny); l > level {
+			best, level, found = entity.Point{X: nx, Y: ny}, l, true
+		}
+	}
+	return best, found
+}
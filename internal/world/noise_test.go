@@ -0,0 +1,94 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+func TestNoiseMap_Emit(t *testing.T) {
+	m := newTestMap(fireRooms)
+	door := make([]bool, m.Width*m.Height)
+	door[m.GetIndex(5, 2)] = true
+
+	tests := []struct {
+		name    string
+		blocked []bool
+		x, y    int
+		want    int
+	}{
+		{"source", nil, 1, 2, 20},
+		{"down the floor", nil, 4, 2, 17},
+		{"through the open door", nil, 6, 2, 15},
+		{"through the closed door", door, 6, 2, 20 - 3 - doorNoiseCost - 1},
+		{"through the wall", nil, 5, 1, 20 - 4 - wallNoiseCost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nm := NewNoiseMap(m.Width, m.Height)
+			nm.Emit(m, 1, 2, 20, tt.blocked)
+			if got := nm.At(tt.x, tt.y); got != tt.want {
+				t.Errorf("At(%d,%d) = %d, want %d", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoiseMap_VacuumCarriesNothing(t *testing.T) {
+	m := newTestMap(`
+#####
+#...#
+#####`)
+	m.Tiles[m.GetIndex(2, 1)] = Tile{Type: TileTypeEmpty}
+
+	nm := NewNoiseMap(m.Width, m.Height)
+	nm.Emit(m, 1, 1, 5, nil)
+
+	// Going round through the walls costs more than the sound has
+	if got := nm.At(3, 1); got != 0 {
+		t.Errorf("Expected no sound across the vacuum, got %d", got)
+	}
+}
+
+func TestNoiseMap_LoudestWinsAndClears(t *testing.T) {
+	m := newTestMap(fireRooms)
+	nm := NewNoiseMap(m.Width, m.Height)
+	nm.Emit(m, 1, 2, 6, nil)
+	nm.Emit(m, 3, 2, 10, nil)
+
+	if got := nm.At(2, 2); got != 9 {
+		t.Errorf("Expected the louder sound to win, not add up, got %d", got)
+	}
+	if p, level, ok := nm.Loudest(); !ok || p != (entity.Point{X: 3, Y: 2}) || level != 10 {
+		t.Errorf("Loudest() = %v, %d, %v", p, level, ok)
+	}
+
+	nm.Clear()
+	for i, level := range nm.Level {
+		if level != 0 {
+			t.Fatalf("Expected silence after Clear, tile %d is at %d", i, level)
+		}
+	}
+	if _, _, ok := nm.Loudest(); ok {
+		t.Error("Expected nothing to be loudest after Clear")
+	}
+}
+
+func TestNoiseMap_TowardLeadsToSource(t *testing.T) {
+	m := newTestFacility()
+	nm := NewNoiseMap(m.Width, m.Height)
+	source := entity.Point{X: 15, Y: 2}
+	nm.Emit(m, source.X, source.Y, 40, nil)
+
+	x, y := 2, 2
+	for steps := 0; steps < 40; steps++ {
+		next, ok := nm.Toward(m, x, y)
+		if !ok {
+			break
+		}
+		x, y = next.X, next.Y
+	}
+	if (entity.Point{X: x, Y: y}) != source {
+		t.Errorf("Expected following the noise to end at %v, got (%d,%d)", source, x, y)
+	}
+}
@@ -0,0 +1,138 @@
+package world
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+/*
+	The path cache remembers routes between two tiles so the autopilot doesn't search for the same
+	one twice, and shares them between everyone walking the floor. Every entry is keyed by the map's
+	Revision as well, so the moment a tile or a door changes the old routes stop matching; the
+	cache drops them all then, rather than letting them age out.
+
+	The cache doesn't look at fire or water, they change every tick. Callers that care about them
+	check the routes they get back.
+*/
+
+// DefaultPathCacheSize is how many routes a cache holds before it starts forgetting the oldest.
+const DefaultPathCacheSize = 256
+
+// PathKind is what sort of route a cache entry holds, so routes of different sorts between the
+// same two tiles don't get mixed up.
+type PathKind uint8
+
+const (
+	PathTiles         PathKind = iota // Every tile of the way, in straight steps
+	PathTilesDiagonal                 // Every tile of the way, diagonal steps allowed
+	PathWaypoints                     // The portals of a Hierarchy route, to be refined leg by leg
+)
+
+// TilePath is the kind of a tile by tile route for a walker that does or doesn't move diagonally.
+func TilePath(diagonal bool) PathKind {
+	if diagonal {
+		return PathTilesDiagonal
+	}
+	return PathTiles
+}
+
+type pathKey struct {
+	start, goal entity.Point
+	revision    uint64
+	kind        PathKind
+}
+
+// PathCacheStats counts how well a cache is doing, for tuning its size.
+type PathCacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Evictions     uint64 // Routes forgotten to make room
+	Invalidations uint64 // Routes dropped because the map changed under them
+	Size          int
+}
+
+// HitRate is the share of lookups the cache answered, 0 before the first.
+func (s PathCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// PathCache holds routes for the current revision of one map. It isn't safe for concurrent use.
+type PathCache struct {
+	entries  map[pathKey][]entity.Point
+	order    []pathKey // Ring of keys in the order they went in, oldest at next
+	next     int
+	revision uint64
+	stats    PathCacheStats
+}
+
+// NewPathCache makes a cache holding up to capacity routes.
+func NewPathCache(capacity int) *PathCache {
+	return &PathCache{
+		entries: make(map[pathKey][]entity.Point, capacity),
+		order:   make([]pathKey, 0, capacity),
+	}
+}
+
+// Get looks up the route of the given kind from start to goal on m as it is now. The route is
+// shared, so callers must copy it before changing it. A nil cache never has anything.
+func (c *PathCache) Get(m *Map, start, goal entity.Point, kind PathKind) ([]entity.Point, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.sync(m)
+	path, ok := c.entries[pathKey{start: start, goal: goal, revision: m.Revision, kind: kind}]
+	if ok {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	return path, ok
+}
+
+// Put remembers the route of the given kind from start to goal on m as it is now. A nil path
+// remembers that there is no way.
+func (c *PathCache) Put(m *Map, start, goal entity.Point, kind PathKind, path []entity.Point) {
+	if c == nil {
+		return
+	}
+	c.sync(m)
+	if cap(c.order) == 0 {
+		return
+	}
+	key := pathKey{start: start, goal: goal, revision: m.Revision, kind: kind}
+	if _, ok := c.entries[key]; ok {
+		c.entries[key] = path
+		return
+	}
+
+	if len(c.order) < cap(c.order) {
+		c.order = append(c.order, key)
+	} else {
+		delete(c.entries, c.order[c.next])
+		c.stats.Evictions++
+		c.order[c.next] = key
+		c.next = (c.next + 1) % len(c.order)
+	}
+	c.entries[key] = path
+}
+
+// sync drops every route once the map has moved on to a new revision.
+func (c *PathCache) sync(m *Map) {
+	if m.Revision == c.revision {
+		return
+	}
+	c.stats.Invalidations += uint64(len(c.entries))
+	clear(c.entries)
+	c.order = c.order[:0]
+	c.next = 0
+	c.revision = m.Revision
+}
+
+// Stats reports the cache's hits and misses so far.
+func (c *PathCache) Stats() PathCacheStats {
+	s := c.stats
+	s.Size = len(c.entries)
+	return s
+}
@@ -0,0 +1,99 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+func TestPathCache_HitsAndMisses(t *testing.T) {
+	m := setupTestMap(10, 10, nil)
+	c := NewPathCache(4)
+	start, goal := entity.Point{X: 1, Y: 1}, entity.Point{X: 1, Y: 3}
+	path := []entity.Point{start, {X: 1, Y: 2}, goal}
+
+	if _, ok := c.Get(m, start, goal, PathTiles); ok {
+		t.Fatal("Expected an empty cache to miss")
+	}
+	c.Put(m, start, goal, PathTiles, path)
+
+	if got, ok := c.Get(m, start, goal, PathTiles); !ok || len(got) != 3 {
+		t.Errorf("Expected the route back, got %v", got)
+	}
+	if _, ok := c.Get(m, start, goal, PathTilesDiagonal); ok {
+		t.Error("Expected a diagonal walker not to get the straight route")
+	}
+	if _, ok := c.Get(m, start, goal, PathWaypoints); ok {
+		t.Error("Expected a waypoint route not to be answered with a tile path")
+	}
+	if _, ok := c.Get(m, goal, start, PathTiles); ok {
+		t.Error("Expected the way back to be a different route")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 || stats.Size != 1 {
+		t.Errorf("Expected 1 hit, 4 misses and 1 route, got %+v", stats)
+	}
+	if stats.HitRate() != 0.2 {
+		t.Errorf("Expected a hit rate of 0.2, got %v", stats.HitRate())
+	}
+}
+
+func TestPathCache_DropsRoutesWhenTheMapChanges(t *testing.T) {
+	start, goal := entity.Point{X: 1, Y: 1}, entity.Point{X: 8, Y: 8}
+
+	cases := []struct {
+		name   string
+		change func(m *Map)
+	}{
+		{"Tile", func(m *Map) { m.SetTile(5, 5, Tile{Type: TileTypeWall}) }},
+		{"Door", func(m *Map) { m.BumpRevision() }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := setupTestMap(10, 10, nil)
+			c := NewPathCache(4)
+			c.Put(m, start, goal, PathTiles, []entity.Point{start, goal})
+
+			tc.change(m)
+			if _, ok := c.Get(m, start, goal, PathTiles); ok {
+				t.Fatal("Expected the route to be dropped once the map changed")
+			}
+			if stats := c.Stats(); stats.Invalidations != 1 || stats.Size != 0 {
+				t.Errorf("Expected 1 route invalidated and none left, got %+v", stats)
+			}
+		})
+	}
+}
+
+func TestPathCache_EvictsTheOldest(t *testing.T) {
+	m := setupTestMap(10, 10, nil)
+	c := NewPathCache(2)
+	goal := entity.Point{X: 9, Y: 9}
+
+	for x := 0; x < 3; x++ {
+		c.Put(m, entity.Point{X: x}, goal, PathTiles, nil)
+	}
+
+	if _, ok := c.Get(m, entity.Point{X: 0}, goal, PathTiles); ok {
+		t.Error("Expected the oldest route to be forgotten")
+	}
+	if _, ok := c.Get(m, entity.Point{X: 2}, goal, PathTiles); !ok {
+		t.Error("Expected the newest route to be kept")
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Size != 2 {
+		t.Errorf("Expected 1 eviction and 2 routes, got %+v", stats)
+	}
+}
+
+func TestPathCache_NilNeverHits(t *testing.T) {
+	var c *PathCache
+	m := setupTestMap(3, 3, nil)
+	p := entity.Point{X: 1, Y: 1}
+
+	c.Put(m, p, p, PathTiles, []entity.Point{p})
+	if _, ok := c.Get(m, p, p, PathTiles); ok {
+		t.Error("Expected a nil cache to miss")
+	}
+}
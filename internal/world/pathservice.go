@@ -0,0 +1,198 @@
+package world
+
+import (
+	"sync"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+/*
+	The path service takes searches off the simulation tick. Systems submit requests during a tick,
+	a pool of workers (each with its own Pathfinder, they aren't safe to share) works through them
+	while the rest of the tick and the frame go on, and Collect hands the results back at the next
+	tick boundary in the order they were asked for.
+
+	Workers never look at the live map, it keeps changing under them. Every request carries a
+	PathSnapshot of what could be walked and what it cost when it was asked, so the answer is the
+	same whichever worker picks it up, and the same as running it synchronously.
+*/
+
+// PathSnapshot is a frozen copy of which tiles can be walked and what they cost, safe for
+// workers to read while the simulation moves on. One snapshot can be shared by many requests.
+type PathSnapshot struct {
+	Revision uint64 // The map's Revision when the snapshot was taken
+	grid     *Map   // Dimensions only, the Pathfinder works out indices from it
+	walkable []bool
+	cost     []int // Extra cost per tile as in PathOptions.Cost, nil for none
+}
+
+// NewPathSnapshot copies the walkable tiles of m and, if cost isn't nil, what each costs to enter.
+// Solid entities aren't part of the map, Block them afterwards.
+func NewPathSnapshot(m *Map, cost func(x, y int) int) *PathSnapshot {
+	s := &PathSnapshot{
+		Revision: m.Revision,
+		grid:     &Map{Width: m.Width, Height: m.Height},
+		walkable: make([]bool, len(m.Tiles)),
+	}
+	for i, tile := range m.Tiles {
+		s.walkable[i] = tile.Walkable
+	}
+
+	if cost != nil {
+		s.cost = make([]int, len(m.Tiles))
+		for i := range s.cost {
+			s.cost[i] = cost(i%m.Width, i/m.Width)
+		}
+	}
+	return s
+}
+
+// Block marks a tile as not walkable, for a Solid entity standing on it.
+func (s *PathSnapshot) Block(x, y int) {
+	if x >= 0 && x < s.grid.Width && y >= 0 && y < s.grid.Height {
+		s.walkable[s.grid.GetIndex(x, y)] = false
+	}
+}
+
+// IsWalkable is Map.IsWalkable as it was when the snapshot was taken.
+func (s *PathSnapshot) IsWalkable(x, y int) bool {
+	if x < 0 || x >= s.grid.Width || y < 0 || y >= s.grid.Height {
+		return false
+	}
+	return s.walkable[s.grid.GetIndex(x, y)]
+}
+
+func (s *PathSnapshot) costAt(x, y int) int {
+	return s.cost[s.grid.GetIndex(x, y)]
+}
+
+// snapshotReuse is how many times a SnapshotCache hands out the same snapshot. Fire and water
+// don't move the map's Revision, so the hazard costs are taken again every so often regardless.
+const snapshotReuse = 32
+
+// SnapshotCache keeps the latest PathSnapshot of one map, so every request shares it until the
+// map's Revision moves on instead of copying the whole map each time.
+type SnapshotCache struct {
+	snapshot *PathSnapshot
+	served   int // Times snapshot has been handed out
+}
+
+// Get returns the cached snapshot of m, or one freshly made by take once the map has changed.
+// A nil cache takes a new snapshot every time.
+func (c *SnapshotCache) Get(m *Map, take func() *PathSnapshot) *PathSnapshot {
+	if c == nil {
+		return take()
+	}
+	if c.snapshot == nil || c.snapshot.Revision != m.Revision || c.served >= snapshotReuse {
+		c.snapshot = take()
+		c.served = 0
+	}
+	c.served++
+	return c.snapshot
+}
+
+// PathRequest asks for a path from Start to Goal over Snapshot.
+type PathRequest struct {
+	Owner    int // Who the path is for, usually an entity ID; the service only hands it back
+	Start    entity.Point
+	Goal     entity.Point
+	Diagonal bool
+	Snapshot *PathSnapshot
+}
+
+// PathResult is the answer to a PathRequest. Path is nil if the goal couldn't be reached.
+type PathResult struct {
+	Owner    int
+	Start    entity.Point
+	Goal     entity.Point
+	Revision uint64 // The snapshot's Revision, whether the path still holds on the map as it is now
+	Path     []entity.Point
+}
+
+type pathJob struct {
+	req PathRequest
+	res *PathResult
+}
+
+// PathService solves PathRequests on a pool of worker goroutines, or inline when it has none.
+// Submit and Collect must be called from one goroutine, the simulation's.
+type PathService struct {
+	jobs    chan pathJob
+	pending []*PathResult
+	wg      sync.WaitGroup
+	local   *Pathfinder // Solves requests as they come in synchronous mode
+}
+
+// NewPathService starts workers goroutines for maps up to width x height. With no workers
+// every request is solved inside Submit, which gives the same results one at a time.
+func NewPathService(width, height, workers int) *PathService {
+	s := &PathService{}
+	if workers <= 0 {
+		s.local = NewPathfinder(width, height)
+		return s
+	}
+
+	s.jobs = make(chan pathJob, 64)
+	for i := 0; i < workers; i++ {
+		go s.work(s.jobs, NewPathfinder(width, height))
+	}
+	return s
+}
+
+func (s *PathService) work(jobs <-chan pathJob, pf *Pathfinder) {
+	for job := range jobs {
+		solve(pf, job)
+		s.wg.Done()
+	}
+}
+
+func solve(pf *Pathfinder, job pathJob) {
+	snap := job.req.Snapshot
+	opts := PathOptions{Diagonal: job.req.Diagonal}
+	if snap.cost != nil {
+		opts.Cost = snap.costAt
+	}
+	job.res.Path = pf.FindPathWithOptions(snap.grid, job.req.Start, job.req.Goal, snap.IsWalkable, opts)
+}
+
+// Submit queues a request. Its result comes back from the next Collect.
+func (s *PathService) Submit(req PathRequest) {
+	res := &PathResult{Owner: req.Owner, Start: req.Start, Goal: req.Goal, Revision: req.Snapshot.Revision}
+	s.pending = append(s.pending, res)
+
+	if s.jobs == nil {
+		solve(s.local, pathJob{req: req, res: res})
+		return
+	}
+	s.wg.Add(1)
+	s.jobs <- pathJob{req: req, res: res}
+}
+
+// Pending is how many requests have been submitted since the last Collect.
+func (s *PathService) Pending() int {
+	return len(s.pending)
+}
+
+// Collect waits for every submitted request and returns the results in the order they were submitted.
+func (s *PathService) Collect() []PathResult {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	s.wg.Wait()
+
+	results := make([]PathResult, len(s.pending))
+	for i, res := range s.pending {
+		results[i] = *res
+	}
+	clear(s.pending)
+	s.pending = s.pending[:0]
+	return results
+}
+
+// Close stops the workers once they've finished what was submitted. The service can't be used afterwards.
+func (s *PathService) Close() {
+	if s.jobs != nil {
+		close(s.jobs)
+		s.jobs = nil
+	}
+}
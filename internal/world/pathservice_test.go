@@ -0,0 +1,116 @@
+package world
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+// submitRandomRequests asks service for paths between random tiles of m, half with costs and diagonals.
+func submitRandomRequests(service *PathService, m *Map, seed uint64, count int) {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	plain := NewPathSnapshot(m, nil)
+	costly := NewPathSnapshot(m, func(x, y int) int { return (x * y) % 3 })
+
+	for i := 0; i < count; i++ {
+		req := PathRequest{
+			Owner:    i,
+			Start:    entity.Point{X: rng.IntN(m.Width), Y: rng.IntN(m.Height)},
+			Goal:     entity.Point{X: rng.IntN(m.Width), Y: rng.IntN(m.Height)},
+			Snapshot: plain,
+		}
+		if i%2 == 1 {
+			req.Diagonal, req.Snapshot = true, costly
+		}
+		service.Submit(req)
+	}
+}
+
+func TestPathService_WorkersMatchSynchronous(t *testing.T) {
+	m := randomCaveMap(rand.New(rand.NewPCG(44, 44)), 60, 40, 0.25)
+
+	sync := NewPathService(m.Width, m.Height, 0)
+	async := NewPathService(m.Width, m.Height, 4)
+	defer async.Close()
+
+	for tick := uint64(0); tick < 5; tick++ {
+		submitRandomRequests(sync, m, tick, 40)
+		submitRandomRequests(async, m, tick, 40)
+		if async.Pending() != 40 {
+			t.Fatalf("Expected 40 pending requests, got %d", async.Pending())
+		}
+
+		want, got := sync.Collect(), async.Collect()
+		if len(got) != len(want) {
+			t.Fatalf("Tick %d: expected %d results, got %d", tick, len(want), len(got))
+		}
+		for i := range want {
+			if got[i].Owner != i || !slices.Equal(got[i].Path, want[i].Path) {
+				t.Fatalf("Tick %d, request %d: workers found %v, synchronous found %v", tick, i, got[i].Path, want[i].Path)
+			}
+		}
+	}
+
+	if async.Pending() != 0 || async.Collect() != nil {
+		t.Error("Expected nothing left after collecting")
+	}
+}
+
+func TestPathService_UsesTheSnapshot(t *testing.T) {
+	m := setupTestMap(10, 3, nil)
+	service := NewPathService(m.Width, m.Height, 2)
+	defer service.Close()
+
+	snap := NewPathSnapshot(m, nil)
+	snap.Block(5, 0)
+	snap.Block(5, 1)
+	start, goal := entity.Point{X: 0, Y: 0}, entity.Point{X: 9, Y: 0}
+	service.Submit(PathRequest{Start: start, Goal: goal, Snapshot: snap})
+
+	// Walling off the live map after asking doesn't change the answer
+	m.SetTile(5, 2, Tile{Type: TileTypeWall})
+
+	results := service.Collect()
+	if len(results) != 1 || results[0].Path == nil {
+		t.Fatalf("Expected a path round the blocked tiles, got %+v", results)
+	}
+	if !slices.Contains(results[0].Path, entity.Point{X: 5, Y: 2}) {
+		t.Errorf("Expected the path through the only gap at (5, 2), got %v", results[0].Path)
+	}
+	if results[0].Revision == m.Revision {
+		t.Error("Expected the result to carry the revision the snapshot was taken at")
+	}
+}
+
+func TestSnapshotCache_SharesUntilTheMapChanges(t *testing.T) {
+	m := setupTestMap(10, 10, nil)
+	var c SnapshotCache
+	taken := 0
+	take := func() *PathSnapshot {
+		taken++
+		return NewPathSnapshot(m, nil)
+	}
+
+	first := c.Get(m, take)
+	if c.Get(m, take) != first || taken != 1 {
+		t.Fatalf("Expected the snapshot to be shared while the map stays the same, took %d", taken)
+	}
+
+	m.BumpRevision()
+	if c.Get(m, take) == first || taken != 2 {
+		t.Fatalf("Expected a new snapshot once the map changed, took %d", taken)
+	}
+
+	for i := 1; i < snapshotReuse; i++ {
+		c.Get(m, take)
+	}
+	if taken != 2 {
+		t.Fatalf("Expected one snapshot for %d requests, took %d", snapshotReuse, taken-1)
+	}
+	c.Get(m, take)
+	if taken != 3 {
+		t.Errorf("Expected the hazard costs to be taken again after %d requests, took %d", snapshotReuse, taken)
+	}
+}
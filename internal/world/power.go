@@ -0,0 +1,160 @@
+package world
+
+/*
+	Power runs through conduit tiles. Every connected group of conduits is one grid, and anything
+	plugged into it shares its generators' output. Open breakers cut their tile out of the network,
+	which can split a grid in two.
+
+	Generated facilities wire every corridor plus a cross through the centre of each room.
+	Devices inside a room draw from the room's wiring at its centre, so a room's outlets go dead
+	when that centre tile is cut off. Anywhere else a device needs a conduit on or next to its tile.
+*/
+
+// NoGrid is the grid ID of tiles and devices that aren't connected to any conduit.
+const NoGrid = -1
+
+// LayConduit wires a tile into the power network.
+func (m *Map) LayConduit(x, y int) {
+	if m.GetTile(x, y) == nil {
+		return
+	}
+	if m.Conduits == nil {
+		m.Conduits = make([]bool, m.Width*m.Height)
+	}
+	m.Conduits[m.GetIndex(x, y)] = true
+}
+
+// HasConduit reports whether a tile is part of the power network.
+func (m *Map) HasConduit(x, y int) bool {
+	if m.Conduits == nil || x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return false
+	}
+	return m.Conduits[m.GetIndex(x, y)]
+}
+
+// layRoomConduits wires a cross through the centre of a room.
+func layRoomConduits(m *Map, room Rect) {
+	cx, cy := room.Center()
+	for x := room.X1; x <= room.X2; x++ {
+		m.LayConduit(x, cy)
+	}
+	for y := room.Y1; y <= room.Y2; y++ {
+		m.LayConduit(cx, y)
+	}
+}
+
+// GridStats is the balance of one grid after the last Label.
+type GridStats struct {
+	Supply float32 // Combined output of the running generators
+	Demand float32 // Combined demand of every consumer
+}
+
+// Ratio is the share of its demand every consumer on the grid gets, capped at 1.
+func (s GridStats) Ratio() float32 {
+	if s.Demand <= 0 {
+		if s.Supply > 0 {
+			return 1
+		}
+		return 0
+	}
+	return min(s.Supply/s.Demand, 1)
+}
+
+// BrownedOut reports whether the grid has power, but not enough of it.
+func (s GridStats) BrownedOut() bool {
+	return s.Supply > 0 && s.Supply < s.Demand
+}
+
+// PowerGrid labels the conduits of one map with grid IDs. Its buffers are reused between calls,
+// so it can be relabelled every tick without allocating.
+type PowerGrid struct {
+	Grids  []GridStats // Indexed by grid ID, filled in by the power system
+	labels []int
+	queue  []int
+}
+
+func NewPowerGrid(width, height int) *PowerGrid {
+	return &PowerGrid{
+		labels: make([]int, width*height),
+		queue:  make([]int, 0, width*height),
+	}
+}
+
+// Label splits the conduits into grids, treating tiles where cut returns true as broken.
+// Grids are numbered in scan order starting from 0, and their stats are reset. Returns the grid count.
+func (g *PowerGrid) Label(m *Map, cut func(x, y int) bool) int {
+	for i := range g.labels {
+		g.labels[i] = NoGrid
+	}
+	g.Grids = g.Grids[:0]
+	if m.Conduits == nil {
+		return 0
+	}
+
+	dx := [4]int{0, 0, 1, -1}
+	dy := [4]int{-1, 1, 0, 0}
+	conducts := func(x, y int) bool {
+		return m.HasConduit(x, y) && !cut(x, y)
+	}
+
+	for start := range m.Conduits {
+		if g.labels[start] != NoGrid || !conducts(start%m.Width, start/m.Width) {
+			continue
+		}
+
+		id := len(g.Grids)
+		g.Grids = append(g.Grids, GridStats{})
+		g.labels[start] = id
+		g.queue = append(g.queue[:0], start)
+
+		for head := 0; head < len(g.queue); head++ {
+			x, y := g.queue[head]%m.Width, g.queue[head]/m.Width
+			for i := 0; i < 4; i++ {
+				nx, ny := x+dx[i], y+dy[i]
+				if !conducts(nx, ny) {
+					continue
+				}
+				idx := m.GetIndex(nx, ny)
+				if g.labels[idx] != NoGrid {
+					continue
+				}
+				g.labels[idx] = id
+				g.queue = append(g.queue, idx)
+			}
+		}
+	}
+
+	return len(g.Grids)
+}
+
+// GridAt returns the grid a device on (x, y) draws from, or NoGrid if it isn't connected.
+func (g *PowerGrid) GridAt(m *Map, x, y int) int {
+	if x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return NoGrid
+	}
+
+	if id := g.labels[m.GetIndex(x, y)]; id != NoGrid {
+		return id
+	}
+
+	// Room outlets are wired back to the room's centre
+	if m.Graph != nil {
+		if room := m.Graph.RoomAt(x, y); room >= 0 {
+			cx, cy := m.Rooms[room].Center()
+			return g.labels[m.GetIndex(cx, cy)]
+		}
+	}
+
+	// Anything else has to sit right next to a conduit
+	for ny := y - 1; ny <= y+1; ny++ {
+		for nx := x - 1; nx <= x+1; nx++ {
+			if nx < 0 || nx >= m.Width || ny < 0 || ny >= m.Height {
+				continue
+			}
+			if id := g.labels[m.GetIndex(nx, ny)]; id != NoGrid {
+				return id
+			}
+		}
+	}
+	return NoGrid
+}
@@ -0,0 +1,108 @@
+package world
+
+import "testing"
+
+// newWiredTestMap wires every walkable tile of a test layout.
+func newWiredTestMap(layout string) *Map {
+	m := newTestMap(layout)
+	for i, tile := range m.Tiles {
+		if tile.Walkable {
+			m.LayConduit(i%m.Width, i/m.Width)
+		}
+	}
+	return m
+}
+
+func TestPowerGrid_Label(t *testing.T) {
+	m := newWiredTestMap(`
+#########
+#...#...#
+#.......#
+#...#...#
+#########`)
+	g := NewPowerGrid(m.Width, m.Height)
+	noCut := func(x, y int) bool { return false }
+
+	if got := g.Label(m, noCut); got != 1 {
+		t.Fatalf("Expected one grid, got %d", got)
+	}
+
+	// Cutting the gap in the middle wall splits the grid in two
+	cut := func(x, y int) bool { return x == 4 && y == 2 }
+	if got := g.Label(m, cut); got != 2 {
+		t.Fatalf("Expected the cut to split the grid, got %d grids", got)
+	}
+	left, right := g.GridAt(m, 1, 1), g.GridAt(m, 7, 3)
+	if left == NoGrid || right == NoGrid || left == right {
+		t.Errorf("Expected the two sides on different grids, got %d and %d", left, right)
+	}
+	if got := g.GridAt(m, 0, 0); got != left {
+		t.Errorf("Expected a wall next to the left side to draw from it, got %d", got)
+	}
+}
+
+func TestPowerGrid_GridAt_RoomOutlets(t *testing.T) {
+	m := newTestFacility()
+	layRoomConduits(m, m.Rooms[1])
+	m.Graph = BuildRoomGraph(m, 2, 2)
+	g := NewPowerGrid(m.Width, m.Height)
+
+	// (7,1) is off the cross but still draws from the room's centre
+	g.Label(m, func(x, y int) bool { return false })
+	if got := g.GridAt(m, 7, 1); got == NoGrid {
+		t.Errorf("Expected a room tile to be wired through the room centre")
+	}
+	if got := g.GridAt(m, 2, 2); got != NoGrid {
+		t.Errorf("Expected an unwired room to have no grid, got %d", got)
+	}
+
+	cx, cy := m.Rooms[1].Center()
+	g.Label(m, func(x, y int) bool { return x == cx && y == cy })
+	if got := g.GridAt(m, 7, 1); got != NoGrid {
+		t.Errorf("Expected the room's outlets to die with its centre, got grid %d", got)
+	}
+}
+
+func TestGridStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		stats     GridStats
+		wantRatio float32
+		wantBrown bool
+	}{
+		{"dead", GridStats{Supply: 0, Demand: 5}, 0, false},
+		{"idle", GridStats{Supply: 10, Demand: 0}, 1, false},
+		{"healthy", GridStats{Supply: 10, Demand: 8}, 1, false},
+		{"overloaded", GridStats{Supply: 10, Demand: 20}, 0.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.Ratio(); got != tt.wantRatio {
+				t.Errorf("Ratio() = %v, want %v", got, tt.wantRatio)
+			}
+			if got := tt.stats.BrownedOut(); got != tt.wantBrown {
+				t.Errorf("BrownedOut() = %v, want %v", got, tt.wantBrown)
+			}
+		})
+	}
+}
+
+func TestFacilityGenerator_WiresEveryRoom(t *testing.T) {
+	for seed := uint64(1); seed <= 50; seed++ {
+		m, px, py := NewFacilityGenerator(seed).Generate(80, 40)
+		g := NewPowerGrid(m.Width, m.Height)
+		g.Label(m, func(x, y int) bool { return false })
+
+		spawnGrid := g.GridAt(m, px, py)
+		if spawnGrid == NoGrid {
+			t.Fatalf("seed %d: the spawn room isn't wired", seed)
+		}
+		for i, room := range m.Rooms {
+			cx, cy := room.Center()
+			if got := g.GridAt(m, cx, cy); got != spawnGrid {
+				t.Errorf("seed %d: room %d is on grid %d, want the spawn's grid %d", seed, i, got, spawnGrid)
+			}
+		}
+	}
+}
@@ -26,6 +26,11 @@ func (r Rect) Height() int {
 	return r.Y2 - r.Y1
 }
 
+// Contains reports whether the tile lies inside the rectangle, edges included.
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X1 && x <= r.X2 && y >= r.Y1 && y <= r.Y2
+}
+
 func (r Rect) Intersects(other Rect) bool {
 	/*
 		A's left edge is to the left of B's right edge.
@@ -118,3 +118,27 @@ func TestRect_Center(t *testing.T) {
 		})
 	}
 }
+
+func TestRect_Contains(t *testing.T) {
+	r := Rect{X1: 2, Y1: 3, X2: 6, Y2: 8}
+
+	tests := []struct {
+		name   string
+		x, y   int
+		inside bool
+	}{
+		{"centre", 4, 5, true},
+		{"top-left corner", 2, 3, true},
+		{"bottom-right corner", 6, 8, true},
+		{"left of the rect", 1, 5, false},
+		{"below the rect", 4, 9, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Contains(tt.x, tt.y); got != tt.inside {
+				t.Errorf("Contains(%d, %d) = %v, want %v", tt.x, tt.y, got, tt.inside)
+			}
+		})
+	}
+}
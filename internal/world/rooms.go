@@ -0,0 +1,437 @@
+package world
+
+import (
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+	"github.com/vikash-paf/derelict-facility/internal/math"
+)
+
+// RoomType is the semantic purpose the generator assigned to a room.
+type RoomType uint8
+
+const (
+	RoomTypeGeneric RoomType = iota
+	RoomTypeSpawn
+	RoomTypeReactor
+	RoomTypeStorage
+	RoomTypeSecurity
+	RoomTypeAirlock
+)
+
+func (t RoomType) Title() string {
+	switch t {
+	case RoomTypeGeneric:
+		return "Generic"
+	case RoomTypeSpawn:
+		return "Spawn"
+	case RoomTypeReactor:
+		return "Reactor"
+	case RoomTypeStorage:
+		return "Storage"
+	case RoomTypeSecurity:
+		return "Security"
+	case RoomTypeAirlock:
+		return "Airlock"
+	default:
+		return "Unknown"
+	}
+}
+
+// RoomEdge connects two rooms, either directly (touching floors) or through a corridor.
+type RoomEdge struct {
+	A, B  int            // Indices into Map.Rooms
+	Doors []entity.Point // Doors placed along the corridor between A and B (may be empty)
+}
+
+// Other returns the room on the opposite end of the edge.
+func (e RoomEdge) Other(room int) int {
+	if e.A == room {
+		return e.B
+	}
+	return e.A
+}
+
+// RoomGraph is the adjacency graph of a map's rooms. Rooms are nodes, corridors and doors are edges.
+type RoomGraph struct {
+	Types []RoomType // Parallel to Map.Rooms
+	Edges []RoomEdge
+
+	adjacency [][]int // Room index -> indices into Edges
+	roomAt    []int   // Tile index -> room index, -1 for corridors and walls
+	width     int
+}
+
+// BuildRoomGraph links the rooms of a map through the corridors between them and assigns each room a type.
+// The room containing the spawn point becomes the spawn room.
+func BuildRoomGraph(m *Map, spawnX, spawnY int) *RoomGraph {
+	g := &RoomGraph{
+		Types:     make([]RoomType, len(m.Rooms)),
+		adjacency: make([][]int, len(m.Rooms)),
+		roomAt:    make([]int, m.Width*m.Height),
+		width:     m.Width,
+	}
+
+	for i := range g.roomAt {
+		g.roomAt[i] = -1
+	}
+	for i, room := range m.Rooms {
+		for y := max(0, room.Y1); y <= min(m.Height-1, room.Y2); y++ {
+			for x := max(0, room.X1); x <= min(m.Width-1, room.X2); x++ {
+				g.roomAt[m.GetIndex(x, y)] = i
+			}
+		}
+	}
+
+	doors := make(map[entity.Point]bool, len(m.Doors))
+	for _, d := range m.Doors {
+		doors[d] = true
+	}
+
+	edgeOf := make(map[[2]int]int) // Normalised (low, high) room pair -> index into Edges
+	link := func(a, b int) int {
+		if a > b {
+			a, b = b, a
+		}
+		if idx, ok := edgeOf[[2]int{a, b}]; ok {
+			return idx
+		}
+		idx := len(g.Edges)
+		g.Edges = append(g.Edges, RoomEdge{A: a, B: b})
+		g.adjacency[a] = append(g.adjacency[a], idx)
+		g.adjacency[b] = append(g.adjacency[b], idx)
+		edgeOf[[2]int{a, b}] = idx
+		return idx
+	}
+
+	dx := [4]int{0, 0, 1, -1}
+	dy := [4]int{-1, 1, 0, 0}
+
+	// 1. Rooms whose floors touch are linked directly
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			a := g.roomAt[m.GetIndex(x, y)]
+			if a < 0 || !m.IsWalkable(x, y) {
+				continue
+			}
+			// Only look east and south, so every pair of tiles is checked once
+			for _, n := range [2]entity.Point{{X: x + 1, Y: y}, {X: x, Y: y + 1}} {
+				if !m.IsWalkable(n.X, n.Y) {
+					continue
+				}
+				if b := g.roomAt[m.GetIndex(n.X, n.Y)]; b >= 0 && b != a {
+					link(a, b)
+				}
+			}
+		}
+	}
+
+	// 2. Every connected run of corridor tiles links all the rooms it touches
+	visited := make([]bool, m.Width*m.Height)
+	var queue []entity.Point
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			idx := m.GetIndex(x, y)
+			if visited[idx] || g.roomAt[idx] >= 0 || !m.IsWalkable(x, y) {
+				continue
+			}
+
+			var touching []int
+			var corridorDoors []entity.Point
+
+			queue = append(queue[:0], entity.Point{X: x, Y: y})
+			visited[idx] = true
+			for head := 0; head < len(queue); head++ {
+				p := queue[head]
+				if doors[p] {
+					corridorDoors = append(corridorDoors, p)
+				}
+				for i := 0; i < 4; i++ {
+					nx, ny := p.X+dx[i], p.Y+dy[i]
+					if !m.IsWalkable(nx, ny) {
+						continue
+					}
+					nIdx := m.GetIndex(nx, ny)
+					if room := g.roomAt[nIdx]; room >= 0 {
+						touching = appendUnique(touching, room)
+						continue
+					}
+					if !visited[nIdx] {
+						visited[nIdx] = true
+						queue = append(queue, entity.Point{X: nx, Y: ny})
+					}
+				}
+			}
+
+			for i := 0; i < len(touching); i++ {
+				for j := i + 1; j < len(touching); j++ {
+					link(touching[i], touching[j])
+				}
+			}
+
+			// A door only sits between the rooms on either side of it, not every room the corridor reaches
+			for _, door := range corridorDoors {
+				sides := g.doorSides(m, door)
+				for i := 0; i < len(sides); i++ {
+					for j := i + 1; j < len(sides); j++ {
+						for _, a := range sides[i] {
+							for _, b := range sides[j] {
+								if a == b {
+									continue
+								}
+								edge := &g.Edges[link(a, b)]
+								edge.Doors = appendUniquePoint(edge.Doors, door)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	spawn := g.RoomAt(spawnX, spawnY)
+	if spawn < 0 && len(m.Rooms) > 0 {
+		spawn = 0
+	}
+	g.assignTypes(m, spawn)
+
+	return g
+}
+
+// doorSides groups the rooms reachable from each side of a corridor door without stepping through it.
+func (g *RoomGraph) doorSides(m *Map, door entity.Point) [][]int {
+	dx := [4]int{0, 0, 1, -1}
+	dy := [4]int{-1, 1, 0, 0}
+
+	var sides [][]int
+	seen := map[entity.Point]bool{door: true}
+	for i := 0; i < 4; i++ {
+		start := entity.Point{X: door.X + dx[i], Y: door.Y + dy[i]}
+		if !m.IsWalkable(start.X, start.Y) || seen[start] {
+			continue
+		}
+		if room := g.roomAt[m.GetIndex(start.X, start.Y)]; room >= 0 {
+			sides = append(sides, []int{room})
+			continue
+		}
+
+		var rooms []int
+		seen[start] = true
+		queue := []entity.Point{start}
+		for head := 0; head < len(queue); head++ {
+			p := queue[head]
+			for j := 0; j < 4; j++ {
+				n := entity.Point{X: p.X + dx[j], Y: p.Y + dy[j]}
+				if !m.IsWalkable(n.X, n.Y) || seen[n] {
+					continue
+				}
+				if room := g.roomAt[m.GetIndex(n.X, n.Y)]; room >= 0 {
+					rooms = appendUnique(rooms, room)
+					continue
+				}
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+		sides = append(sides, rooms)
+	}
+
+	return sides
+}
+
+// assignTypes labels rooms based on their size and position in the graph relative to the spawn:
+//   - the spawn room is where the player starts
+//   - the reactor is the biggest room, preferring ones further from the spawn
+//   - security guards the approach, it's the room you pass through right before the reactor
+//   - the airlock is the dead end furthest away from the spawn
+//   - the remaining dead ends are storage
+func (g *RoomGraph) assignTypes(m *Map, spawn int) {
+	if spawn < 0 {
+		return
+	}
+	g.Types[spawn] = RoomTypeSpawn
+
+	hops, parent := g.bfs(spawn)
+
+	area := func(r Rect) int { return (r.Width() + 1) * (r.Height() + 1) }
+
+	reactor := -1
+	for i, room := range m.Rooms {
+		if g.Types[i] != RoomTypeGeneric || hops[i] < 0 {
+			continue
+		}
+		if reactor < 0 || area(room) > area(m.Rooms[reactor]) ||
+			(area(room) == area(m.Rooms[reactor]) && hops[i] > hops[reactor]) {
+			reactor = i
+		}
+	}
+	if reactor < 0 {
+		return
+	}
+	g.Types[reactor] = RoomTypeReactor
+
+	if guard := parent[reactor]; guard >= 0 && g.Types[guard] == RoomTypeGeneric {
+		g.Types[guard] = RoomTypeSecurity
+	}
+
+	airlock := -1
+	for i := range m.Rooms {
+		if g.Types[i] != RoomTypeGeneric || len(g.adjacency[i]) > 1 || hops[i] < 0 {
+			continue
+		}
+		if airlock < 0 || hops[i] > hops[airlock] {
+			airlock = i
+		}
+	}
+	if airlock >= 0 {
+		g.Types[airlock] = RoomTypeAirlock
+	}
+
+	for i := range m.Rooms {
+		if g.Types[i] == RoomTypeGeneric && len(g.adjacency[i]) <= 1 {
+			g.Types[i] = RoomTypeStorage
+		}
+	}
+}
+
+// bfs returns the hop count from the source to every room (-1 if unreachable),
+// and the room each one was reached from (-1 for the source and unreachable rooms).
+func (g *RoomGraph) bfs(source int) (hops, parent []int) {
+	hops = make([]int, len(g.Types))
+	parent = make([]int, len(g.Types))
+	for i := range hops {
+		hops[i] = -1
+		parent[i] = -1
+	}
+
+	hops[source] = 0
+	queue := []int{source}
+	for head := 0; head < len(queue); head++ {
+		room := queue[head]
+		for _, next := range g.Neighbours(room) {
+			if hops[next] >= 0 {
+				continue
+			}
+			hops[next] = hops[room] + 1
+			parent[next] = room
+			queue = append(queue, next)
+		}
+	}
+
+	return hops, parent
+}
+
+// RoomAt returns the index of the room containing the tile, or -1 if the tile isn't in a room.
+func (g *RoomGraph) RoomAt(x, y int) int {
+	if x < 0 || x >= g.width || y < 0 {
+		return -1
+	}
+	idx := y*g.width + x
+	if idx >= len(g.roomAt) {
+		return -1
+	}
+	return g.roomAt[idx]
+}
+
+// Neighbours lists the rooms directly connected to the given room.
+func (g *RoomGraph) Neighbours(room int) []int {
+	if room < 0 || room >= len(g.adjacency) {
+		return nil
+	}
+	neighbours := make([]int, 0, len(g.adjacency[room]))
+	for _, e := range g.adjacency[room] {
+		neighbours = append(neighbours, g.Edges[e].Other(room))
+	}
+	return neighbours
+}
+
+// RoomsOfType lists every room with the given type.
+func (g *RoomGraph) RoomsOfType(t RoomType) []int {
+	var rooms []int
+	for i, rt := range g.Types {
+		if rt == t {
+			rooms = append(rooms, i)
+		}
+	}
+	return rooms
+}
+
+// NearestRoomOfType finds the closest room of the given type to a tile. Inside a room, "closest"
+// means the fewest rooms to walk through; elsewhere it falls back to the Manhattan distance to the room centres.
+func (g *RoomGraph) NearestRoomOfType(m *Map, x, y int, t RoomType) (int, bool) {
+	var hops []int
+	if from := g.RoomAt(x, y); from >= 0 {
+		hops, _ = g.bfs(from)
+	}
+
+	best, bestHops, bestDist := -1, 0, 0
+	for i, rt := range g.Types {
+		if rt != t {
+			continue
+		}
+		h := 0
+		if hops != nil {
+			if hops[i] < 0 {
+				continue // Not connected to the room we're standing in
+			}
+			h = hops[i]
+		}
+		cx, cy := m.Rooms[i].Center()
+		dist := math.Abs(cx-x) + math.Abs(cy-y)
+		if best < 0 || h < bestHops || (h == bestHops && dist < bestDist) {
+			best, bestHops, bestDist = i, h, dist
+		}
+	}
+
+	return best, best >= 0
+}
+
+// RoomsBehindDoor lists every room reachable through the door when coming from the given room,
+// without walking back through that room. The order follows the distance from the door.
+// Coming from outside any room (-1) there's nothing to walk away from, so it lists none.
+func (g *RoomGraph) RoomsBehindDoor(door entity.Point, from int) []int {
+	if from < 0 || from >= len(g.adjacency) {
+		return nil
+	}
+	seen := make([]bool, len(g.Types))
+	seen[from] = true
+
+	var queue []int
+	for _, e := range g.adjacency[from] {
+		edge := g.Edges[e]
+		for _, d := range edge.Doors {
+			if d == door && !seen[edge.Other(from)] {
+				seen[edge.Other(from)] = true
+				queue = append(queue, edge.Other(from))
+				break
+			}
+		}
+	}
+
+	for head := 0; head < len(queue); head++ {
+		for _, next := range g.Neighbours(queue[head]) {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return queue
+}
+
+func appendUnique(s []int, v int) []int {
+	for _, existing := range s {
+		if existing == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
+
+func appendUniquePoint(s []entity.Point, p entity.Point) []entity.Point {
+	for _, existing := range s {
+		if existing == p {
+			return s
+		}
+	}
+	return append(s, p)
+}
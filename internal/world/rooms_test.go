@@ -0,0 +1,175 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vikash-paf/derelict-facility/internal/entity"
+)
+
+// newTestFacility builds four rooms around a large central room:
+//
+//	room 0 (spawn) -- door (5,2) -- room 1 -- door (13,2) -- room 2
+//	                                  |
+//	                                room 3
+func newTestFacility() *Map {
+	m := newTestMap(`
+###################
+#...###.....###...#
+#.................#
+#...###.....###...#
+#######.....#######
+#######.....#######
+#########.#########
+#########.#########
+#######...#########
+#######...#########
+###################`)
+	m.Rooms = []Rect{
+		{X1: 1, Y1: 1, X2: 3, Y2: 3},
+		{X1: 7, Y1: 1, X2: 11, Y2: 5},
+		{X1: 15, Y1: 1, X2: 17, Y2: 3},
+		{X1: 7, Y1: 8, X2: 9, Y2: 9},
+	}
+	m.Doors = []entity.Point{{X: 5, Y: 2}, {X: 13, Y: 2}}
+	return m
+}
+
+func TestBuildRoomGraph_Edges(t *testing.T) {
+	m := newTestFacility()
+	g := BuildRoomGraph(m, 2, 2)
+
+	if len(g.Edges) != 3 {
+		t.Fatalf("Expected 3 edges, got %+v", g.Edges)
+	}
+
+	neighbours := g.Neighbours(1)
+	if len(neighbours) != 3 {
+		t.Errorf("Expected the central room to have 3 neighbours, got %v", neighbours)
+	}
+
+	for _, e := range g.Edges {
+		if e.A == 0 && e.B == 1 && !reflect.DeepEqual(e.Doors, []entity.Point{{X: 5, Y: 2}}) {
+			t.Errorf("Expected the 0-1 corridor to hold door (5,2), got %v", e.Doors)
+		}
+		if e.A == 1 && e.B == 3 && len(e.Doors) != 0 {
+			t.Errorf("Expected the 1-3 corridor to have no doors, got %v", e.Doors)
+		}
+	}
+}
+
+func TestBuildRoomGraph_Types(t *testing.T) {
+	m := newTestFacility()
+	g := BuildRoomGraph(m, 2, 2)
+
+	want := []RoomType{RoomTypeSpawn, RoomTypeReactor, RoomTypeAirlock, RoomTypeStorage}
+	if !reflect.DeepEqual(g.Types, want) {
+		t.Errorf("Expected types %v, got %v", want, g.Types)
+	}
+}
+
+func TestRoomGraph_Queries(t *testing.T) {
+	m := newTestFacility()
+	g := BuildRoomGraph(m, 2, 2)
+
+	if room := g.RoomAt(9, 4); room != 1 {
+		t.Errorf("Expected (9,4) to be in room 1, got %d", room)
+	}
+	if room := g.RoomAt(5, 2); room != -1 {
+		t.Errorf("Expected corridor tile (5,2) to be outside any room, got %d", room)
+	}
+
+	if room, ok := g.NearestRoomOfType(m, 2, 2, RoomTypeReactor); !ok || room != 1 {
+		t.Errorf("Expected nearest reactor to be room 1, got %d (%v)", room, ok)
+	}
+	if room, ok := g.NearestRoomOfType(m, 13, 2, RoomTypeAirlock); !ok || room != 2 {
+		t.Errorf("Expected nearest airlock from the corridor to be room 2, got %d (%v)", room, ok)
+	}
+	if _, ok := g.NearestRoomOfType(m, 2, 2, RoomTypeSecurity); ok {
+		t.Errorf("Expected no security room in this layout")
+	}
+
+	if behind := g.RoomsBehindDoor(entity.Point{X: 13, Y: 2}, 1); !reflect.DeepEqual(behind, []int{2}) {
+		t.Errorf("Expected only room 2 behind door (13,2), got %v", behind)
+	}
+	if behind := g.RoomsBehindDoor(entity.Point{X: 5, Y: 2}, 0); !reflect.DeepEqual(behind, []int{1, 2, 3}) {
+		t.Errorf("Expected rooms 1, 2 and 3 behind door (5,2), got %v", behind)
+	}
+}
+
+// A corridor joining three rooms, with a door on the branch down to room 2 only
+func TestBuildRoomGraph_JunctionDoor(t *testing.T) {
+	m := newTestMap(`
+###########
+#...###...#
+#.........#
+#...#.#...#
+#####.#####
+#####.#####
+####...####
+####...####
+###########`)
+	m.Rooms = []Rect{
+		{X1: 1, Y1: 1, X2: 3, Y2: 3},
+		{X1: 7, Y1: 1, X2: 9, Y2: 3},
+		{X1: 4, Y1: 6, X2: 6, Y2: 7},
+	}
+	door := entity.Point{X: 5, Y: 4}
+	m.Doors = []entity.Point{door}
+	g := BuildRoomGraph(m, 2, 2)
+
+	for _, e := range g.Edges {
+		onBranch := e.A == 2 || e.B == 2
+		if onBranch && !reflect.DeepEqual(e.Doors, []entity.Point{door}) {
+			t.Errorf("Expected the %d-%d route to hold door (5,4), got %v", e.A, e.B, e.Doors)
+		}
+		if !onBranch && len(e.Doors) != 0 {
+			t.Errorf("Expected the %d-%d route to have no doors, got %v", e.A, e.B, e.Doors)
+		}
+	}
+
+	if behind := g.RoomsBehindDoor(door, 0); len(behind) == 0 || behind[0] != 2 {
+		t.Errorf("Expected room 2 first behind door (5,4), got %v", behind)
+	}
+}
+
+func TestRoomGraph_OutsideRooms(t *testing.T) {
+	m := newTestFacility()
+	g := BuildRoomGraph(m, 2, 2)
+
+	// RoomAt reports -1 for corridors, which the queries must take without panicking
+	corridor := g.RoomAt(5, 2)
+	if neighbours := g.Neighbours(corridor); neighbours != nil {
+		t.Errorf("Expected no neighbours for a corridor, got %v", neighbours)
+	}
+	if behind := g.RoomsBehindDoor(entity.Point{X: 5, Y: 2}, corridor); behind != nil {
+		t.Errorf("Expected no rooms behind a door from a corridor, got %v", behind)
+	}
+}
+
+func TestFacilityGenerator_RoomGraph(t *testing.T) {
+	for seed := uint64(0); seed < 200; seed++ {
+		m, px, py := NewFacilityGenerator(seed).Generate(120, 40)
+		if m.Graph == nil {
+			t.Fatalf("seed %d: expected a room graph", seed)
+		}
+		if len(m.Graph.Types) != len(m.Rooms) {
+			t.Fatalf("seed %d: expected %d room types, got %d", seed, len(m.Rooms), len(m.Graph.Types))
+		}
+		if m.Graph.Types[m.Graph.RoomAt(px, py)] != RoomTypeSpawn {
+			t.Fatalf("seed %d: expected the player to spawn in the spawn room", seed)
+		}
+
+		// The generator chains every room, so the graph must be connected
+		hops, _ := m.Graph.bfs(m.Graph.RoomAt(px, py))
+		for room, h := range hops {
+			if h < 0 {
+				t.Fatalf("seed %d: room %d is not connected to the spawn room", seed, room)
+			}
+		}
+
+		if len(m.Rooms) > 1 && len(m.Graph.RoomsOfType(RoomTypeReactor)) != 1 {
+			t.Fatalf("seed %d: expected exactly one reactor", seed)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package world
+
+import "github.com/vikash-paf/derelict-facility/internal/math"
+
+// FOVAlgorithm selects how ComputeFOV decides which tiles the player can see.
+type FOVAlgorithm uint8
+
+const (
+	FOVRaycast    FOVAlgorithm = iota // Bresenham rays to the edge of the radius' bounding box
+	FOVShadowcast                     // Symmetric recursive shadowcasting with a circular radius
+)
+
+/*
+	Symmetric shadowcasting, after Albert Ford's write-up:
+	https://www.albertford.com/shadowcasting/
+
+	The view is split into four quadrants (north, east, south, west). Each quadrant is scanned
+	row by row moving away from the origin; a row is the set of tiles at the same depth between
+	two slopes. Walls shrink the slopes of the rows behind them, which is what casts the shadows.
+
+	A floor tile is only revealed if its centre lies between the slopes (isSymmetric), which is
+	what makes the result symmetric: if A can see B, then B can see A. Walls are revealed as soon
+	as any part of them is in view, so rooms don't end up with missing corners.
+
+	Slopes are kept as exact fractions so the symmetry doesn't depend on floating point rounding.
+*/
+
+type quadrant uint8
+
+const (
+	quadrantNorth quadrant = iota
+	quadrantEast
+	quadrantSouth
+	quadrantWest
+)
+
+// slope is the fraction num/den, with den always positive.
+type slope struct {
+	num, den int
+}
+
+// slopeAt is the slope of the left edge of the tile at (depth, col), as seen from the origin.
+func slopeAt(depth, col int) slope {
+	return slope{num: 2*col - 1, den: 2 * depth}
+}
+
+type shadowcaster struct {
+	m           *Map
+	originX     int
+	originY     int
+	radius      int
+	quadrant    quadrant
+	blocksLight func(x, y int) bool
+	reveal      func(x, y, dist int)
+}
+
+// castShadows calls reveal for every tile within the circular radius that can be seen from the origin.
+// The origin itself is not revealed. Off-map tiles are treated as walls and never revealed.
+func castShadows(m *Map, originX, originY, radius int, blocksLight func(x, y int) bool, reveal func(x, y, dist int)) {
+	sc := shadowcaster{
+		m:           m,
+		originX:     originX,
+		originY:     originY,
+		radius:      radius,
+		blocksLight: blocksLight,
+		reveal:      reveal,
+	}
+
+	for q := quadrantNorth; q <= quadrantWest; q++ {
+		sc.quadrant = q
+		sc.scanRow(1, slope{num: -1, den: 1}, slope{num: 1, den: 1})
+	}
+}
+
+// transform turns a (depth, col) pair in the current quadrant into map coordinates.
+func (sc *shadowcaster) transform(depth, col int) (x, y int) {
+	switch sc.quadrant {
+	case quadrantNorth:
+		return sc.originX + col, sc.originY - depth
+	case quadrantSouth:
+		return sc.originX + col, sc.originY + depth
+	case quadrantEast:
+		return sc.originX + depth, sc.originY + col
+	default: // quadrantWest
+		return sc.originX - depth, sc.originY + col
+	}
+}
+
+func (sc *shadowcaster) isWall(x, y int) bool {
+	if x < 0 || x >= sc.m.Width || y < 0 || y >= sc.m.Height {
+		return true
+	}
+	return sc.blocksLight(x, y)
+}
+
+func (sc *shadowcaster) scanRow(depth int, start, end slope) {
+	if depth > sc.radius {
+		return
+	}
+
+	// round_ties_up(depth * start) and round_ties_down(depth * end) in integer math
+	minCol := floorDiv(2*depth*start.num+start.den, 2*start.den)
+	maxCol := ceilDiv(2*depth*end.num-end.den, 2*end.den)
+
+	prevWasWall := false
+	first := true
+	for col := minCol; col <= maxCol; col++ {
+		x, y := sc.transform(depth, col)
+		wall := sc.isWall(x, y)
+
+		if wall || isSymmetric(depth, col, start, end) {
+			sc.revealTile(x, y, depth, col)
+		}
+
+		if !first {
+			if prevWasWall && !wall {
+				start = slopeAt(depth, col)
+			}
+			if !prevWasWall && wall {
+				sc.scanRow(depth+1, start, slopeAt(depth, col))
+			}
+		}
+
+		prevWasWall = wall
+		first = false
+	}
+
+	if !first && !prevWasWall {
+		sc.scanRow(depth+1, start, end)
+	}
+}
+
+func (sc *shadowcaster) revealTile(x, y, depth, col int) {
+	if x < 0 || x >= sc.m.Width || y < 0 || y >= sc.m.Height {
+		return
+	}
+	// Circular radius, the "+ radius" rounds the circle out so it doesn't look pinched at the axes
+	if depth*depth+col*col > sc.radius*sc.radius+sc.radius {
+		return
+	}
+	sc.reveal(x, y, max(depth, math.Abs(col)))
+}
+
+// isSymmetric reports whether the centre of the tile lies between the start and end slopes.
+func isSymmetric(depth, col int, start, end slope) bool {
+	return col*start.den >= depth*start.num && col*end.den <= depth*end.num
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func ceilDiv(a, b int) int {
+	return -floorDiv(-a, b)
+}
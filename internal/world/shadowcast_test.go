@@ -0,0 +1,109 @@
+package world
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// The pillars must shade the tiles diagonally behind them, and the floor at the edges of
+// a shadow stays visible because its centre is still in view.
+func TestComputeFOV_ShadowcastGolden(t *testing.T) {
+	m := newTestMap(`
+###########
+#.........#
+#..#...#..#
+#.........#
+#....#....#
+#.........#
+###########`)
+	m.FOV = FOVShadowcast
+
+	m.ComputeFOV(5, 3, 8, func(x, y int) bool {
+		return !m.IsWalkable(x, y)
+	})
+
+	want := strings.TrimLeft(`
+# X X X X X X X X X # 
+# . . V V V V V . . # 
+X V . X V V V X . V X 
+X V V V V P V V V V X 
+X V V V V X V V V V X 
+X V V V V . V V V V X 
+X X X X # # # X X X X 
+`, "\n")
+
+	got := InspectVisibility(m, 5, 3)
+	if got != want {
+		t.Errorf("visibility mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestComputeFOV_ShadowcastSymmetric(t *testing.T) {
+	const width, height, radius = 24, 16, 8
+
+	for seed := uint64(1); seed <= 20; seed++ {
+		rng := rand.New(rand.NewPCG(seed, seed))
+		m := NewMap(width, height)
+		m.FOV = FOVShadowcast
+		for i := range m.Tiles {
+			if rng.IntN(4) == 0 {
+				m.Tiles[i] = Tile{Type: TileTypeWall}
+			} else {
+				m.Tiles[i] = Tile{Type: TileTypeFloor, Walkable: true}
+			}
+		}
+		blocksLight := func(x, y int) bool {
+			return !m.IsWalkable(x, y)
+		}
+
+		// sees[a*n+b] is true if floor tile a can see tile b
+		n := width * height
+		sees := make([]bool, n*n)
+		for a := 0; a < n; a++ {
+			if !m.Tiles[a].Walkable {
+				continue
+			}
+			m.ComputeFOV(a%width, a/width, radius, blocksLight)
+			for b := range m.Tiles {
+				sees[a*n+b] = m.Tiles[b].Visible
+			}
+		}
+
+		for a := 0; a < n; a++ {
+			for b := a + 1; b < n; b++ {
+				if !m.Tiles[a].Walkable || !m.Tiles[b].Walkable {
+					continue
+				}
+				if sees[a*n+b] != sees[b*n+a] {
+					t.Fatalf("seed %d: (%d,%d) sees (%d,%d) = %v, but the reverse is %v",
+						seed, a%width, a/width, b%width, b/width, sees[a*n+b], sees[b*n+a])
+				}
+			}
+		}
+	}
+}
+
+func TestComputeFOV_ClearsPreviousView(t *testing.T) {
+	m := newTestMap(`
+.........................
+.........................
+.........................`)
+	m.FOV = FOVShadowcast
+	blocksLight := func(x, y int) bool {
+		return !m.IsWalkable(x, y)
+	}
+
+	m.ComputeFOV(2, 1, 3, blocksLight)
+	m.ComputeFOV(22, 1, 3, blocksLight)
+
+	for x := 0; x < m.Width; x++ {
+		tile := m.GetTile(x, 1)
+		if x <= 5 && (tile.Visible || !tile.Explored) {
+			t.Errorf("(%d,1) should be explored but no longer visible", x)
+		}
+		if x >= 19 && !tile.Visible {
+			t.Errorf("(%d,1) should be visible", x)
+		}
+	}
+}
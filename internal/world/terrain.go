@@ -0,0 +1,16 @@
+package world
+
+// DebrisCost is the extra path cost of picking through the debris on a littered floor.
+func (m *Map) DebrisCost(x, y int) int {
+	tile := m.GetTile(x, y)
+	if tile == nil || tile.Type != TileTypeFloor || tile.Variant == 0 {
+		return 0
+	}
+	return 1
+}
+
+// HazardCost adds up everything that slows a walk over a tile: debris, water and fire.
+// It's meant as PathOptions.Cost.
+func (m *Map) HazardCost(x, y int) int {
+	return m.DebrisCost(x, y) + m.WaterCost(x, y) + m.FireCost(x, y)
+}
@@ -8,6 +8,19 @@ const (
 	TileTypeFloor
 )
 
+func (t TileType) Title() string {
+	switch t {
+	case TileTypeEmpty:
+		return "Void"
+	case TileTypeWall:
+		return "Wall"
+	case TileTypeFloor:
+		return "Floor"
+	default:
+		return "Unknown"
+	}
+}
+
 type Tile struct {
 	Type     TileType
 	Walkable bool
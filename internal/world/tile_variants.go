@@ -102,3 +102,67 @@ var TileVariantPaused = TileVariant{
 	TileTypeWall:  {"▓", core.DarkGray}, // Dark Gray Wall
 	TileTypeFloor: {"░", core.DarkGray}, // Dark Gray Floor
 }
+
+// TileOverlay is drawn over whatever theme the map uses to show a hazard on a tile, one appearance
+// per strength band from weakest to strongest.
+type TileOverlay []TileAppearance
+
+// TileOverlayFire 13. Fire (Embers flaring up into flames)
+var TileOverlayFire = TileOverlay{
+	{"'", core.Red},
+	{"^", core.Color{R: 255, G: 128, B: 0, A: 255}},
+	{"▲", core.Yellow},
+}
+
+// TileOverlayWater 14. Flooding (Puddles deepening into open water)
+var TileOverlayWater = TileOverlay{
+	{".", core.Cyan},
+	{"~", core.Cyan},
+	{"≈", core.Blue},
+}
+
+// TileOverlayNoise 15. Noise (Debug view of what can be heard, faint hum to deafening)
+var TileOverlayNoise = TileOverlay{
+	{"░", core.DarkGray},
+	{"▒", core.Gray},
+	{"▓", core.Yellow},
+	{"█", core.Red},
+}
+
+// At picks the appearance for a hazard of the given strength, out of maxStrength.
+func (o TileOverlay) At(strength, maxStrength int) TileAppearance {
+	band := (strength - 1) * len(o) / maxStrength
+	return o[max(0, min(band, len(o)-1))]
+}
+
+// NamedTileVariant pairs a theme with the name players and tools refer to it by.
+type NamedTileVariant struct {
+	Name    string
+	Variant TileVariant
+}
+
+// TileVariants lists every theme that can be picked by name.
+var TileVariants = []NamedTileVariant{
+	{"classic", TileVariantClassic},
+	{"solid", TileVariantSolid},
+	{"gritty", TileVariantGritty},
+	{"blueprint", TileVariantBlueprint},
+	{"toxic", TileVariantToxic},
+	{"alert", TileVariantAlert},
+	{"cold", TileVariantCold},
+	{"hive", TileVariantHive},
+	{"dark", TileVariantDark},
+	{"lightning", TileVariantLightning},
+	{"flooded", TileVariantFlooded},
+	{"ash", TileVariantAsh},
+}
+
+// TileVariantByName looks up a theme from TileVariants.
+func TileVariantByName(name string) (TileVariant, bool) {
+	for _, v := range TileVariants {
+		if v.Name == name {
+			return v.Variant, true
+		}
+	}
+	return TileVariant{}, false
+}
@@ -0,0 +1,120 @@
+package world
+
+/*
+	Water is a depth per tile, where 1.0 is waist deep. It flows the same way air diffuses: every
+	tick each open tile swaps a share of the difference in depth with its open neighbours, so it
+	runs from the deep end outwards until the floor is level, and the total is conserved. Closed
+	doors hold it back. Pumps are the only way to get rid of it.
+*/
+
+const (
+	flowRate = 0.2 // Share of the difference in depth exchanged with each neighbour per tick, stable up to 0.25
+
+	DryDepth          = 0.02 // Shallower than this the floor is just damp
+	ShortCircuitDepth = 0.3  // Devices standing in water this deep short out
+)
+
+// Water holds the depth of water on every tile.
+type Water struct {
+	Depth []float32
+
+	next []float32
+	open []bool // Tiles water can flow through this tick
+}
+
+// EnableWater gives the map a dry water layer.
+func (m *Map) EnableWater() {
+	n := m.Width * m.Height
+	m.Water = &Water{
+		Depth: make([]float32, n),
+		next:  make([]float32, n),
+		open:  make([]bool, n),
+	}
+}
+
+// Pour adds water to a tile.
+func (wt *Water) Pour(m *Map, x, y int, amount float32) {
+	if m.GetTile(x, y) == nil {
+		return
+	}
+	wt.Depth[m.GetIndex(x, y)] += amount
+}
+
+// Pump takes up to amount off every tile inside r (edges included) and returns how much it removed.
+func (wt *Water) Pump(m *Map, r Rect, amount float32) float32 {
+	var removed float32
+	for y := max(r.Y1, 0); y <= min(r.Y2, m.Height-1); y++ {
+		for x := max(r.X1, 0); x <= min(r.X2, m.Width-1); x++ {
+			i := m.GetIndex(x, y)
+			taken := min(amount, wt.Depth[i])
+			wt.Depth[i] -= taken
+			removed += taken
+		}
+	}
+	return removed
+}
+
+// Step lets the water flow for one tick. blocked marks tiles that hold water back on top of walls,
+// like closed doors; it may be nil. A blocked tile keeps whatever was standing in it.
+func (wt *Water) Step(m *Map, blocked []bool) {
+	w, h := m.Width, m.Height
+
+	for i, tile := range m.Tiles {
+		wt.open[i] = tile.Walkable && (blocked == nil || !blocked[i])
+	}
+
+	for y := 0; y < h; y++ {
+		row := y * w
+		for x := 0; x < w; x++ {
+			i := row + x
+			d := wt.Depth[i]
+			if !wt.open[i] {
+				wt.next[i] = d
+				continue
+			}
+
+			var dd float32
+			if x > 0 && wt.open[i-1] {
+				dd += wt.Depth[i-1] - d
+			}
+			if x < w-1 && wt.open[i+1] {
+				dd += wt.Depth[i+1] - d
+			}
+			if y > 0 && wt.open[i-w] {
+				dd += wt.Depth[i-w] - d
+			}
+			if y < h-1 && wt.open[i+w] {
+				dd += wt.Depth[i+w] - d
+			}
+			wt.next[i] = d + flowRate*dd
+		}
+	}
+
+	wt.Depth, wt.next = wt.next, wt.Depth
+}
+
+// Total is all the water on the map.
+func (wt *Water) Total() float32 {
+	var sum float32
+	for _, d := range wt.Depth {
+		sum += d
+	}
+	return sum
+}
+
+// WaterAt returns how deep the water on a tile is, 0 if it's off the map or water is disabled.
+func (m *Map) WaterAt(x, y int) float32 {
+	if m.Water == nil || x < 0 || x >= m.Width || y < 0 || y >= m.Height {
+		return 0
+	}
+	return m.Water.Depth[m.GetIndex(x, y)]
+}
+
+// WaterCost is the extra path cost of wading through a tile, deeper water is slower going.
+func (m *Map) WaterCost(x, y int) int {
+	d := m.WaterAt(x, y)
+	if d < DryDepth {
+		return 0
+	}
+	return int(min(d, 1)*8) + 1
+}
@@ -0,0 +1,105 @@
+package world
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWater_FlowsOutAndLevels(t *testing.T) {
+	m := newTestMap(`
+##########
+#........#
+#........#
+##########`)
+	m.EnableWater()
+	m.Water.Pour(m, 1, 1, 4)
+
+	for i := 0; i < 500; i++ {
+		m.Water.Step(m, nil)
+	}
+
+	if got := m.Water.Total(); math.Abs(float64(got)-4) > 1e-3 {
+		t.Errorf("Expected a sealed room to keep its water, got %v", got)
+	}
+	// 4 units spread over the 16 floor tiles
+	for _, x := range []int{1, 8} {
+		if got := m.WaterAt(x, 2); math.Abs(float64(got)-0.25) > 0.01 {
+			t.Errorf("Expected (%d,2) to settle at 0.25 deep, got %v", x, got)
+		}
+	}
+	if got := m.WaterAt(0, 1); got != 0 {
+		t.Errorf("Expected no water inside the walls, got %v", got)
+	}
+}
+
+func TestWater_ClosedDoorHoldsItBack(t *testing.T) {
+	m := newTestMap(fireRooms)
+	m.EnableWater()
+	m.Water.Pour(m, 1, 2, 3)
+
+	blocked := make([]bool, m.Width*m.Height)
+	blocked[m.GetIndex(5, 2)] = true
+	for i := 0; i < 200; i++ {
+		m.Water.Step(m, blocked)
+	}
+
+	if got := m.WaterAt(8, 2); got != 0 {
+		t.Errorf("Expected the room behind the door to stay dry, got %v", got)
+	}
+
+	// Opening the door lets it through
+	for i := 0; i < 200; i++ {
+		m.Water.Step(m, nil)
+	}
+	if got := m.WaterAt(8, 2); got < DryDepth {
+		t.Errorf("Expected water through the open door, got %v", got)
+	}
+}
+
+func TestWater_Pump(t *testing.T) {
+	m := newTestMap(fireRooms)
+	m.EnableWater()
+	m.Water.Pour(m, 2, 2, 0.5)
+	m.Water.Pour(m, 8, 2, 0.5)
+
+	removed := m.Water.Pump(m, Rect{X1: 0, Y1: 0, X2: 4, Y2: 4}, 0.2)
+	if math.Abs(float64(removed)-0.2) > 1e-6 {
+		t.Errorf("Expected the pump to take 0.2 out of the left room, took %v", removed)
+	}
+	if got := m.WaterAt(8, 2); got != 0.5 {
+		t.Errorf("Expected the right room untouched, got %v", got)
+	}
+
+	m.Water.Pump(m, Rect{X1: 0, Y1: 0, X2: 4, Y2: 4}, 1)
+	if got := m.WaterAt(2, 2); got != 0 {
+		t.Errorf("Expected the pump to stop at a dry floor, got %v", got)
+	}
+}
+
+func TestWater_PutsOutFire(t *testing.T) {
+	m := newTestMap(fireRooms)
+	m.EnableFire(1)
+	m.EnableWater()
+	m.Fire.Ignite(m, 2, 2)
+	m.Water.Pour(m, 2, 2, 0.5)
+
+	m.Fire.Step(m, nil)
+	if m.FireAt(2, 2) != 0 {
+		t.Error("Expected water to put the fire out")
+	}
+}
+
+func TestMap_WaterCost(t *testing.T) {
+	m := newTestMap(fireRooms)
+	m.EnableWater()
+	m.Water.Pour(m, 2, 2, 0.5)
+	m.Water.Pour(m, 3, 2, 1)
+	m.Water.Pour(m, 4, 2, DryDepth/2)
+
+	if a, b := m.WaterCost(2, 2), m.WaterCost(3, 2); a == 0 || b <= a {
+		t.Errorf("Expected deeper water to cost more, got %d and %d", a, b)
+	}
+	if got := m.WaterCost(4, 2); got != 0 {
+		t.Errorf("Expected a damp floor to cost nothing, got %d", got)
+	}
+}